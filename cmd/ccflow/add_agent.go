@@ -68,15 +68,11 @@ func addAgent(cmd *cobra.Command, args []string) {
 	// Determine content source
 	mut := mutator.New(bpManager)
 	opts := mutator.AddOptions{
-		Name:        agentName,
-		Force:       forceFlag,
-		BlueprintID: ws.Config.Blueprint,
-		HubPath:     ws.GetHubPath(),
-		TemplateData: &blueprint.TemplateData{
-			WorkflowName:  ws.Config.Name,
-			DocsStateDir:  ws.Config.State.StateDir,
-			DocsDesignDir: ws.Config.State.DesignsDir,
-		},
+		Name:         agentName,
+		Force:        forceFlag,
+		BlueprintID:  ws.Config.Blueprint,
+		HubPath:      ws.GetHubPath(),
+		TemplateData: blueprint.TemplateDataFor(ws.Config, agentName),
 	}
 
 	if addAgentStdinFlag {
@@ -97,6 +93,7 @@ func addAgent(cmd *cobra.Command, args []string) {
 	}
 
 	// Add the agent
+	snapshotHub(ws, "add-agent "+agentName)
 	if err := mut.AddAgent(opts); err != nil {
 		exitWithError("failed to add agent: %v", err)
 	}
@@ -68,16 +68,11 @@ func addCommand(cmd *cobra.Command, args []string) {
 	// Determine content source
 	mut := mutator.New(bpManager)
 	opts := mutator.AddOptions{
-		Name:        commandName,
-		Force:       forceFlag,
-		BlueprintID: ws.Config.Blueprint,
-		HubPath:     ws.GetHubPath(),
-		TemplateData: &blueprint.TemplateData{
-			WorkflowName:  ws.Config.Name,
-			DocsStateDir:  ws.Config.State.StateDir,
-			DocsDesignDir: ws.Config.State.DesignsDir,
-			GatesEnabled:  ws.Config.Gates.Enabled,
-		},
+		Name:         commandName,
+		Force:        forceFlag,
+		BlueprintID:  ws.Config.Blueprint,
+		HubPath:      ws.GetHubPath(),
+		TemplateData: blueprint.TemplateDataFor(ws.Config, ""),
 	}
 
 	if addCommandStdinFlag {
@@ -98,6 +93,7 @@ func addCommand(cmd *cobra.Command, args []string) {
 	}
 
 	// Add the command
+	snapshotHub(ws, "add-command "+commandName)
 	if err := mut.AddCommand(opts); err != nil {
 		exitWithError("failed to add command: %v", err)
 	}
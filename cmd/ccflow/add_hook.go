@@ -71,15 +71,11 @@ func addHook(cmd *cobra.Command, args []string) {
 	// Determine content source
 	mut := mutator.New(bpManager)
 	opts := mutator.AddOptions{
-		Name:        hookName,
-		Force:       forceFlag,
-		BlueprintID: ws.Config.Blueprint,
-		HubPath:     ws.GetHubPath(),
-		TemplateData: &blueprint.TemplateData{
-			WorkflowName:  ws.Config.Name,
-			DocsStateDir:  ws.Config.State.StateDir,
-			DocsDesignDir: ws.Config.State.DesignsDir,
-		},
+		Name:         hookName,
+		Force:        forceFlag,
+		BlueprintID:  ws.Config.Blueprint,
+		HubPath:      ws.GetHubPath(),
+		TemplateData: blueprint.TemplateDataFor(ws.Config, ""),
 	}
 
 	if addHookStdinFlag {
@@ -100,6 +96,7 @@ func addHook(cmd *cobra.Command, args []string) {
 	}
 
 	// Add the hook
+	snapshotHub(ws, "add-hook "+hookName)
 	if err := mut.AddHook(opts); err != nil {
 		exitWithError("failed to add hook: %v", err)
 	}
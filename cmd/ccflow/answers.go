@@ -0,0 +1,341 @@
+package ccflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/installer"
+	"github.com/Wameedh/ccflow/internal/util"
+)
+
+// answersFile is the on-disk format accepted by 'ccflow run --answers'.
+// Fields mirror the wizard prompts; unset optional fields fall back to the
+// same defaults the interactive wizard uses.
+type answersFile struct {
+	Blueprint     string                    `yaml:"blueprint"`
+	Name          string                    `yaml:"name"`
+	WorkspacePath string                    `yaml:"workspace_path"`
+	Topology      config.Topology           `yaml:"topology"`
+	Repos         []config.RepoConfig       `yaml:"repos"`
+	Hooks         *bool                     `yaml:"hooks"`
+	Gates         *bool                     `yaml:"gates"`
+	VCS           config.VCSProvider        `yaml:"vcs"`
+	Tracker       config.TrackerProvider    `yaml:"tracker"`
+	Transitions   *config.TransitionsConfig `yaml:"transitions"`
+	Parallel      *config.ParallelConfig    `yaml:"parallel"`
+	InstallMode   string                    `yaml:"install_mode"`
+}
+
+// nonInteractiveFlags lists the run flags that switch the wizard off
+var nonInteractiveFlags = []string{"answers", "name", "topology", "repo", "vcs", "tracker", "transition"}
+
+// isNonInteractiveRun reports whether any answer was supplied on the command line
+func isNonInteractiveRun(cmd *cobra.Command) bool {
+	for _, name := range nonInteractiveFlags {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadAnswersFile reads and parses an answers file
+func loadAnswersFile(path string) (*answersFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read answers file: %w", err)
+	}
+
+	var af answersFile
+	if err := yaml.Unmarshal(data, &af); err != nil {
+		return nil, fmt.Errorf("failed to parse answers file: %w", err)
+	}
+
+	return &af, nil
+}
+
+// buildNonInteractiveAnswers merges the answers file (if any) with command-line
+// flags and returns the blueprint ID plus a fully populated wizardAnswers.
+// Flags take precedence over the answers file.
+func buildNonInteractiveAnswers(cmd *cobra.Command, args []string) (string, wizardAnswers, error) {
+	af := &answersFile{}
+	if runAnswersFlag != "" {
+		loaded, err := loadAnswersFile(runAnswersFlag)
+		if err != nil {
+			return "", wizardAnswers{}, err
+		}
+		af = loaded
+	}
+
+	// Command-line overrides
+	if len(args) > 0 {
+		af.Blueprint = args[0]
+	}
+	if cmd.Flags().Changed("name") {
+		af.Name = runNameFlag
+	}
+	if cmd.Flags().Changed("topology") {
+		af.Topology = config.Topology(runTopologyFlag)
+	}
+	if cmd.Flags().Changed("vcs") {
+		af.VCS = config.VCSProvider(runVCSFlag)
+	}
+	if cmd.Flags().Changed("tracker") {
+		af.Tracker = config.TrackerProvider(runTrackerFlag)
+	}
+	if cmd.Flags().Changed("repo") {
+		repos, err := parseRepoFlags(runRepoFlags)
+		if err != nil {
+			return "", wizardAnswers{}, err
+		}
+		af.Repos = repos
+	}
+	if cmd.Flags().Changed("transition") {
+		if af.Transitions == nil {
+			af.Transitions = &config.TransitionsConfig{}
+		}
+		if err := applyTransitionFlags(af.Transitions, runTransitionFlags); err != nil {
+			return "", wizardAnswers{}, err
+		}
+	}
+
+	// Required answers
+	var missing []string
+	if af.Blueprint == "" {
+		missing = append(missing, "blueprint (argument or 'blueprint' key)")
+	}
+	if af.Name == "" {
+		missing = append(missing, "name (--name or 'name' key)")
+	}
+	if af.Topology == "" {
+		missing = append(missing, "topology (--topology or 'topology' key)")
+	}
+	if len(missing) > 0 {
+		return "", wizardAnswers{}, fmt.Errorf("missing required answers: %s", strings.Join(missing, ", "))
+	}
+
+	answers, err := af.toWizardAnswers()
+	if err != nil {
+		return "", wizardAnswers{}, err
+	}
+
+	return af.Blueprint, answers, nil
+}
+
+// toWizardAnswers validates the merged answers and applies wizard defaults
+func (af *answersFile) toWizardAnswers() (wizardAnswers, error) {
+	answers := wizardAnswers{
+		workflowName: af.Name,
+		topology:     af.Topology,
+		hooksEnabled: true,
+		gatesEnabled: true,
+		vcs:          config.VCSNone,
+		tracker:      config.TrackerNone,
+		transitions: config.TransitionsConfig{
+			IdeaToDesign:      config.TransitionConfig{Mode: config.TransitionPrompt},
+			DesignToImplement: config.TransitionConfig{Mode: config.TransitionPrompt},
+			ImplementToReview: config.TransitionConfig{Mode: config.TransitionPrompt},
+			ReviewToRelease:   config.TransitionConfig{Mode: config.TransitionPrompt},
+		},
+		parallel: config.ParallelConfig{
+			Enabled:  false,
+			SyncGate: "all",
+		},
+	}
+
+	// Workspace path: answers file, then --workspace, then current directory
+	workspacePath := af.WorkspacePath
+	if workspacePath == "" {
+		workspacePath = workspaceFlag
+	}
+	if workspacePath == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return answers, fmt.Errorf("failed to get current directory: %w", err)
+		}
+		workspacePath = cwd
+	}
+	absPath, err := filepath.Abs(workspacePath)
+	if err != nil {
+		return answers, fmt.Errorf("failed to resolve workspace path: %w", err)
+	}
+	answers.workspacePath = absPath
+
+	switch af.Topology {
+	case config.TopologySingleRepo, config.TopologyMultiRepo:
+	default:
+		return answers, fmt.Errorf("invalid topology: %s (must be '%s' or '%s')", af.Topology, config.TopologySingleRepo, config.TopologyMultiRepo)
+	}
+
+	if af.Topology == config.TopologyMultiRepo {
+		for _, repo := range af.Repos {
+			if repo.Name == "" {
+				return answers, fmt.Errorf("repository entry is missing a name")
+			}
+			if repo.Path == "" {
+				repo.Path = repo.Name
+			}
+			if repo.Kind == "" {
+				repo.Kind = util.DetectRepoKind(filepath.Join(absPath, repo.Path))
+			} else if err := checkRepoKind(repo.Kind); err != nil {
+				return answers, fmt.Errorf("repository %s: %w", repo.Name, err)
+			}
+			answers.repos = append(answers.repos, repo)
+		}
+	} else if len(af.Repos) > 0 {
+		return answers, fmt.Errorf("repositories can only be listed for the %s topology", config.TopologyMultiRepo)
+	}
+
+	if af.Hooks != nil {
+		answers.hooksEnabled = *af.Hooks
+	}
+	if af.Gates != nil {
+		answers.gatesEnabled = *af.Gates
+	}
+
+	if af.VCS != "" {
+		switch af.VCS {
+		case config.VCSGitHub, config.VCSGitLab, config.VCSNone:
+			answers.vcs = af.VCS
+		default:
+			return answers, fmt.Errorf("invalid vcs: %s (must be github, gitlab or none)", af.VCS)
+		}
+	}
+
+	if af.Tracker != "" {
+		switch af.Tracker {
+		case config.TrackerLinear, config.TrackerJira, config.TrackerNone:
+			answers.tracker = af.Tracker
+		default:
+			return answers, fmt.Errorf("invalid tracker: %s (must be linear, jira or none)", af.Tracker)
+		}
+	}
+
+	installMode, err := installer.ParseInstallMode(af.InstallMode)
+	if err != nil {
+		return answers, err
+	}
+	answers.installMode = installMode
+
+	if af.Transitions != nil {
+		fields := []struct {
+			name string
+			src  config.TransitionConfig
+			dst  *config.TransitionConfig
+		}{
+			{"idea_to_design", af.Transitions.IdeaToDesign, &answers.transitions.IdeaToDesign},
+			{"design_to_implement", af.Transitions.DesignToImplement, &answers.transitions.DesignToImplement},
+			{"implement_to_review", af.Transitions.ImplementToReview, &answers.transitions.ImplementToReview},
+			{"review_to_release", af.Transitions.ReviewToRelease, &answers.transitions.ReviewToRelease},
+		}
+		for _, f := range fields {
+			if f.src.Mode == "" {
+				continue
+			}
+			if !isValidTransitionMode(f.src.Mode) {
+				return answers, fmt.Errorf("invalid transition mode for %s: %s (must be auto, prompt or manual)", f.name, f.src.Mode)
+			}
+			f.dst.Mode = f.src.Mode
+		}
+	}
+
+	if af.Parallel != nil {
+		answers.parallel = *af.Parallel
+		if answers.parallel.SyncGate == "" {
+			answers.parallel.SyncGate = "all"
+		}
+		if answers.parallel.SyncGate != "all" && answers.parallel.SyncGate != "any" {
+			return answers, fmt.Errorf("invalid parallel sync_gate: %s (must be 'all' or 'any')", answers.parallel.SyncGate)
+		}
+	}
+
+	return answers, nil
+}
+
+// parseRepoFlags parses --repo values of the form name[:path[:kind]]
+func parseRepoFlags(values []string) ([]config.RepoConfig, error) {
+	var repos []config.RepoConfig
+	for _, v := range values {
+		parts := strings.Split(v, ":")
+		if len(parts) > 3 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid --repo value: %q (expected name[:path[:kind]])", v)
+		}
+
+		repo := config.RepoConfig{Name: strings.TrimSpace(parts[0])}
+		if len(parts) > 1 {
+			repo.Path = strings.TrimSpace(parts[1])
+		}
+		if len(parts) > 2 {
+			repo.Kind = config.RepoKind(strings.TrimSpace(parts[2]))
+			if err := checkRepoKind(repo.Kind); err != nil {
+				return nil, fmt.Errorf("invalid --repo value: %q (%w)", v, err)
+			}
+		}
+		repos = append(repos, repo)
+	}
+	return repos, nil
+}
+
+// checkRepoKind returns an error naming the valid kinds if kind isn't one
+func checkRepoKind(kind config.RepoKind) error {
+	if slices.Contains(config.RepoKinds, kind) {
+		return nil
+	}
+	kinds := make([]string, len(config.RepoKinds))
+	for i, k := range config.RepoKinds {
+		kinds[i] = string(k)
+	}
+	return fmt.Errorf("unknown repo kind %s, must be one of %s", kind, strings.Join(kinds, ", "))
+}
+
+// applyTransitionFlags applies --transition values of the form mode (all
+// transitions) or phase=mode (e.g. idea_to_design=auto)
+func applyTransitionFlags(t *config.TransitionsConfig, values []string) error {
+	byName := map[string]*config.TransitionConfig{
+		"idea_to_design":      &t.IdeaToDesign,
+		"design_to_implement": &t.DesignToImplement,
+		"implement_to_review": &t.ImplementToReview,
+		"review_to_release":   &t.ReviewToRelease,
+	}
+
+	for _, v := range values {
+		name, mode, hasName := strings.Cut(v, "=")
+		if !hasName {
+			mode = name
+		}
+		if !isValidTransitionMode(config.TransitionMode(mode)) {
+			return fmt.Errorf("invalid --transition value: %q (mode must be auto, prompt or manual)", v)
+		}
+
+		if !hasName {
+			for _, field := range byName {
+				field.Mode = config.TransitionMode(mode)
+			}
+			continue
+		}
+
+		field, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("invalid --transition value: %q (unknown transition %s)", v, name)
+		}
+		field.Mode = config.TransitionMode(mode)
+	}
+
+	return nil
+}
+
+// isValidTransitionMode checks a mode against the supported values
+func isValidTransitionMode(mode config.TransitionMode) bool {
+	switch mode {
+	case config.TransitionAuto, config.TransitionPrompt, config.TransitionManual:
+		return true
+	}
+	return false
+}
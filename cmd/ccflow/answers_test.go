@@ -0,0 +1,210 @@
+package ccflow
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Wameedh/ccflow/internal/config"
+)
+
+// newAnswersCmd returns a command with the run flags buildNonInteractiveAnswers
+// reads, set from args, and resets the flag variables afterwards
+func newAnswersCmd(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	reset := func() {
+		runAnswersFlag, runNameFlag, runTopologyFlag, runVCSFlag, runTrackerFlag = "", "", "", "", ""
+		runRepoFlags, runTransitionFlags = nil, nil
+		workspaceFlag = ""
+	}
+	reset()
+	t.Cleanup(reset)
+
+	cmd := &cobra.Command{Use: "run"}
+	cmd.Flags().StringVar(&runAnswersFlag, "answers", "", "")
+	cmd.Flags().StringVar(&runNameFlag, "name", "", "")
+	cmd.Flags().StringVar(&runTopologyFlag, "topology", "", "")
+	cmd.Flags().StringArrayVar(&runRepoFlags, "repo", nil, "")
+	cmd.Flags().StringVar(&runVCSFlag, "vcs", "", "")
+	cmd.Flags().StringVar(&runTrackerFlag, "tracker", "", "")
+	cmd.Flags().StringArrayVar(&runTransitionFlags, "transition", nil, "")
+	if err := cmd.Flags().Parse(args); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return cmd
+}
+
+func TestParseRepoFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    []config.RepoConfig
+		wantErr string
+	}{
+		{
+			name:   "name only",
+			values: []string{"web"},
+			want:   []config.RepoConfig{{Name: "web"}},
+		},
+		{
+			name:   "name, path and kind",
+			values: []string{"web:apps/web:node", " api : services/api "},
+			want: []config.RepoConfig{
+				{Name: "web", Path: "apps/web", Kind: config.RepoKindNode},
+				{Name: "api", Path: "services/api"},
+			},
+		},
+		{name: "empty name", values: []string{":web"}, wantErr: "expected name[:path[:kind]]"},
+		{name: "too many parts", values: []string{"web:web:node:x"}, wantErr: "expected name[:path[:kind]]"},
+		{name: "unknown kind", values: []string{"web:web:rust"}, wantErr: "unknown repo kind rust"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos, err := parseRepoFlags(tt.values)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRepoFlags failed: %v", err)
+			}
+			if !reflect.DeepEqual(repos, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, repos)
+			}
+		})
+	}
+}
+
+func TestApplyTransitionFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    config.TransitionsConfig
+		wantErr string
+	}{
+		{
+			name:   "mode for every transition, then one override",
+			values: []string{"auto", "review_to_release=manual"},
+			want: config.TransitionsConfig{
+				IdeaToDesign:      config.TransitionConfig{Mode: config.TransitionAuto},
+				DesignToImplement: config.TransitionConfig{Mode: config.TransitionAuto},
+				ImplementToReview: config.TransitionConfig{Mode: config.TransitionAuto},
+				ReviewToRelease:   config.TransitionConfig{Mode: config.TransitionManual},
+			},
+		},
+		{
+			name:   "single transition",
+			values: []string{"idea_to_design=prompt"},
+			want:   config.TransitionsConfig{IdeaToDesign: config.TransitionConfig{Mode: config.TransitionPrompt}},
+		},
+		{name: "invalid mode", values: []string{"sometimes"}, wantErr: "mode must be auto, prompt or manual"},
+		{name: "unknown transition", values: []string{"idea_to_release=auto"}, wantErr: "unknown transition idea_to_release"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got config.TransitionsConfig
+			err := applyTransitionFlags(&got, tt.values)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyTransitionFlags failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBuildNonInteractiveAnswers_Missing(t *testing.T) {
+	answersPath := filepath.Join(t.TempDir(), "answers.yaml")
+	if err := os.WriteFile(answersPath, []byte("name: shop\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		flags       []string
+		wantMissing []string
+		wantPresent []string
+	}{
+		{
+			name:        "nothing given",
+			wantMissing: []string{"blueprint", "name", "topology"},
+		},
+		{
+			name:        "blueprint argument and name flag",
+			args:        []string{"web-dev"},
+			flags:       []string{"--name", "shop"},
+			wantMissing: []string{"topology"},
+			wantPresent: []string{"blueprint", "name"},
+		},
+		{
+			name:        "name from the answers file",
+			flags:       []string{"--answers", answersPath, "--topology", "single-repo"},
+			wantMissing: []string{"blueprint"},
+			wantPresent: []string{"name", "topology"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newAnswersCmd(t, tt.flags...)
+			_, _, err := buildNonInteractiveAnswers(cmd, tt.args)
+			if err == nil || !strings.HasPrefix(err.Error(), "missing required answers: ") {
+				t.Fatalf("Expected missing required answers, got %v", err)
+			}
+			missing := strings.TrimPrefix(err.Error(), "missing required answers: ")
+			for _, answer := range tt.wantMissing {
+				if !strings.Contains(missing, answer+" (") {
+					t.Errorf("Expected %s to be reported missing, got %q", answer, missing)
+				}
+			}
+			for _, answer := range tt.wantPresent {
+				if strings.Contains(missing, answer+" (") {
+					t.Errorf("Expected %s not to be reported missing, got %q", answer, missing)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildNonInteractiveAnswers_RepoKind(t *testing.T) {
+	dir := t.TempDir()
+	answersPath := filepath.Join(dir, "answers.yaml")
+	answers := "blueprint: web-dev\nname: shop\ntopology: multi-repo\nrepos:\n  - name: web\n    kind: rust\n"
+	if err := os.WriteFile(answersPath, []byte(answers), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newAnswersCmd(t, "--answers", answersPath)
+	workspaceFlag = dir
+	_, _, err := buildNonInteractiveAnswers(cmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "repository web: unknown repo kind rust") {
+		t.Fatalf("Expected unknown kind error, got %v", err)
+	}
+
+	// A flag overrides the file's repos
+	cmd = newAnswersCmd(t, "--answers", answersPath, "--repo", "web:web:node")
+	workspaceFlag = dir
+	bpID, got, err := buildNonInteractiveAnswers(cmd, nil)
+	if err != nil {
+		t.Fatalf("buildNonInteractiveAnswers failed: %v", err)
+	}
+	if bpID != "web-dev" || len(got.repos) != 1 || got.repos[0].Kind != config.RepoKindNode {
+		t.Errorf("Unexpected answers: %s %+v", bpID, got.repos)
+	}
+}
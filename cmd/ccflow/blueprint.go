@@ -0,0 +1,111 @@
+package ccflow
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Wameedh/ccflow/internal/blueprint"
+)
+
+var blueprintCmd = &cobra.Command{
+	Use:   "blueprint",
+	Short: "Blueprint authoring tools",
+	Long: `Tools for authoring and checking workflow blueprints.
+
+Examples:
+  ccflow blueprint lint                    Lint every available blueprint
+  ccflow blueprint lint ./my-blueprint     Lint a blueprint directory`,
+}
+
+var blueprintLintCmd = &cobra.Command{
+	Use:   "lint [path]",
+	Short: "Check blueprints for integrity problems",
+	Long: `Check blueprints for problems that would break generation:
+
+  - agents, commands and hooks listed as defaults without an asset
+  - asset templates that fail to parse
+  - template fields that do not exist in the template data
+  - a missing or invalid settings.json
+  - hooks_manifest entries that disagree with the hook defaults
+
+With a path, lints the blueprint in that directory (resolving extends
+against the available blueprints), reporting a blueprint.yaml that doesn't
+parse or an unknown extends as errors. Without one, lints every available
+blueprint and lists the on-disk blueprints that failed to load. Exits
+non-zero if any errors are found.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runBlueprintLint,
+}
+
+func init() {
+	blueprintCmd.AddCommand(blueprintLintCmd)
+}
+
+func runBlueprintLint(cmd *cobra.Command, args []string) {
+	// Blueprints to report, each with its issues
+	var blueprints []*blueprint.Blueprint
+	var issuesOf [][]blueprint.LintIssue
+	var loadErrors []error
+
+	if len(args) == 1 {
+		// Linted on its own, so that a blueprint that fails to load can
+		// still be diagnosed
+		bp, issues, err := blueprint.LintDir(args[0])
+		if err != nil {
+			exitWithError("%v", err)
+		}
+		blueprints = append(blueprints, bp)
+		issuesOf = append(issuesOf, issues)
+	} else {
+		bpManager, err := blueprint.NewManager()
+		if err != nil {
+			exitWithError("failed to initialize blueprints: %v", err)
+		}
+		for _, bp := range bpManager.List() {
+			issues, err := bpManager.Lint(bp.ID)
+			if err != nil {
+				exitWithError("%v", err)
+			}
+			blueprints = append(blueprints, bp)
+			issuesOf = append(issuesOf, issues)
+		}
+		loadErrors = bpManager.LoadErrors()
+	}
+
+	errors, warnings := 0, 0
+	for i, bp := range blueprints {
+		issues := issuesOf[i]
+
+		fmt.Printf("%s (%s)\n", bp.ID, bp.Source)
+		if len(issues) == 0 {
+			fmt.Println("  ✓ no issues")
+		}
+		for _, issue := range issues {
+			icon := "⚠"
+			if issue.Severity == "error" {
+				icon = "✗"
+				errors++
+			} else {
+				warnings++
+			}
+			fmt.Printf("  %s %s\n", icon, issue)
+		}
+		fmt.Println()
+	}
+
+	if len(loadErrors) > 0 {
+		fmt.Println("Skipped blueprints")
+		for _, err := range loadErrors {
+			fmt.Printf("  ✗ %v\n", err)
+			errors++
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("Linted %d blueprint(s): %d error(s), %d warning(s)\n", len(blueprints), errors, warnings)
+	if errors > 0 {
+		os.Exit(1)
+	}
+}
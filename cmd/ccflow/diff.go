@@ -0,0 +1,192 @@
+package ccflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Wameedh/ccflow/internal/blueprint"
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/merge"
+	"github.com/Wameedh/ccflow/internal/settings"
+	"github.com/Wameedh/ccflow/internal/workspace"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [path]",
+	Short: "Show how managed files differ from their templates",
+	Long: `Show how the hub's managed files differ from their templates.
+
+Each file ccflow wrote from a blueprint template, and settings.json, is
+compared with the template rendered afresh from workflow.yaml. Lines marked
+- are the template's and lines marked + are the hub's, so the diff covers
+both your edits and template changes not yet upgraded. Files in agents/,
+commands/ and hooks/ that don't come from a template are listed after the
+diffs.
+
+Paths are relative to the hub's .claude directory.
+
+Examples:
+  ccflow diff                             # Every managed file
+  ccflow diff agents/product-agent.md     # A single file
+  ccflow reset agents/product-agent.md    # Discard the differences`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runDiff,
+}
+
+func runDiff(cmd *cobra.Command, args []string) {
+	ws, bpManager, bp, manifest := loadManagedFiles()
+	hubPath := ws.GetHubPath()
+
+	var paths []string
+	if len(args) == 1 {
+		paths = []string{hubRelPath(hubPath, args[0])}
+	} else {
+		for relPath := range manifest.Files {
+			paths = append(paths, relPath)
+		}
+		sort.Strings(paths)
+		paths = append(paths, settings.FileName)
+	}
+
+	differ := 0
+	var missing []string
+	for _, relPath := range paths {
+		template, err := renderManagedFile(ws, bpManager, bp, manifest, relPath)
+		if err != nil {
+			if len(args) == 1 {
+				exitWithError("no template for %s: %v", relPath, err)
+			}
+			printWarning("%s: %v", relPath, err)
+			continue
+		}
+
+		current, err := os.ReadFile(filepath.Join(hubPath, relPath))
+		if os.IsNotExist(err) {
+			missing = append(missing, relPath)
+			continue
+		} else if err != nil {
+			printWarning("%s: %v", relPath, err)
+			continue
+		}
+
+		if d := merge.Unified(template, current, "template/"+relPath, relPath, 3); d != nil {
+			os.Stdout.Write(d)
+			differ++
+		}
+	}
+
+	var extras []string
+	if len(args) == 0 {
+		extras = unmanagedFiles(hubPath, manifest)
+		if len(extras) > 0 {
+			if differ > 0 {
+				fmt.Println()
+			}
+			fmt.Println("Unmanaged files (not written from a template):")
+			for _, relPath := range extras {
+				fmt.Printf("  %s\n", relPath)
+			}
+		}
+	}
+
+	for _, relPath := range missing {
+		printWarning("%s is missing; run 'ccflow reset %s' to restore it", relPath, relPath)
+	}
+
+	if differ == 0 && len(missing) == 0 {
+		if len(args) == 1 {
+			printSuccess("%s matches its template", paths[0])
+		} else {
+			printSuccess("Managed files match their templates")
+		}
+	}
+}
+
+// loadManagedFiles discovers the workspace and loads its blueprint and
+// managed-files manifest
+func loadManagedFiles() (*workspace.Workspace, *blueprint.Manager, *blueprint.Blueprint, *config.ManagedFilesManifest) {
+	ws, err := workspace.Discover(workspaceFlag)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	bpManager, err := blueprint.NewManager()
+	if err != nil {
+		exitWithError("failed to initialize blueprints: %v", err)
+	}
+
+	bp, err := bpManager.Get(ws.Config.Blueprint)
+	if err != nil {
+		exitWithError("failed to load blueprint: %v", err)
+	}
+
+	return ws, bpManager, bp, workspace.LoadManifest(ws.GetHubPath())
+}
+
+// hubRelPath resolves a path given on the command line to one relative to
+// the hub's .claude directory. Paths inside the hub or a repo's .claude
+// symlink resolve to the file they point at; anything else is taken as
+// relative to .claude already.
+func hubRelPath(hubPath, arg string) string {
+	if abs, err := filepath.Abs(arg); err == nil {
+		if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+			abs = resolved
+		}
+		hub := hubPath
+		if resolved, err := filepath.EvalSymlinks(hubPath); err == nil {
+			hub = resolved
+		}
+		if rel, err := filepath.Rel(hub, abs); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return filepath.Clean(strings.TrimPrefix(filepath.ToSlash(arg), ".claude/"))
+}
+
+// templateBlueprint returns the ID of the blueprint a hub file is rendered
+// from: the one recorded in the manifest, else the workflow's
+func templateBlueprint(bp *blueprint.Blueprint, manifest *config.ManagedFilesManifest, relPath string) string {
+	if info, ok := manifest.Files[relPath]; ok {
+		if id, _, found := strings.Cut(info.TemplateID, "/"); found {
+			return id
+		}
+	}
+	return bp.ID
+}
+
+// renderManagedFile renders the template content of a file in the hub's
+// .claude directory as ccflow would write it today
+func renderManagedFile(ws *workspace.Workspace, bpManager *blueprint.Manager, bp *blueprint.Blueprint, manifest *config.ManagedFilesManifest, relPath string) ([]byte, error) {
+	if relPath == settings.FileName {
+		s, err := bpManager.RenderHubSettings(bp, ws.Config.Hooks.Enabled, manifest)
+		if err != nil {
+			return nil, err
+		}
+		return s.Marshal()
+	}
+	return bpManager.RenderFile(templateBlueprint(bp, manifest, relPath), relPath, ws.Config)
+}
+
+// unmanagedFiles lists the files in agents/, commands/ and hooks/ that the
+// manifest doesn't track
+func unmanagedFiles(hubPath string, manifest *config.ManagedFilesManifest) []string {
+	var extras []string
+	for _, dir := range []string{"agents", "commands", "hooks"} {
+		entries, err := os.ReadDir(filepath.Join(hubPath, dir))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			relPath := filepath.Join(dir, entry.Name())
+			if _, ok := manifest.Files[relPath]; !ok && !entry.IsDir() {
+				extras = append(extras, relPath)
+			}
+		}
+	}
+	return extras
+}
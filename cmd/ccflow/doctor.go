@@ -3,9 +3,11 @@ package ccflow
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/Wameedh/ccflow/internal/report"
 	"github.com/Wameedh/ccflow/internal/validator"
 	"github.com/Wameedh/ccflow/internal/workspace"
 )
@@ -21,12 +23,75 @@ Checks include:
 - Hook scripts exist and are executable
 - Symlinks point to correct targets (multi-repo)
 - Required directories exist
+- Feature state files (and experiment / infra request files) match the
+  blueprint's JSON schemas, reporting the file, JSON pointer and violation
 
-Provides remediation suggestions for any issues found.`,
+Provides remediation suggestions for any issues found.
+
+Use --output json or --output yaml for the checks in a stable format (see
+docs/OUTPUT.md). Either way the exit code is 1 if any check failed.
+
+Use --report format=path to also write the checks for CI, as JUnit XML
+(one test case per check) or SARIF 2.1.0 (one result per failed or warned
+check). Both include the remediation and, where known, the offending file
+relative to the workspace root. The flag can be repeated:
+
+  ccflow doctor --report junit=doctor.xml --report sarif=doctor.sarif
+
+Use --fix to repair what can be repaired automatically, then re-run the
+checks: make hook scripts executable, create missing directories,
+re-create repo symlinks (or copies) to the hub and regenerate a missing or
+invalid settings.json from the blueprint. Checks are re-run after fixing,
+and problems the fixes uncovered are fixed in turn. The files are
+snapshotted before each round of fixes, so 'ccflow undo' reverts them. Add
+--dry-run to list the fixes without applying them.`,
 	Run: runDoctor,
 }
 
+var (
+	doctorReports []string
+	doctorFix     bool
+	doctorDryRun  bool
+)
+
+func init() {
+	doctorCmd.Flags().StringArrayVar(&doctorReports, "report", nil, "Write a report as format=path (junit or sarif); repeatable")
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Apply automatic fixes, then re-run the checks")
+	doctorCmd.Flags().BoolVar(&doctorDryRun, "dry-run", false, "With --fix, show the fixes without applying them")
+}
+
+// doctorReport is a report requested with --report
+type doctorReport struct {
+	format string
+	path   string
+}
+
+// parseDoctorReports parses the --report flags
+func parseDoctorReports(values []string) ([]doctorReport, error) {
+	var reports []doctorReport
+	for _, value := range values {
+		format, path, ok := strings.Cut(value, "=")
+		if !ok || path == "" {
+			return nil, fmt.Errorf("invalid --report %q: expected format=path, e.g. junit=doctor.xml", value)
+		}
+		format = strings.ToLower(format)
+		if format != report.FormatJUnit && format != report.FormatSARIF {
+			return nil, fmt.Errorf("invalid --report %q: format must be %s", value, strings.Join(report.Formats, " or "))
+		}
+		reports = append(reports, doctorReport{format: format, path: path})
+	}
+	return reports, nil
+}
+
 func runDoctor(cmd *cobra.Command, args []string) {
+	reports, err := parseDoctorReports(doctorReports)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+	if doctorDryRun && !doctorFix {
+		exitWithError("--dry-run requires --fix")
+	}
+
 	// Discover workspace
 	ws, err := workspace.Discover(workspaceFlag)
 	if err != nil {
@@ -36,6 +101,23 @@ func runDoctor(cmd *cobra.Command, args []string) {
 	// Run doctor checks
 	v := validator.New()
 	result := v.Doctor(ws)
+	if doctorFix {
+		result = fixDoctorChecks(v, ws, result)
+	}
+
+	for _, r := range reports {
+		if err := report.WriteFile(r.path, r.format, result, ws.Root); err != nil {
+			exitWithError("Failed to write %s report: %v", r.format, err)
+		}
+	}
+
+	if structuredOutput() {
+		printStructured(result)
+		if result.Failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
 
 	// Print results
 	fmt.Println("ccflow Doctor")
@@ -55,6 +137,9 @@ func runDoctor(cmd *cobra.Command, args []string) {
 
 		fmt.Printf("%s %s\n", icon, check.Name)
 		fmt.Printf("  %s\n", check.Message)
+		for _, detail := range check.Details {
+			fmt.Printf("    - %s\n", detail)
+		}
 		if check.Remediation != "" {
 			fmt.Printf("  → %s\n", check.Remediation)
 		}
@@ -69,6 +154,13 @@ func runDoctor(cmd *cobra.Command, args []string) {
 	fmt.Printf("  Failed:   %d\n", result.Failed)
 	fmt.Println()
 
+	for _, r := range reports {
+		printInfo("Wrote %s report to %s", r.format, r.path)
+	}
+	if len(reports) > 0 {
+		fmt.Println()
+	}
+
 	// Exit code
 	if result.Failed > 0 {
 		fmt.Println("Some checks failed. Please address the issues above.")
@@ -81,3 +173,65 @@ func runDoctor(cmd *cobra.Command, args []string) {
 		os.Exit(0)
 	}
 }
+
+// fixDoctorRounds bounds how often --fix re-runs the checks to pick up
+// problems that earlier fixes uncovered, e.g. hooks in a regenerated
+// settings.json
+const fixDoctorRounds = 3
+
+// fixDoctorChecks applies the fixes of the checks that didn't pass and
+// returns the checks re-run afterwards. With --dry-run it only lists them.
+// Progress goes to stderr when stdout is JSON or YAML.
+func fixDoctorChecks(v *validator.Validator, ws *workspace.Workspace, result *validator.DoctorResult) *validator.DoctorResult {
+	out := os.Stdout
+	if structuredOutput() {
+		out = os.Stderr
+	}
+
+	fixable := result.Fixable()
+	if len(fixable) == 0 {
+		fmt.Fprintln(out, "→ Nothing to fix automatically")
+		fmt.Fprintln(out)
+		return result
+	}
+
+	if doctorDryRun {
+		fmt.Fprintln(out, "Would fix:")
+		for _, check := range fixable {
+			fmt.Fprintf(out, "  - %s: %s\n", check.Name, check.Fix.Description)
+		}
+		fmt.Fprintln(out)
+		return result
+	}
+
+	attempted := make(map[string]bool)
+	for round := 0; round < fixDoctorRounds; round++ {
+		var pending []validator.Check
+		var paths []string
+		for _, check := range fixable {
+			if !attempted[check.Fix.Description] {
+				pending = append(pending, check)
+				paths = append(paths, check.Fix.Paths...)
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+
+		takeSnapshot(ws.Root, "doctor --fix", paths...)
+		for _, check := range pending {
+			attempted[check.Fix.Description] = true
+			if err := check.Fix.Apply(); err != nil {
+				fmt.Fprintf(out, "✗ %s: %s failed: %v\n", check.Name, check.Fix.Description, err)
+			} else {
+				fmt.Fprintf(out, "✓ %s: %s\n", check.Name, check.Fix.Description)
+			}
+		}
+
+		result = v.Doctor(ws)
+		fixable = result.Fixable()
+	}
+	fmt.Fprintln(out)
+
+	return result
+}
@@ -2,14 +2,16 @@ package ccflow
 
 import (
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/spf13/cobra"
 
 	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/snapshot"
+	"github.com/Wameedh/ccflow/internal/stage"
 	"github.com/Wameedh/ccflow/internal/util"
 	"github.com/Wameedh/ccflow/internal/workspace"
 )
@@ -65,11 +67,16 @@ func runExpand(cmd *cobra.Command, args []string) {
 	}
 
 	// Perform expansion
+	takeSnapshot(ws.Root, "expand",
+		filepath.Join(ws.Root, ".claude"),
+		filepath.Join(ws.Root, "workflow-hub"),
+		filepath.Join(ws.Root, ".ccflow"))
 	if err := expandWorkflow(ws); err != nil {
 		exitWithError("expansion failed: %v", err)
 	}
 
 	printSuccess("Workflow expanded to multi-repo topology")
+	printInfo("Run 'ccflow undo' to go back to single-repo")
 	fmt.Println()
 	fmt.Println("Next steps:")
 	fmt.Println("  1. Move other repositories into the workspace")
@@ -80,22 +87,46 @@ func runExpand(cmd *cobra.Command, args []string) {
 
 func expandWorkflow(ws *workspace.Workspace) error {
 	hubName := "workflow-hub"
-	hubPath := filepath.Join(ws.Root, hubName)
 	oldClaudePath := filepath.Join(ws.Root, ".claude")
-	newClaudePath := filepath.Join(hubPath, ".claude")
+	oldCcflowPath := filepath.Join(ws.Root, ".ccflow")
+	oldBasePath := filepath.Join(oldCcflowPath, workspace.BaseDir)
+
+	// The multi-repo layout is built in a stage and moved into place at the
+	// end, so a failed expansion leaves the single-repo workflow as it was
+	st, err := stage.New(ws.Root)
+	if err != nil {
+		return err
+	}
+	defer st.Abort()
+	if err := st.Seed(oldClaudePath, oldBasePath); err != nil {
+		return err
+	}
 
 	// Create workflow-hub directory
+	hubPath := filepath.Join(st.Dir, hubName)
+	newClaudePath := filepath.Join(hubPath, ".claude")
 	if err := util.EnsureDir(hubPath); err != nil {
 		return fmt.Errorf("failed to create hub directory: %w", err)
 	}
 
 	// Move .claude to hub
-	if err := moveDirectory(oldClaudePath, newClaudePath); err != nil {
+	if err := os.Rename(st.Path(oldClaudePath), newClaudePath); err != nil {
 		return fmt.Errorf("failed to move .claude: %w", err)
 	}
 
+	// Keep the bases upgrade merges against beside it
+	if util.DirExists(st.Path(oldBasePath)) {
+		newBasePath := filepath.Join(hubPath, ".ccflow", workspace.BaseDir)
+		if err := util.EnsureDir(filepath.Dir(newBasePath)); err != nil {
+			return fmt.Errorf("failed to create hub .ccflow directory: %w", err)
+		}
+		if err := os.Rename(st.Path(oldBasePath), newBasePath); err != nil {
+			return fmt.Errorf("failed to move template bases: %w", err)
+		}
+	}
+
 	// Create symlink
-	if err := util.CreateRelativeSymlink(newClaudePath, oldClaudePath); err != nil {
+	if err := util.CreateRelativeSymlink(newClaudePath, st.Path(oldClaudePath)); err != nil {
 		return fmt.Errorf("failed to create symlink: %w", err)
 	}
 
@@ -122,50 +153,17 @@ func expandWorkflow(ws *workspace.Workspace) error {
 		return fmt.Errorf("failed to write new config: %w", err)
 	}
 
-	// Remove old .ccflow directory
-	oldCcflowPath := filepath.Join(ws.Root, ".ccflow")
-	if util.DirExists(oldCcflowPath) {
-		if err := util.RemoveAll(oldCcflowPath); err != nil {
+	// Remove old .ccflow directory, keeping the snapshots and stages in it
+	entries, _ := os.ReadDir(oldCcflowPath)
+	for _, entry := range entries {
+		path := filepath.Join(oldCcflowPath, entry.Name())
+		if path == filepath.Join(ws.Root, snapshot.StoreDir) || strings.HasPrefix(entry.Name(), stage.DirPrefix) {
+			continue
+		}
+		if err := st.Remove(path); err != nil {
 			return fmt.Errorf("failed to remove old .ccflow directory: %w", err)
 		}
 	}
 
-	return nil
-}
-
-func moveDirectory(src, dst string) error {
-	// Ensure destination parent exists
-	if err := util.EnsureDir(filepath.Dir(dst)); err != nil {
-		return err
-	}
-
-	// Try os.Rename first (fast, atomic on same filesystem)
-	if err := os.Rename(src, dst); err == nil {
-		return nil
-	}
-
-	// Fall back to copy + delete
-	if err := copyDir(src, dst); err != nil {
-		return err
-	}
-
-	return util.RemoveAll(src)
-}
-
-func copyDir(src, dst string) error {
-	// Walk source directory and copy files
-	return filepath.Walk(src, func(path string, info fs.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		relPath, _ := filepath.Rel(src, path)
-		dstPath := filepath.Join(dst, relPath)
-
-		if info.IsDir() {
-			return util.EnsureDir(dstPath)
-		}
-
-		return util.CopyFile(path, dstPath)
-	})
+	return st.Commit()
 }
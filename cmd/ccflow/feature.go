@@ -0,0 +1,250 @@
+package ccflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Wameedh/ccflow/internal/blueprint"
+	"github.com/Wameedh/ccflow/internal/feature"
+	"github.com/Wameedh/ccflow/internal/gate"
+	"github.com/Wameedh/ccflow/internal/transition"
+	"github.com/Wameedh/ccflow/internal/workspace"
+)
+
+var (
+	featureTitleFlag       string
+	featureDescriptionFlag string
+	featureToFlag          string
+)
+
+var featureCmd = &cobra.Command{
+	Use:   "feature",
+	Short: "Manage feature state files",
+	Long: `Create and move features through the workflow lifecycle.
+
+Each feature is a JSON state file in the workflow's state directory
+(docs/workflow/state by default), validated against the blueprint's
+state.schema.json. The statuses and their order come from the schema's
+status enum.
+
+Examples:
+  ccflow feature new login-page --title "Login page"
+  ccflow feature list
+  ccflow feature show login-page
+  ccflow feature advance login-page
+  ccflow feature advance login-page --to rollback`,
+}
+
+var featureNewCmd = &cobra.Command{
+	Use:   "new <id>",
+	Short: "Create a feature in the first lifecycle status",
+	Args:  cobra.ExactArgs(1),
+	Run:   runFeatureNew,
+}
+
+var featureListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List features and their status",
+	Args:  cobra.NoArgs,
+	Run:   runFeatureList,
+}
+
+var featureShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a feature and check it against the schema",
+	Args:  cobra.ExactArgs(1),
+	Run:   runFeatureShow,
+}
+
+var featureAdvanceCmd = &cobra.Command{
+	Use:   "advance <id>",
+	Short: "Move a feature to its next status",
+	Long: `Move a feature to the next status in the lifecycle and update its
+updated_at timestamp. Use --to to set a specific status, e.g. to send a
+feature back to design or to roll back a release. Rollback is never chosen
+automatically.
+
+When gates are enabled, a move into a later phase must pass the gate of
+every phase it leaves, even with --to (see 'ccflow gate check'). --force
+skips the gates.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runFeatureAdvance,
+}
+
+func init() {
+	featureNewCmd.Flags().StringVar(&featureTitleFlag, "title", "", "human-readable title (defaults to the id)")
+	featureNewCmd.Flags().StringVar(&featureDescriptionFlag, "description", "", "description of the feature")
+	featureAdvanceCmd.Flags().StringVar(&featureToFlag, "to", "", "status to move to (defaults to the next status)")
+
+	featureCmd.AddCommand(featureNewCmd)
+	featureCmd.AddCommand(featureListCmd)
+	featureCmd.AddCommand(featureShowCmd)
+	featureCmd.AddCommand(featureAdvanceCmd)
+}
+
+// initFeatureStore discovers the workspace and opens its feature store
+func initFeatureStore() (*workspace.Workspace, *feature.Store) {
+	ws, err := workspace.Discover(workspaceFlag)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	bpManager, err := blueprint.NewManager()
+	if err != nil {
+		exitWithError("failed to initialize blueprints: %v", err)
+	}
+
+	schemaData, err := bpManager.GetTemplate(ws.Config.Blueprint, feature.SchemaTemplate)
+	if err != nil {
+		exitWithError("blueprint %s has no %s template", ws.Config.Blueprint, feature.SchemaTemplate)
+	}
+
+	store, err := feature.NewStore(ws.GetStatePath(), schemaData)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+	return ws, store
+}
+
+func runFeatureNew(cmd *cobra.Command, args []string) {
+	_, store := initFeatureStore()
+
+	title := featureTitleFlag
+	if title == "" {
+		title = args[0]
+	}
+
+	f, err := store.New(args[0], title, featureDescriptionFlag)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	printSuccess("Created feature %s (%s)", f.ID(), f.Status())
+	printInfo("State file: %s", f.Path)
+}
+
+func runFeatureList(cmd *cobra.Command, args []string) {
+	ws, store := initFeatureStore()
+
+	features, failed, err := store.List()
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	if len(features) == 0 && len(failed) == 0 {
+		fmt.Printf("No features in %s\n", ws.Config.State.StateDir)
+		fmt.Println("Create one with: ccflow feature new <id>")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tUPDATED\tTITLE")
+	invalid := 0
+	for _, f := range features {
+		status := f.Status()
+		if len(store.Validate(f)) > 0 {
+			status += " ✗"
+			invalid++
+		}
+		updated := f.UpdatedAt()
+		if updated == "" {
+			updated = f.CreatedAt()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", f.ID(), status, updated, f.Title())
+	}
+	for _, e := range failed {
+		fmt.Fprintf(w, "%s\tunreadable ✗\t\t\n", strings.TrimSuffix(filepath.Base(e.Path), ".json"))
+	}
+	w.Flush()
+
+	if invalid > 0 || len(failed) > 0 {
+		fmt.Println()
+	}
+	if invalid > 0 {
+		printWarning("%d feature(s) do not match the schema; run 'ccflow feature show <id>' for details", invalid)
+	}
+	for _, e := range failed {
+		printWarning("%v", e)
+	}
+}
+
+func runFeatureShow(cmd *cobra.Command, args []string) {
+	_, store := initFeatureStore()
+
+	f, err := store.Get(args[0])
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	fmt.Printf("Feature: %s\n", f.ID())
+	fmt.Println(strings.Repeat("─", 50))
+	fmt.Printf("  Title:   %s\n", f.Title())
+	fmt.Printf("  Status:  %s\n", f.Status())
+	fmt.Printf("  Created: %s\n", f.CreatedAt())
+	if f.UpdatedAt() != "" {
+		fmt.Printf("  Updated: %s\n", f.UpdatedAt())
+	}
+	if next, nextErr := feature.NextStatus(store.Statuses(), f.Status()); nextErr == nil {
+		fmt.Printf("  Next:    %s\n", next)
+	}
+
+	// Remaining fields, in key order
+	shown := map[string]bool{"id": true, "title": true, "status": true, "created_at": true, "updated_at": true}
+	var keys []string
+	for key := range f.Data {
+		if !shown[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) > 0 {
+		fmt.Println()
+		for _, key := range keys {
+			value, _ := json.Marshal(f.Data[key])
+			if s, ok := f.Data[key].(string); ok {
+				value = []byte(s)
+			}
+			fmt.Printf("  %s: %s\n", key, value)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Lifecycle: %s\n", strings.Join(store.Statuses(), " → "))
+
+	if violations := store.Validate(f); len(violations) > 0 {
+		fmt.Println()
+		fmt.Printf("Schema violations in %s:\n", f.Path)
+		for _, v := range violations {
+			fmt.Printf("  ✗ %s\n", v)
+		}
+		os.Exit(1)
+	}
+}
+
+func runFeatureAdvance(cmd *cobra.Command, args []string) {
+	ws, store := initFeatureStore()
+
+	var enforce transition.GateFunc
+	if forceFlag {
+		if ws.Config.Gates.Enabled {
+			printWarning("Skipping gates (--force)")
+		}
+	} else {
+		enforce = gate.NewRunner(ws).Enforce
+	}
+
+	engine := transition.NewEngine(store, ws.Config.Transitions, nil, enforce)
+	result, err := engine.Advance(args[0], featureToFlag)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	printSuccess("%s: %s → %s", result.Feature.ID(), result.FromStatus, result.Feature.Status())
+}
@@ -0,0 +1,87 @@
+package ccflow
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Wameedh/ccflow/internal/gate"
+	"github.com/Wameedh/ccflow/internal/transition"
+)
+
+var gateCmd = &cobra.Command{
+	Use:   "gate",
+	Short: "Run quality gates between workflow phases",
+	Long: `Quality gates are checks a feature must pass before 'ccflow next' moves it
+out of a phase. They are configured per transition under gates in
+workflow.yaml and can require state fields, files and passing commands.`,
+}
+
+var gateCheckCmd = &cobra.Command{
+	Use:   "check <phase> <feature>",
+	Short: "Run the gate out of a phase for a feature",
+	Long: `Run the gate for the transition out of <phase> against a feature and
+report each check. Phases are idea, design, implement and review.
+
+Checks run even when gates are disabled in workflow.yaml, so a gate can be
+tried before it is enforced.
+
+Examples:
+  ccflow gate check design login-page
+  ccflow gate check implement login-page`,
+	Args: cobra.ExactArgs(2),
+	Run:  runGateCheck,
+}
+
+func init() {
+	gateCmd.AddCommand(gateCheckCmd)
+}
+
+func runGateCheck(cmd *cobra.Command, args []string) {
+	ws, store := initFeatureStore()
+
+	t, err := transition.For(ws.Config.Transitions, transition.Phase(args[0]))
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	f, err := store.Get(args[1])
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	result := gate.NewRunner(ws).Check(t, f)
+
+	fmt.Printf("Gate %s for %s\n", t.Key, f.ID())
+	fmt.Println(strings.Repeat("─", 50))
+
+	if len(result.Checks) == 0 {
+		fmt.Println("  No checks configured")
+	}
+	for _, check := range result.Checks {
+		icon := "✓"
+		if !check.Passed {
+			icon = "✗"
+		}
+		fmt.Printf("%s %s: %s\n", icon, check.Kind, check.Name)
+		fmt.Printf("  %s\n", check.Message)
+		if !check.Passed && strings.TrimSpace(check.Output) != "" {
+			for _, line := range strings.Split(strings.TrimRight(check.Output, "\n"), "\n") {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+	}
+	fmt.Println()
+
+	if !ws.Config.Gates.Enabled {
+		printWarning("Gates are disabled in workflow.yaml; 'ccflow next' will not enforce this gate")
+	}
+
+	if !result.Passed() {
+		fmt.Printf("%d of %d check(s) failed\n", len(result.Failed()), len(result.Checks))
+		os.Exit(1)
+	}
+	printSuccess("Gate passed")
+}
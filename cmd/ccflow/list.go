@@ -2,10 +2,13 @@ package ccflow
 
 import (
 	"fmt"
+	"os"
+	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/Wameedh/ccflow/internal/blueprint"
 	"github.com/Wameedh/ccflow/internal/workspace"
 )
 
@@ -15,7 +18,11 @@ var listCmd = &cobra.Command{
 	Long: `List all workflows registered in the global registry.
 
 The registry is stored at ~/.ccflow/registry.json and is updated
-automatically when workflows are created.
+automatically when workflows are created and used.
+
+Each workflow's health says whether it is still usable: ok, path does not
+exist, no valid workflow.yaml, or unknown blueprint. Use 'ccflow registry'
+to relocate moved workflows and prune ones that are gone.
 
 Note: This is a convenience feature. All commands work via marker
 discovery even without the registry.`,
@@ -28,6 +35,19 @@ func listWorkflows(cmd *cobra.Command, args []string) {
 		exitWithError("failed to load registry: %v", err)
 	}
 
+	// Blueprints are only needed to tell whether each one still exists
+	bpManager, _ := blueprint.NewManager()
+
+	if structuredOutput() {
+		listing := workflowListing{Workflows: []workflowListingEntry{}}
+		for _, entry := range reg.Workflows {
+			_, health, found := entryHealth(entry, bpManager)
+			listing.Workflows = append(listing.Workflows, workflowListingEntry{RegistryEntry: entry, Health: health, Found: found})
+		}
+		printStructured(listing)
+		return
+	}
+
 	if len(reg.Workflows) == 0 {
 		fmt.Println("No workflows registered.")
 		fmt.Println()
@@ -35,18 +55,53 @@ func listWorkflows(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	fmt.Println("Registered Workflows")
-	fmt.Println("====================")
-	fmt.Println()
-
+	missing := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tBLUEPRINT\tHEALTH\tLAST USED\tPATH")
 	for _, entry := range reg.Workflows {
-		fmt.Printf("  %s\n", entry.Name)
-		fmt.Printf("    Path:      %s\n", entry.Path)
-		fmt.Printf("    Blueprint: %s\n", entry.Blueprint)
-		fmt.Printf("    Created:   %s\n", formatTime(entry.CreatedAt))
-		fmt.Printf("    Last used: %s\n", formatTime(entry.LastUsedAt))
+		bp, health, found := entryHealth(entry, bpManager)
+		if !found {
+			missing++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", entry.Name, bp, health, formatTime(entry.LastUsedAt), entry.Path)
+	}
+	w.Flush()
+
+	if missing > 0 {
 		fmt.Println()
+		printWarning("%d workflow(s) not found where registered", missing)
+		fmt.Println("Run 'ccflow registry relocate <name> <path>' for moved workflows")
+		fmt.Println("or 'ccflow registry prune' to drop the ones that are gone.")
+	}
+}
+
+// workflowListing is the schema of ccflow list --output json|yaml
+// (docs/OUTPUT.md)
+type workflowListing struct {
+	Workflows []workflowListingEntry `json:"workflows" yaml:"workflows"`
+}
+
+// workflowListingEntry is a registry entry with its health
+type workflowListingEntry struct {
+	workspace.RegistryEntry `yaml:",inline"`
+	Health                  string `json:"health" yaml:"health"` // "ok" or what is wrong
+	Found                   bool   `json:"found" yaml:"found"`   // the workflow is at its registered path
+}
+
+// entryHealth returns the blueprint of a registered workflow, a short
+// description of its health (ok, or what is wrong) and whether the workflow
+// was found at its path
+func entryHealth(entry workspace.RegistryEntry, bpManager *blueprint.Manager) (string, string, bool) {
+	h := entry.Health()
+	if !h.OK() {
+		return entry.Blueprint, h.Problem, false
+	}
+	if bpManager != nil {
+		if _, err := bpManager.Get(h.Blueprint); err != nil {
+			return h.Blueprint, "unknown blueprint", true
+		}
 	}
+	return h.Blueprint, "ok", true
 }
 
 func formatTime(t time.Time) string {
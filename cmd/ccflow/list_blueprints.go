@@ -11,8 +11,16 @@ import (
 var listBlueprintsCmd = &cobra.Command{
 	Use:   "list-blueprints",
 	Short: "List available blueprints",
-	Long:  `List all available workflow blueprints with their descriptions.`,
-	Run:   listBlueprints,
+	Long: `List all available workflow blueprints with their descriptions.
+
+Blueprints come from the ccflow binary (embedded), ~/.ccflow/blueprints/,
+and each directory in CCFLOW_BLUEPRINT_PATH. On-disk blueprints with the
+same ID replace embedded ones. On-disk blueprints that fail to load are
+skipped and listed as warnings.
+
+Use --output json or --output yaml for the list in a stable format (see
+docs/OUTPUT.md).`,
+	Run: listBlueprints,
 }
 
 func listBlueprints(cmd *cobra.Command, args []string) {
@@ -23,6 +31,28 @@ func listBlueprints(cmd *cobra.Command, args []string) {
 
 	blueprints := bpManager.List()
 
+	if structuredOutput() {
+		listing := blueprintListing{Blueprints: []blueprintListingEntry{}, Errors: []string{}}
+		for _, bp := range blueprints {
+			listing.Blueprints = append(listing.Blueprints, blueprintListingEntry{
+				ID:              bp.ID,
+				DisplayName:     bp.DisplayName,
+				Description:     bp.Description,
+				DefaultTopology: bp.DefaultTopology,
+				Extends:         bp.Extends,
+				Source:          bp.Source,
+				Agents:          nonNil(bp.Agents.Defaults),
+				Commands:        nonNil(bp.Commands.Defaults),
+				Hooks:           nonNil(bp.Hooks.Defaults),
+			})
+		}
+		for _, err := range bpManager.LoadErrors() {
+			listing.Errors = append(listing.Errors, err.Error())
+		}
+		printStructured(listing)
+		return
+	}
+
 	fmt.Println("Available blueprints:")
 	fmt.Println()
 
@@ -31,8 +61,48 @@ func listBlueprints(cmd *cobra.Command, args []string) {
 		fmt.Printf("    %s\n", bp.DisplayName)
 		fmt.Printf("    %s\n", bp.Description)
 		fmt.Printf("    Default topology: %s\n", bp.DefaultTopology)
+		if bp.Extends != "" {
+			fmt.Printf("    Extends: %s\n", bp.Extends)
+		}
+		fmt.Printf("    Source: %s\n", bp.Source)
+		fmt.Println()
+	}
+
+	for _, err := range bpManager.LoadErrors() {
+		printWarning("Skipped: %v", err)
+	}
+	if len(bpManager.LoadErrors()) > 0 {
 		fmt.Println()
 	}
 
 	fmt.Println("Usage: ccflow run <blueprint>")
 }
+
+// blueprintListing is the schema of ccflow list-blueprints --output
+// json|yaml (docs/OUTPUT.md)
+type blueprintListing struct {
+	Blueprints []blueprintListingEntry `json:"blueprints" yaml:"blueprints"`
+	Errors     []string                `json:"errors" yaml:"errors"`
+}
+
+// blueprintListingEntry describes one blueprint and its default assets
+type blueprintListingEntry struct {
+	ID              string   `json:"id" yaml:"id"`
+	DisplayName     string   `json:"display_name" yaml:"display_name"`
+	Description     string   `json:"description" yaml:"description"`
+	DefaultTopology string   `json:"default_topology" yaml:"default_topology"`
+	Extends         string   `json:"extends" yaml:"extends"`
+	Source          string   `json:"source" yaml:"source"`
+	Agents          []string `json:"agents" yaml:"agents"`
+	Commands        []string `json:"commands" yaml:"commands"`
+	Hooks           []string `json:"hooks" yaml:"hooks"`
+}
+
+// nonNil returns names, or an empty list for nil, so that lists in
+// structured output are never null
+func nonNil(names []string) []string {
+	if names == nil {
+		return []string{}
+	}
+	return names
+}
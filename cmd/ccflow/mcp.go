@@ -0,0 +1,193 @@
+package ccflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Wameedh/ccflow/internal/mcp"
+	"github.com/Wameedh/ccflow/internal/workspace"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Manage MCP servers for the workflow",
+	Long: `Manage the MCP servers configured for the workflow.
+
+The providers chosen under mcp in workflow.yaml are written to .mcp.json in
+each project (the repo in single-repo workflows; the hub and every repo in
+multi-repo ones). Secrets are never written: each server's env uses ${VAR}
+placeholders that Claude Code fills in from your environment.
+
+Examples:
+  ccflow mcp list
+  ccflow mcp add linear
+  ccflow mcp remove github`,
+}
+
+var mcpAddCmd = &cobra.Command{
+	Use:   "add <provider>",
+	Short: "Add an MCP provider and write its server to .mcp.json",
+	Long: `Record an MCP provider in workflow.yaml and add its server to .mcp.json.
+A provider replaces any other provider of the same kind (vcs, tracker or
+deploy).
+
+Providers: ` + strings.Join(mcp.Names(), ", "),
+	Args: cobra.ExactArgs(1),
+	Run:  runMCPAdd,
+}
+
+var mcpRemoveCmd = &cobra.Command{
+	Use:   "remove <provider>",
+	Short: "Remove an MCP provider and its server from .mcp.json",
+	Args:  cobra.ExactArgs(1),
+	Run:   runMCPRemove,
+}
+
+var mcpListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured MCP providers and .mcp.json status",
+	Args:  cobra.NoArgs,
+	Run:   runMCPList,
+}
+
+func init() {
+	mcpCmd.AddCommand(mcpAddCmd)
+	mcpCmd.AddCommand(mcpRemoveCmd)
+	mcpCmd.AddCommand(mcpListCmd)
+}
+
+func runMCPAdd(cmd *cobra.Command, args []string) {
+	ws, err := workspace.Discover(workspaceFlag)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	p, err := mcp.Select(&ws.Config.MCP, args[0])
+	if err != nil {
+		exitWithError("%v", err)
+	}
+	saveMCPConfig(ws, "mcp add "+args[0])
+
+	printSuccess("Added MCP provider %s (%s)", p.Name, p.Category)
+	if vars := p.EnvVars(); len(vars) > 0 {
+		printInfo("Set %s in your environment before starting Claude Code", strings.Join(vars, ", "))
+	} else if setup := p.Setup(); setup != "" {
+		printInfo("To use it, %s", setup)
+	}
+}
+
+func runMCPRemove(cmd *cobra.Command, args []string) {
+	ws, err := workspace.Discover(workspaceFlag)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	p, err := mcp.Deselect(&ws.Config.MCP, args[0])
+	if err != nil {
+		exitWithError("%v", err)
+	}
+	saveMCPConfig(ws, "mcp remove "+args[0])
+
+	printSuccess("Removed MCP provider %s (%s)", p.Name, p.Category)
+}
+
+// saveMCPConfig writes workflow.yaml and brings every .mcp.json in line with
+// it, after taking a snapshot of them
+func saveMCPConfig(ws *workspace.Workspace, command string) {
+	paths := []string{ws.ConfigPath}
+	for _, root := range mcp.ProjectRoots(ws.Root, ws.Config) {
+		paths = append(paths, filepath.Join(root, mcp.FileName))
+	}
+	takeSnapshot(ws.Root, command, paths...)
+
+	if err := workspace.SaveConfig(ws.ConfigPath, ws.Config); err != nil {
+		exitWithError("failed to save workflow.yaml: %v", err)
+	}
+
+	for _, root := range mcp.ProjectRoots(ws.Root, ws.Config) {
+		change, err := mcp.Apply(root, ws.Config.MCP, false)
+		if err != nil {
+			exitWithError("%v", err)
+		}
+		if change.Changed() {
+			fmt.Printf("  Updated %s\n", relToWorkspace(ws, change.Path))
+		}
+	}
+}
+
+func runMCPList(cmd *cobra.Command, args []string) {
+	ws, err := workspace.Discover(workspaceFlag)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	selected := mcp.Selected(ws.Config.MCP)
+	if len(selected) == 0 {
+		fmt.Println("No MCP providers configured")
+		fmt.Printf("Add one with: ccflow mcp add <provider> (%s)\n", strings.Join(mcp.Names(), ", "))
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tKIND\tCOMMAND/URL\tENV")
+	for _, p := range selected {
+		var env []string
+		for _, v := range p.EnvVars() {
+			if os.Getenv(v) == "" {
+				v += " (unset)"
+			}
+			env = append(env, v)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Name, p.Category, p.Location(), strings.Join(env, ", "))
+	}
+	w.Flush()
+
+	fmt.Println()
+	outOfSync := 0
+	for _, root := range mcp.ProjectRoots(ws.Root, ws.Config) {
+		change, err := mcp.Apply(root, ws.Config.MCP, true)
+		relPath := relToWorkspace(ws, change.Path)
+		switch {
+		case err != nil:
+			printWarning("%v", err)
+			outOfSync++
+		case change.Changed():
+			printWarning("%s is out of date (%s)", relPath, describeMCPChange(change))
+			outOfSync++
+		default:
+			printSuccess("%s", relPath)
+		}
+	}
+	if outOfSync > 0 {
+		fmt.Println()
+		printInfo("Run 'ccflow mcp add %s' to update them from workflow.yaml", selected[0].Name)
+	}
+}
+
+// describeMCPChange summarizes the servers a change adds and removes
+func describeMCPChange(c mcp.Change) string {
+	var parts []string
+	if len(c.Added) > 0 {
+		parts = append(parts, "missing "+strings.Join(c.Added, ", "))
+	}
+	if len(c.Updated) > 0 {
+		parts = append(parts, "outdated "+strings.Join(c.Updated, ", "))
+	}
+	if len(c.Removed) > 0 {
+		parts = append(parts, "stale "+strings.Join(c.Removed, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// relToWorkspace returns path relative to the workspace root when possible
+func relToWorkspace(ws *workspace.Workspace, path string) string {
+	if rel, err := filepath.Rel(ws.Root, path); err == nil {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,81 @@
+package ccflow
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/feature"
+	"github.com/Wameedh/ccflow/internal/gate"
+	"github.com/Wameedh/ccflow/internal/transition"
+	"github.com/Wameedh/ccflow/internal/util"
+)
+
+var (
+	nextYesFlag bool
+)
+
+var nextCmd = &cobra.Command{
+	Use:   "next <feature>",
+	Short: "Move a feature to its next workflow phase",
+	Long: `Move a feature to its next phase (idea → design → implement → review →
+release), following the transition modes in workflow.yaml:
+
+  auto     advance the feature and print the next slash command
+  prompt   ask before advancing (--yes answers for you)
+  manual   only print the suggested next slash command
+
+When gates are enabled, the gate for the transition must pass before the
+feature is advanced (see 'ccflow gate check').
+
+Examples:
+  ccflow next login-page
+  ccflow next login-page --yes`,
+	Args: cobra.ExactArgs(1),
+	Run:  runNext,
+}
+
+func init() {
+	nextCmd.Flags().BoolVarP(&nextYesFlag, "yes", "y", false, "confirm prompt-mode transitions without asking")
+}
+
+func runNext(cmd *cobra.Command, args []string) {
+	ws, store := initFeatureStore()
+
+	var confirm transition.ConfirmFunc
+	switch {
+	case nextYesFlag:
+		confirm = func(transition.Transition, *feature.Feature) (bool, error) { return true, nil }
+	case util.IsTerminal(os.Stdin):
+		confirm = func(t transition.Transition, f *feature.Feature) (bool, error) {
+			var ok bool
+			err := survey.AskOne(&survey.Confirm{
+				Message: fmt.Sprintf("Move %s from %s to %s?", f.ID(), t.From, t.To),
+				Default: true,
+			}, &ok)
+			return ok, err
+		}
+	}
+
+	engine := transition.NewEngine(store, ws.Config.Transitions, confirm, gate.NewRunner(ws).Enforce)
+	result, err := engine.Next(args[0])
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	t := result.Transition
+	switch {
+	case result.Advanced:
+		printSuccess("%s: %s → %s (%s: %s)", result.Feature.ID(), result.FromStatus, result.Feature.Status(), t.Key, t.Mode)
+		printInfo("Next: run %s in Claude Code", result.Command)
+	case result.Declined:
+		fmt.Printf("%s stays in %s. When ready, run %s in Claude Code\n", result.Feature.ID(), result.FromStatus, result.Command)
+	case t.Mode == config.TransitionPrompt:
+		printInfo("Next: %s (%s asks for confirmation; rerun with --yes to advance)", result.Command, t.Key)
+	default:
+		printInfo("Next: %s (%s is %s)", result.Command, t.Key, t.Mode)
+	}
+}
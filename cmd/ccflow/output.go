@@ -0,0 +1,50 @@
+package ccflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats accepted by --output. Commands that support structured output
+// print the same fields in JSON and YAML; see docs/OUTPUT.md.
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+var outputFlag string
+
+// validateOutputFlag rejects unknown --output formats before any command runs
+func validateOutputFlag() error {
+	switch outputFlag {
+	case outputText, outputJSON, outputYAML:
+		return nil
+	}
+	return fmt.Errorf("invalid output format '%s': expected json, yaml or text", outputFlag)
+}
+
+// structuredOutput reports whether --output asks for JSON or YAML instead of
+// human-readable text
+func structuredOutput() bool {
+	return outputFlag == outputJSON || outputFlag == outputYAML
+}
+
+// printStructured writes v to stdout in the format --output asks for
+func printStructured(v interface{}) {
+	var data []byte
+	var err error
+	if outputFlag == outputYAML {
+		data, err = yaml.Marshal(v)
+	} else {
+		data, err = json.MarshalIndent(v, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		exitWithError("failed to encode output: %v", err)
+	}
+	os.Stdout.Write(data)
+}
@@ -0,0 +1,98 @@
+package ccflow
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Wameedh/ccflow/internal/parallel"
+	"github.com/Wameedh/ccflow/internal/workspace"
+)
+
+var parallelCmd = &cobra.Command{
+	Use:   "parallel",
+	Short: "Inspect parallel agent groups",
+	Long: `Parallel groups in workflow.yaml list agents that run at the same time
+within a phase (implement unless the group sets phase). A group's sync
+decides whether it joins after all of its agents finish or after the first;
+sync_gate decides the same for the groups of a phase before the transition
+to the next phase.`,
+}
+
+var parallelPlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Print the execution plan for phases and parallel groups",
+	Long: `Print which groups run concurrently in each phase, where they join and
+which transition follows, then validate the groups against the hub's agents.
+
+Examples:
+  ccflow parallel plan`,
+	Args: cobra.NoArgs,
+	Run:  runParallelPlan,
+}
+
+func init() {
+	parallelCmd.AddCommand(parallelPlanCmd)
+}
+
+func runParallelPlan(cmd *cobra.Command, args []string) {
+	ws, err := workspace.Discover(workspaceFlag)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	plan := parallel.BuildPlan(ws.Config)
+
+	state := "disabled"
+	if plan.Enabled {
+		state = "enabled"
+	}
+	fmt.Printf("Execution plan for %s (parallel %s, sync_gate: %s)\n", ws.Config.Name, state, plan.SyncGate)
+	fmt.Println(strings.Repeat("─", 50))
+
+	for _, stage := range plan.Stages {
+		fmt.Printf("%s (%s)\n", stage.Phase, stage.Phase.Command())
+
+		if len(stage.Groups) == 0 {
+			fmt.Println("  agents run one at a time")
+		}
+		for i, group := range stage.Groups {
+			branch := "├"
+			if len(stage.Groups) == 1 {
+				branch = "─"
+			} else if i == 0 {
+				branch = "┌"
+			} else if i == len(stage.Groups)-1 {
+				branch = "└"
+			}
+			fmt.Printf("  %s %s [%s]: %s\n", branch, group.Name, group.Sync, strings.Join(group.Agents, ", "))
+		}
+		if join := plan.Join(stage); join != "" {
+			fmt.Printf("  join: %s\n", join)
+		}
+
+		if stage.Transition != nil {
+			fmt.Printf("  ↓ %s (%s)\n", stage.Transition.Key, stage.Transition.Mode)
+		}
+	}
+	fmt.Println()
+
+	issues := parallel.Validate(ws.Config.Parallel, parallel.HubAgents(ws.GetHubPath()))
+	failed := false
+	for _, issue := range issues {
+		if issue.Severity == "error" {
+			fmt.Printf("✗ %s\n", issue)
+			failed = true
+		} else {
+			printWarning("%s", issue)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+	if len(issues) == 0 {
+		printSuccess("Parallel configuration is valid")
+	}
+}
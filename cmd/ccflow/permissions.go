@@ -36,7 +36,10 @@ var permissionsListCmd = &cobra.Command{
 	Long: `Display the repository permissions for all agents in the current workflow.
 
 If no explicit permissions are configured, agents have full read/write access
-to all repositories (default behavior for backward compatibility).`,
+to all repositories (default behavior for backward compatibility).
+
+Use --output json or --output yaml for every agent's access to every repo
+in a stable format (see docs/OUTPUT.md).`,
 	Run: runPermissionsList,
 }
 
@@ -107,6 +110,15 @@ func init() {
 func runPermissionsList(cmd *cobra.Command, args []string) {
 	ws, mgr := initPermissionsManager()
 
+	if structuredOutput() {
+		matrix, err := mgr.Matrix()
+		if err != nil {
+			exitWithError("failed to build permission matrix: %v", err)
+		}
+		printStructured(matrix)
+		return
+	}
+
 	perms := mgr.List()
 	repoNames := mgr.GetRepoNames()
 	agentNames, _ := mgr.GetAgentNames()
@@ -194,7 +206,7 @@ func runPermissionsShow(cmd *cobra.Command, args []string) {
 
 func runPermissionsSet(cmd *cobra.Command, args []string) {
 	agentName := args[0]
-	_, mgr := initPermissionsManager()
+	ws, mgr := initPermissionsManager()
 
 	// Validate agent exists
 	agentNames, _ := mgr.GetAgentNames()
@@ -223,6 +235,8 @@ func runPermissionsSet(cmd *cobra.Command, args []string) {
 		exitWithError("failed to set permissions: %v", err)
 	}
 
+	snapshotHub(ws, "permissions set "+agentName)
+
 	// Save config
 	if err := mgr.Save(); err != nil {
 		exitWithError("failed to save workflow config: %v", err)
@@ -240,7 +254,7 @@ func runPermissionsSet(cmd *cobra.Command, args []string) {
 
 func runPermissionsGrant(cmd *cobra.Command, args []string) {
 	agentName := args[0]
-	_, mgr := initPermissionsManager()
+	ws, mgr := initPermissionsManager()
 
 	// Validate agent exists
 	agentNames, _ := mgr.GetAgentNames()
@@ -279,6 +293,8 @@ func runPermissionsGrant(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	snapshotHub(ws, "permissions grant "+agentName)
+
 	// Save config
 	if err := mgr.Save(); err != nil {
 		exitWithError("failed to save workflow config: %v", err)
@@ -294,7 +310,7 @@ func runPermissionsGrant(cmd *cobra.Command, args []string) {
 
 func runPermissionsRevoke(cmd *cobra.Command, args []string) {
 	agentName := args[0]
-	_, mgr := initPermissionsManager()
+	ws, mgr := initPermissionsManager()
 
 	if writeReposFlag == "" && readReposFlag == "" {
 		exitWithError("specify --write or --read flag with repository name")
@@ -320,6 +336,8 @@ func runPermissionsRevoke(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	snapshotHub(ws, "permissions revoke "+agentName)
+
 	// Save config
 	if err := mgr.Save(); err != nil {
 		exitWithError("failed to save workflow config: %v", err)
@@ -0,0 +1,110 @@
+package ccflow
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Wameedh/ccflow/internal/workspace"
+)
+
+var registryPruneDryRunFlag bool
+
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Maintain the workflow registry",
+	Long: `Maintain the global registry of workflows at ~/.ccflow/registry.json.
+
+Registered paths are absolute, so a workflow that is moved or deleted stays
+listed by 'ccflow list' with its health showing the problem. Relocate moved
+workflows and prune the ones that are gone.
+
+Examples:
+  ccflow registry prune --dry-run
+  ccflow registry prune
+  ccflow registry relocate shop ~/code/shop`,
+}
+
+var registryPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Drop workflows that no longer exist",
+	Long: `Drop registered workflows whose path no longer exists or no longer holds
+a valid workflow.yaml. Nothing on disk is touched.`,
+	Args: cobra.NoArgs,
+	Run:  runRegistryPrune,
+}
+
+var registryRelocateCmd = &cobra.Command{
+	Use:   "relocate <name> <path>",
+	Short: "Point a registered workflow at its new path",
+	Long: `Point a registered workflow at the path it was moved to. The path must
+hold the workflow's workflow.yaml.`,
+	Args: cobra.ExactArgs(2),
+	Run:  runRegistryRelocate,
+}
+
+func init() {
+	registryPruneCmd.Flags().BoolVar(&registryPruneDryRunFlag, "dry-run", false, "show what would be dropped without changing the registry")
+
+	registryCmd.AddCommand(registryPruneCmd)
+	registryCmd.AddCommand(registryRelocateCmd)
+}
+
+func runRegistryPrune(cmd *cobra.Command, args []string) {
+	var pruned []workspace.RegistryEntry
+	err := workspace.UpdateRegistry(func(reg *workspace.Registry) error {
+		pruned = reg.Prune()
+		if registryPruneDryRunFlag || len(pruned) == 0 {
+			return workspace.ErrUnchanged
+		}
+		return nil
+	})
+	if err != nil {
+		exitWithError("failed to update registry: %v", err)
+	}
+
+	if len(pruned) == 0 {
+		printSuccess("All registered workflows are healthy")
+		return
+	}
+
+	for _, entry := range pruned {
+		if registryPruneDryRunFlag {
+			fmt.Printf("  - %s (%s): %s (would drop)\n", entry.Name, entry.Path, entry.Health().Problem)
+		} else {
+			fmt.Printf("  - %s (%s): %s\n", entry.Name, entry.Path, entry.Health().Problem)
+		}
+	}
+	fmt.Println()
+	if registryPruneDryRunFlag {
+		fmt.Println("This was a dry run. The registry was not changed.")
+	} else {
+		printSuccess("Dropped %d workflow(s) from the registry", len(pruned))
+	}
+}
+
+func runRegistryRelocate(cmd *cobra.Command, args []string) {
+	name := args[0]
+	path, err := filepath.Abs(args[1])
+	if err != nil {
+		exitWithError("failed to resolve path: %v", err)
+	}
+
+	ws, err := workspace.Discover(path)
+	if err != nil {
+		exitWithError("no workflow at %s: %v", path, err)
+	}
+
+	err = workspace.UpdateRegistry(func(reg *workspace.Registry) error {
+		return reg.Relocate(name, ws.Root)
+	})
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	printSuccess("Workflow '%s' now at %s", name, ws.Root)
+	if ws.Config.Name != name {
+		printWarning("workflow.yaml there is named '%s'", ws.Config.Name)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/snapshot"
 	"github.com/Wameedh/ccflow/internal/util"
 	"github.com/Wameedh/ccflow/internal/workspace"
 )
@@ -29,6 +30,9 @@ This command removes:
 - docs/workflow/ directory (unless --keep-docs)
 - Registry entry from ~/.ccflow/registry.json
 
+What is removed is snapshotted first under .ccflow/snapshots in the
+workspace root, which is kept, so 'ccflow undo' can restore the workflow.
+
 If run without arguments, removes the workflow in the current directory.
 If a workflow name is provided, removes that workflow from anywhere.
 
@@ -102,8 +106,15 @@ func runRemove(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	// Snapshot what is removed so that ccflow undo can bring it back
+	var paths []string
+	for _, item := range items {
+		paths = append(paths, item.path)
+	}
+	takeSnapshot(ws.Root, "remove", paths...)
+
 	// Execute removal
-	results := executeRemoval(items)
+	results := executeRemoval(ws.Root, items)
 
 	// Clean up registry
 	registryRemoved := removeFromRegistry(ws.Root)
@@ -213,7 +224,9 @@ type removalResult struct {
 	err     error
 }
 
-func executeRemoval(items []removalItem) []removalResult {
+// executeRemoval removes each item, keeping the workspace's snapshots
+func executeRemoval(root string, items []removalItem) []removalResult {
+	store := snapshot.New(root)
 	var results []removalResult
 
 	for _, item := range items {
@@ -221,7 +234,7 @@ func executeRemoval(items []removalItem) []removalResult {
 		if item.isSymlink {
 			err = util.RemoveSymlink(item.path)
 		} else {
-			err = util.RemoveAll(item.path)
+			err = store.RemoveAll(item.path)
 		}
 
 		results = append(results, removalResult{
@@ -235,17 +248,12 @@ func executeRemoval(items []removalItem) []removalResult {
 }
 
 func removeFromRegistry(workspacePath string) bool {
-	reg, err := workspace.LoadRegistry()
-	if err != nil {
-		return false
-	}
-
-	removed := reg.RemoveWorkflow(workspacePath)
-	if removed {
-		_ = workspace.SaveRegistry(reg) // Best effort
-	}
-
-	return removed
+	removed := false
+	err := workspace.UpdateRegistry(func(reg *workspace.Registry) error {
+		removed = reg.RemoveWorkflow(workspacePath)
+		return nil
+	})
+	return err == nil && removed
 }
 
 func printRemovalResults(ws *workspace.Workspace, results []removalResult, registryRemoved bool) {
@@ -270,6 +278,7 @@ func printRemovalResults(ws *workspace.Workspace, results []removalResult, regis
 	fmt.Println()
 	if allSuccess {
 		fmt.Printf("Workflow '%s' removed successfully.\n", ws.Config.Name)
+		fmt.Printf("Run 'ccflow undo' in %s to restore it.\n", ws.Root)
 	} else {
 		fmt.Printf("Workflow '%s' partially removed (some errors occurred).\n", ws.Config.Name)
 	}
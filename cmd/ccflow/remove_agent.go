@@ -0,0 +1,117 @@
+package ccflow
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Wameedh/ccflow/internal/blueprint"
+	"github.com/Wameedh/ccflow/internal/installer"
+	"github.com/Wameedh/ccflow/internal/mutator"
+	"github.com/Wameedh/ccflow/internal/settings"
+	"github.com/Wameedh/ccflow/internal/workspace"
+)
+
+var removeAgentDryRunFlag bool
+
+var removeAgentCmd = &cobra.Command{
+	Use:   "remove-agent <name>",
+	Short: "Remove an agent from the workflow",
+	Long: `Remove an agent from the workflow.
+
+Deletes agents/<name>.md from the hub, drops the agent from agent_permissions
+and parallel groups in workflow.yaml, and records the removal so that
+'ccflow upgrade' does not bring a blueprint agent back.
+
+Examples:
+  ccflow remove-agent devops-agent
+  ccflow remove-agent devops-agent --dry-run   # Preview the changes`,
+	Args: cobra.ExactArgs(1),
+	Run:  removeAgent,
+}
+
+func init() {
+	removeAgentCmd.Flags().BoolVar(&removeAgentDryRunFlag, "dry-run", false, "show what would be removed without making changes")
+}
+
+func removeAgent(cmd *cobra.Command, args []string) {
+	ws, mut, opts := removeOptions("remove-agent", args[0], removeAgentDryRunFlag)
+
+	removal, err := mut.RemoveAgent(opts)
+	if err != nil {
+		exitWithError("failed to remove agent: %v", err)
+	}
+
+	finishRemoval(ws, "Agent", args[0], removal, opts.DryRun)
+}
+
+// removeOptions discovers the workspace and prepares a removal, taking a
+// snapshot unless it's a dry run
+func removeOptions(command, name string, dryRun bool) (*workspace.Workspace, *mutator.Mutator, mutator.RemoveOptions) {
+	ws, err := workspace.Discover(workspaceFlag)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	bpManager, err := blueprint.NewManager()
+	if err != nil {
+		exitWithError("failed to initialize blueprints: %v", err)
+	}
+
+	if !dryRun {
+		snapshotHub(ws, command+" "+name)
+	}
+
+	return ws, mutator.New(bpManager), mutator.RemoveOptions{
+		Name:        name,
+		BlueprintID: ws.Config.Blueprint,
+		HubPath:     ws.GetHubPath(),
+		Config:      ws.Config,
+		DryRun:      dryRun,
+	}
+}
+
+// finishRemoval saves workflow.yaml when the removal changed it and reports
+// what was, or would be, removed
+func finishRemoval(ws *workspace.Workspace, kind, name string, removal *mutator.Removal, dryRun bool) {
+	if removal.ConfigChanged() && !dryRun {
+		if err := workspace.SaveConfig(ws.ConfigPath, ws.Config); err != nil {
+			exitWithError("%s removed but failed to save workflow.yaml: %v", removal.Path, err)
+		}
+	}
+
+	var changes []string
+	if removal.FileExisted {
+		changes = append(changes, removal.Path)
+	}
+	for _, event := range removal.Events {
+		changes = append(changes, fmt.Sprintf("%s: %s hook", settings.FileName, event))
+	}
+	if removal.Permissions {
+		changes = append(changes, fmt.Sprintf("workflow.yaml: agent_permissions.%s", name))
+	}
+	for _, group := range removal.Groups {
+		changes = append(changes, fmt.Sprintf("workflow.yaml: parallel group %s", group))
+	}
+	if removal.Managed {
+		changes = append(changes, fmt.Sprintf("%s: %s", workspace.ManifestFile, removal.Path))
+	}
+
+	if dryRun {
+		fmt.Printf("Would remove %s '%s':\n", strings.ToLower(kind), name)
+		for _, change := range changes {
+			fmt.Printf("  -> %s\n", change)
+		}
+		fmt.Println("\nThis was a dry run. No files were modified.")
+		return
+	}
+
+	printSuccess("%s '%s' removed", kind, name)
+	for _, change := range changes {
+		fmt.Printf("  - %s\n", change)
+	}
+	if ws.Config.Install.Mode == string(installer.InstallModeCopy) {
+		printInfo("Repos use copied .claude directories. Run 'ccflow sync' to push this change.")
+	}
+}
@@ -0,0 +1,37 @@
+package ccflow
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var removeCommandDryRunFlag bool
+
+var removeCommandCmd = &cobra.Command{
+	Use:   "remove-command <name>",
+	Short: "Remove a command from the workflow",
+	Long: `Remove a command from the workflow.
+
+Deletes commands/<name>.md from the hub and records the removal so that
+'ccflow upgrade' does not bring a blueprint command back.
+
+Examples:
+  ccflow remove-command deploy
+  ccflow remove-command deploy --dry-run   # Preview the changes`,
+	Args: cobra.ExactArgs(1),
+	Run:  removeCommand,
+}
+
+func init() {
+	removeCommandCmd.Flags().BoolVar(&removeCommandDryRunFlag, "dry-run", false, "show what would be removed without making changes")
+}
+
+func removeCommand(cmd *cobra.Command, args []string) {
+	ws, mut, opts := removeOptions("remove-command", args[0], removeCommandDryRunFlag)
+
+	removal, err := mut.RemoveCommand(opts)
+	if err != nil {
+		exitWithError("failed to remove command: %v", err)
+	}
+
+	finishRemoval(ws, "Command", args[0], removal, opts.DryRun)
+}
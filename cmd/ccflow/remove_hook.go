@@ -0,0 +1,38 @@
+package ccflow
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var removeHookDryRunFlag bool
+
+var removeHookCmd = &cobra.Command{
+	Use:   "remove-hook <name>",
+	Short: "Remove a hook from the workflow",
+	Long: `Remove a hook from the workflow.
+
+Deletes hooks/<name>.sh from the hub, deregisters it from every event in
+settings.json, and records the removal so that 'ccflow upgrade' neither
+recreates nor re-registers a blueprint hook.
+
+Examples:
+  ccflow remove-hook post-edit
+  ccflow remove-hook post-edit --dry-run   # Preview the changes`,
+	Args: cobra.ExactArgs(1),
+	Run:  removeHook,
+}
+
+func init() {
+	removeHookCmd.Flags().BoolVar(&removeHookDryRunFlag, "dry-run", false, "show what would be removed without making changes")
+}
+
+func removeHook(cmd *cobra.Command, args []string) {
+	ws, mut, opts := removeOptions("remove-hook", args[0], removeHookDryRunFlag)
+
+	removal, err := mut.RemoveHook(opts)
+	if err != nil {
+		exitWithError("failed to remove hook: %v", err)
+	}
+
+	finishRemoval(ws, "Hook", args[0], removal, opts.DryRun)
+}
@@ -0,0 +1,105 @@
+package ccflow
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/Wameedh/ccflow/internal/installer"
+	"github.com/Wameedh/ccflow/internal/settings"
+	"github.com/Wameedh/ccflow/internal/util"
+	"github.com/Wameedh/ccflow/internal/workspace"
+)
+
+var resetCmd = &cobra.Command{
+	Use:   "reset <path>",
+	Short: "Restore a file to its template version",
+	Long: `Restore a file in the hub's .claude directory to its template version.
+
+The template is rendered afresh from workflow.yaml and written over the file,
+which is recorded as managed again, so 'ccflow upgrade' updates it from then
+on. A file removed with ccflow remove-* is restored too. Any .new file left
+by an earlier upgrade is deleted.
+
+You are asked to confirm before local changes are discarded, unless --force
+is given. Use 'ccflow diff <path>' to see them first.
+
+Examples:
+  ccflow reset agents/product-agent.md
+  ccflow reset settings.json --force`,
+	Args: cobra.ExactArgs(1),
+	Run:  runReset,
+}
+
+func runReset(cmd *cobra.Command, args []string) {
+	ws, bpManager, bp, manifest := loadManagedFiles()
+	hubPath := ws.GetHubPath()
+	relPath := hubRelPath(hubPath, args[0])
+
+	content, err := renderManagedFile(ws, bpManager, bp, manifest, relPath)
+	if err != nil {
+		exitWithError("no template for %s: %v", relPath, err)
+	}
+
+	filePath := filepath.Join(hubPath, relPath)
+	current, err := os.ReadFile(filePath)
+	exists := err == nil
+	if exists && bytes.Equal(current, content) && !manifest.IsRemoved(relPath) {
+		if _, managed := manifest.Files[relPath]; managed || relPath == settings.FileName {
+			printInfo("%s already matches its template", relPath)
+			return
+		}
+	}
+
+	if exists && !forceFlag {
+		var confirm bool
+		prompt := &survey.Confirm{
+			Message: fmt.Sprintf("Discard your changes to %s?", relPath),
+			Default: false,
+		}
+		if surveyErr := survey.AskOne(prompt, &confirm); surveyErr != nil {
+			exitWithError("prompt failed: %v", surveyErr)
+		}
+		if !confirm {
+			fmt.Println("Reset canceled.")
+			return
+		}
+	}
+
+	snapshotHub(ws, "reset "+relPath)
+	if filepath.Dir(relPath) == "hooks" {
+		err = util.SafeWriteExecutable(filePath, content, true)
+	} else {
+		err = util.SafeWriteFile(filePath, content, true)
+	}
+	if err != nil {
+		exitWithError("failed to write %s: %v", relPath, err)
+	}
+
+	if relPath == settings.FileName {
+		err = workspace.SaveBase(hubPath, relPath, content)
+	} else {
+		templateID := templateBlueprint(bp, manifest, relPath) + "/" + relPath
+		if err = workspace.RecordManaged(hubPath, manifest, templateID, relPath, content); err == nil {
+			err = workspace.SaveManifest(hubPath, manifest)
+		}
+	}
+	if err != nil {
+		exitWithError("%s reset but failed to record it in %s: %v", relPath, workspace.ManifestFile, err)
+	}
+
+	if util.FileExists(filePath + ".new") {
+		if err := os.Remove(filePath + ".new"); err != nil {
+			printWarning("failed to remove %s.new: %v", relPath, err)
+		}
+	}
+
+	printSuccess("%s reset to its template version", relPath)
+	if ws.Config.Install.Mode == string(installer.InstallModeCopy) {
+		printInfo("Repos use copied .claude directories. Run 'ccflow sync' to push this change.")
+	}
+}
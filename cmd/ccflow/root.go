@@ -45,18 +45,37 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVarP(&workspaceFlag, "workspace", "w", "", "workspace path (overrides auto-detection)")
 	rootCmd.PersistentFlags().BoolVarP(&forceFlag, "force", "f", false, "force overwrite existing files")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", outputText, "output format for status, doctor, list, list-blueprints and permissions list: text, json or yaml")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return validateOutputFlag()
+	}
 
 	// Add commands
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(listBlueprintsCmd)
+	rootCmd.AddCommand(blueprintCmd)
 	rootCmd.AddCommand(statusCmd)
 	rootCmd.AddCommand(doctorCmd)
 	rootCmd.AddCommand(addAgentCmd)
 	rootCmd.AddCommand(addCommandCmd)
 	rootCmd.AddCommand(addHookCmd)
+	rootCmd.AddCommand(removeAgentCmd)
+	rootCmd.AddCommand(removeCommandCmd)
+	rootCmd.AddCommand(removeHookCmd)
 	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(registryCmd)
 	rootCmd.AddCommand(upgradeCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(resetCmd)
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(snapshotsCmd)
 	rootCmd.AddCommand(expandCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(featureCmd)
+	rootCmd.AddCommand(nextCmd)
+	rootCmd.AddCommand(gateCmd)
+	rootCmd.AddCommand(parallelCmd)
+	rootCmd.AddCommand(mcpCmd)
 	rootCmd.AddCommand(removeCmd)
 	rootCmd.AddCommand(permissionsCmd)
 	rootCmd.AddCommand(versionCmd)
@@ -14,6 +14,7 @@ import (
 	"github.com/Wameedh/ccflow/internal/config"
 	"github.com/Wameedh/ccflow/internal/generator"
 	"github.com/Wameedh/ccflow/internal/installer"
+	"github.com/Wameedh/ccflow/internal/mcp"
 	"github.com/Wameedh/ccflow/internal/util"
 	"github.com/Wameedh/ccflow/internal/workspace"
 )
@@ -27,14 +28,45 @@ var runCmd = &cobra.Command{
 If a blueprint is specified, it will be used as the starting point.
 Otherwise, you will be prompted to select one.
 
+The wizard can be skipped entirely by supplying answers with --answers
+and/or the answer flags. In that mode nothing is prompted, and a missing
+required answer (blueprint, name, topology) is an error. When stdin is not
+a terminal, ccflow refuses to prompt and requires non-interactive answers.
+
 Examples:
   ccflow run                  # Interactive blueprint selection
   ccflow run web-dev          # Use web-dev blueprint
-  ccflow run ios-dev          # Use ios-dev blueprint`,
+  ccflow run ios-dev          # Use ios-dev blueprint
+  ccflow run --answers answers.yaml
+  ccflow run go-cli-dev --name my-cli --topology single-repo --vcs github
+  ccflow run web-dev --name shop --topology multi-repo \
+    --repo web:web:node --repo api --transition auto`,
 	Args: cobra.MaximumNArgs(1),
 	Run:  runWorkflow,
 }
 
+var (
+	runAnswersFlag     string
+	runNameFlag        string
+	runTopologyFlag    string
+	runRepoFlags       []string
+	runVCSFlag         string
+	runTrackerFlag     string
+	runTransitionFlags []string
+	runInstallModeFlag string
+)
+
+func init() {
+	runCmd.Flags().StringVar(&runAnswersFlag, "answers", "", "read wizard answers from a YAML file (non-interactive)")
+	runCmd.Flags().StringVar(&runNameFlag, "name", "", "workflow name")
+	runCmd.Flags().StringVar(&runTopologyFlag, "topology", "", "topology: single-repo or multi-repo")
+	runCmd.Flags().StringArrayVar(&runRepoFlags, "repo", nil, "repository as name[:path[:kind]] (repeatable, multi-repo only)")
+	runCmd.Flags().StringVar(&runVCSFlag, "vcs", "", "code host: github, gitlab or none")
+	runCmd.Flags().StringVar(&runTrackerFlag, "tracker", "", "issue tracker: linear, jira or none")
+	runCmd.Flags().StringArrayVar(&runTransitionFlags, "transition", nil, "transition mode for all phases, or phase=mode (repeatable)")
+	runCmd.Flags().StringVar(&runInstallModeFlag, "install-mode", "", "how repos get the hub .claude: symlink (default) or copy")
+}
+
 type wizardAnswers struct {
 	workflowName  string
 	workspacePath string
@@ -46,6 +78,7 @@ type wizardAnswers struct {
 	tracker       config.TrackerProvider
 	transitions   config.TransitionsConfig
 	parallel      config.ParallelConfig
+	installMode   installer.InstallMode
 }
 
 func runWorkflow(cmd *cobra.Command, args []string) {
@@ -55,33 +88,64 @@ func runWorkflow(cmd *cobra.Command, args []string) {
 		exitWithError("failed to initialize blueprints: %v", err)
 	}
 
-	// Show welcome message
-	printWelcome()
+	var blueprintID string
+	var answers wizardAnswers
+	interactive := !isNonInteractiveRun(cmd)
 
-	// Determine setup mode (quick vs custom)
-	setupMode := promptSetupMode()
+	if interactive {
+		// Refuse to prompt when nobody can answer
+		if !util.IsTerminal(os.Stdin) {
+			exitWithError("stdin is not a terminal; provide answers with --answers or flags (--name, --topology, ...)")
+		}
 
-	// Determine blueprint
-	var blueprintID string
-	if len(args) > 0 {
-		blueprintID = args[0]
+		// Show welcome message
+		printWelcome()
+
+		// Determine setup mode (quick vs custom)
+		setupMode := promptSetupMode()
+
+		// Determine blueprint
+		if len(args) > 0 {
+			blueprintID = args[0]
+			if _, getErr := bpManager.Get(blueprintID); getErr != nil {
+				exitWithError("unknown blueprint: %s\nRun 'ccflow list-blueprints' to see available blueprints", blueprintID)
+			}
+		} else {
+			blueprintID = promptBlueprintSection(bpManager)
+		}
+
+		bp, _ := bpManager.Get(blueprintID)
+
+		// Run wizard prompts based on setup mode
+		if setupMode == "quick" {
+			answers = runQuickSetup(bp)
+		} else {
+			answers = runCustomSetup(bp)
+		}
+	} else {
+		var buildErr error
+		blueprintID, answers, buildErr = buildNonInteractiveAnswers(cmd, args)
+		if buildErr != nil {
+			exitWithError("%v", buildErr)
+		}
 		if _, getErr := bpManager.Get(blueprintID); getErr != nil {
 			exitWithError("unknown blueprint: %s\nRun 'ccflow list-blueprints' to see available blueprints", blueprintID)
 		}
-	} else {
-		blueprintID = promptBlueprintSection(bpManager)
 	}
 
-	bp, _ := bpManager.Get(blueprintID)
-
-	// Run wizard prompts based on setup mode
-	var answers wizardAnswers
-	if setupMode == "quick" {
-		answers = runQuickSetup(bp)
-	} else {
-		answers = runCustomSetup(bp)
+	// --install-mode applies to both the wizard and non-interactive runs
+	if cmd.Flags().Changed("install-mode") {
+		mode, modeErr := installer.ParseInstallMode(runInstallModeFlag)
+		if modeErr != nil {
+			exitWithError("%v", modeErr)
+		}
+		answers.installMode = mode
+	} else if answers.installMode == "" {
+		answers.installMode = installer.InstallModeSymlink
 	}
 
+	bp, _ := bpManager.Get(blueprintID)
+
 	// Generate workflow
 	gen := generator.New(bpManager)
 	opts := generator.GenerateOptions{
@@ -96,6 +160,7 @@ func runWorkflow(cmd *cobra.Command, args []string) {
 		Tracker:       answers.tracker,
 		Transitions:   answers.transitions,
 		Parallel:      answers.parallel,
+		InstallMode:   string(answers.installMode),
 		Force:         forceFlag,
 	}
 
@@ -116,6 +181,10 @@ func runWorkflow(cmd *cobra.Command, args []string) {
 				fmt.Printf("    - %s\n", relPath)
 			}
 
+			if !interactive {
+				exitWithError("files already exist. Use --force to overwrite")
+			}
+
 			var overwrite bool
 			promptErr := survey.AskOne(&survey.Confirm{
 				Message: "Overwrite existing files?",
@@ -135,6 +204,13 @@ func runWorkflow(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	// Snapshot an existing workflow before overwriting it
+	if opts.Force {
+		if existing, _ := gen.CheckExistingFiles(opts); len(existing) > 0 {
+			takeSnapshot(answers.workspacePath, "run", overwrittenPaths(answers)...)
+		}
+	}
+
 	cfg, err := gen.Generate(opts)
 	if err != nil {
 		exitWithError("failed to generate workflow: %v", err)
@@ -149,7 +225,7 @@ func runWorkflow(cmd *cobra.Command, args []string) {
 			HubPath:       hubPath,
 			Repos:         answers.repos,
 			WorkspacePath: answers.workspacePath,
-			Mode:          installer.InstallModeSymlink,
+			Mode:          answers.installMode,
 			Force:         opts.Force,
 		})
 
@@ -717,11 +793,6 @@ func promptParallelConfig() config.ParallelConfig {
 }
 
 func registerWorkflow(workspacePath string, cfg *config.WorkflowConfig) {
-	reg, err := workspace.LoadRegistry()
-	if err != nil {
-		return // Silently fail - registry is optional
-	}
-
 	entry := workspace.RegistryEntry{
 		Name:       cfg.Name,
 		Path:       workspacePath,
@@ -730,8 +801,28 @@ func registerWorkflow(workspacePath string, cfg *config.WorkflowConfig) {
 		LastUsedAt: time.Now(),
 	}
 
-	reg.AddOrUpdateWorkflow(entry)
-	_ = workspace.SaveRegistry(reg) // Best effort, non-critical
+	// Best effort, non-critical - registry is optional
+	_ = workspace.UpdateRegistry(func(reg *workspace.Registry) error {
+		reg.AddOrUpdateWorkflow(entry)
+		return nil
+	})
+}
+
+// overwrittenPaths returns what generating a workflow over an existing one
+// writes: the .claude and ccflow directories, .mcp.json files, .gitignore
+// and, in multi-repo workflows, the repos' .claude
+func overwrittenPaths(answers wizardAnswers) []string {
+	root := answers.workspacePath
+	paths := []string{filepath.Join(root, ".gitignore")}
+	if answers.topology == config.TopologyMultiRepo {
+		paths = append(paths, filepath.Join(root, "workflow-hub"))
+		for _, repo := range answers.repos {
+			paths = append(paths, filepath.Join(root, repo.Path, ".claude"), filepath.Join(root, repo.Path, mcp.FileName))
+		}
+	} else {
+		paths = append(paths, filepath.Join(root, ".claude"), filepath.Join(root, ".ccflow"), filepath.Join(root, mcp.FileName))
+	}
+	return paths
 }
 
 // printWorkflowSummary displays the success message with ASCII workflow diagram
@@ -840,17 +931,13 @@ func printWorkflowSummary(answers wizardAnswers, cfg *config.WorkflowConfig, bp
 	fmt.Println()
 
 	// Integration hints
-	switch answers.vcs {
-	case config.VCSGitHub:
-		fmt.Println("  Optional: Enhanced Integration")
+	if providers := mcp.Selected(cfg.MCP); len(providers) > 0 {
+		fmt.Println("  MCP Servers")
 		fmt.Println("  " + strings.Repeat("─", 70))
-		fmt.Println("    For full GitHub integration (auto-create PRs, etc.), install the")
-		fmt.Println("    GitHub MCP server: https://github.com/modelcontextprotocol/servers")
-		fmt.Println()
-	case config.VCSGitLab:
-		fmt.Println("  Optional: Enhanced Integration")
-		fmt.Println("  " + strings.Repeat("─", 70))
-		fmt.Println("    For full GitLab integration, install the GitLab MCP server")
+		fmt.Println("    .mcp.json configures these servers; before using them:")
+		for _, p := range providers {
+			fmt.Printf("    %-10s %s\n", p.Name, p.Setup())
+		}
 		fmt.Println()
 	}
 
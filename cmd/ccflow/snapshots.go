@@ -0,0 +1,148 @@
+package ccflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Wameedh/ccflow/internal/snapshot"
+	"github.com/Wameedh/ccflow/internal/workspace"
+)
+
+var snapshotsCmd = &cobra.Command{
+	Use:   "snapshots",
+	Short: "List and restore snapshots taken before changes",
+	Long: `List and restore the snapshots ccflow takes before it changes files.
+
+Commands that overwrite or delete files (upgrade, expand, remove, reset,
+sync, run --force, add-*, remove-*, permissions set/grant/revoke and mcp
+add/remove) first record the files they touch under
+.ccflow/snapshots/<timestamp>/ in the workspace root, with a journal.json
+describing them. The last 20 snapshots are kept.
+
+Restoring a snapshot rolls back the command it was taken before and every
+command after it, newest first, and uses those snapshots up.
+
+Examples:
+  ccflow snapshots list
+  ccflow snapshots restore 20260105-143012.512
+  ccflow undo                # Roll back the last command`,
+}
+
+var snapshotsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List snapshots, newest first",
+	Args:  cobra.NoArgs,
+	Run:   runSnapshotsList,
+}
+
+var snapshotsRestoreCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "Roll back to the state before a snapshot's command",
+	Args:  cobra.ExactArgs(1),
+	Run:   runSnapshotsRestore,
+}
+
+func init() {
+	snapshotsCmd.AddCommand(snapshotsListCmd)
+	snapshotsCmd.AddCommand(snapshotsRestoreCmd)
+}
+
+func runSnapshotsList(cmd *cobra.Command, args []string) {
+	store := snapshotStore()
+	journals, err := store.List()
+	if err != nil {
+		exitWithError("failed to read snapshots: %v", err)
+	}
+	if len(journals) == 0 {
+		fmt.Println("No snapshots.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCOMMAND\tFILES\tTAKEN")
+	for _, j := range journals {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", j.ID, j.Command, j.Files(), formatTime(j.CreatedAt))
+	}
+	w.Flush()
+}
+
+func runSnapshotsRestore(cmd *cobra.Command, args []string) {
+	store := snapshotStore()
+	restored, err := store.RestoreTo(args[0])
+	reportRestored(store, restored)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+}
+
+// snapshotStore returns the snapshots of the workspace. A workflow that is
+// gone, e.g. after ccflow remove, is looked for at --workspace or the
+// current directory.
+func snapshotStore() *snapshot.Store {
+	if ws, err := workspace.Discover(workspaceFlag); err == nil {
+		return snapshot.New(ws.Root)
+	}
+
+	root := workspaceFlag
+	if root == "" {
+		root = os.Getenv(workspace.EnvWorkspace)
+	}
+	if root == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			exitWithError("failed to get current directory: %v", err)
+		}
+		root = cwd
+	}
+	return snapshot.New(root)
+}
+
+// reportRestored lists the commands rolled back and registers a workflow
+// that a rolled-back ccflow remove brought back
+func reportRestored(store *snapshot.Store, restored []*snapshot.Journal) {
+	removed := false
+	for _, j := range restored {
+		printSuccess("Rolled back '%s' (%s, %d file(s))", j.Command, j.ID, j.Files())
+		removed = removed || j.Command == "remove"
+	}
+	if !removed {
+		return
+	}
+
+	ws, err := workspace.Discover(store.Root)
+	if err != nil {
+		return
+	}
+	if reg, err := workspace.LoadRegistry(); err == nil && reg.FindByPath(ws.Root) == nil {
+		registerWorkflow(ws.Root, ws.Config)
+		printSuccess("Registered workflow '%s'", ws.Config.Name)
+	}
+}
+
+// takeSnapshot records paths before a command changes them, so that ccflow
+// undo can roll the change back. A snapshot that can't be taken is reported
+// but doesn't stop the command.
+func takeSnapshot(root, command string, paths ...string) {
+	if _, err := snapshot.New(root).Take(command, paths...); err != nil {
+		printWarning("failed to take a snapshot before '%s': %v", command, err)
+	}
+}
+
+// snapshotHub records what commands that change the hub touch: its .claude
+// directory, workflow.yaml and ccflow's own state
+func snapshotHub(ws *workspace.Workspace, command string) {
+	takeSnapshot(ws.Root, command, ws.GetHubPath(), ws.ConfigPath, ws.GetCCFlowPath())
+}
+
+// repoClaudePaths returns the .claude path of every repo in the workflow
+func repoClaudePaths(ws *workspace.Workspace) []string {
+	var paths []string
+	for _, repo := range ws.Config.Repos {
+		paths = append(paths, filepath.Join(ws.Root, repo.Path, ".claude"))
+	}
+	return paths
+}
@@ -23,7 +23,10 @@ var statusCmd = &cobra.Command{
 Exit codes:
   0 - Healthy
   2 - Warnings (non-fatal issues)
-  3 - Errors (broken workflow)`,
+  3 - Errors (broken workflow)
+
+Use --output json or --output yaml for the result in a stable format
+(see docs/OUTPUT.md). Exit codes are the same.`,
 	Run: showStatus,
 }
 
@@ -38,6 +41,11 @@ func showStatus(cmd *cobra.Command, args []string) {
 	v := validator.New()
 	result := v.Status(ws)
 
+	if structuredOutput() {
+		printStructured(result)
+		os.Exit(statusExitCode(result))
+	}
+
 	// Print results
 	fmt.Println("Workflow Status")
 	fmt.Println("===============")
@@ -85,13 +93,11 @@ func showStatus(cmd *cobra.Command, args []string) {
 	fmt.Println()
 
 	// Warnings and errors
-	exitCode := 0
 	if len(result.Warnings) > 0 {
 		fmt.Println("Warnings:")
 		for _, w := range result.Warnings {
 			fmt.Printf("  ⚠ %s\n", w)
 		}
-		exitCode = 2
 	}
 
 	if len(result.Errors) > 0 {
@@ -99,9 +105,9 @@ func showStatus(cmd *cobra.Command, args []string) {
 		for _, e := range result.Errors {
 			fmt.Printf("  ✗ %s\n", e)
 		}
-		exitCode = 3
 	}
 
+	exitCode := statusExitCode(result)
 	if exitCode == 0 {
 		fmt.Println("Status: Healthy ✓")
 	}
@@ -109,6 +115,18 @@ func showStatus(cmd *cobra.Command, args []string) {
 	os.Exit(exitCode)
 }
 
+// statusExitCode returns 0 for a healthy workflow, 2 for warnings and 3 for
+// errors
+func statusExitCode(result *validator.StatusResult) int {
+	switch {
+	case len(result.Errors) > 0:
+		return 3
+	case len(result.Warnings) > 0:
+		return 2
+	}
+	return 0
+}
+
 func enabledString(enabled bool) string {
 	if enabled {
 		return "enabled"
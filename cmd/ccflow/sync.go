@@ -0,0 +1,114 @@
+package ccflow
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/installer"
+	"github.com/Wameedh/ccflow/internal/workspace"
+)
+
+var (
+	syncDryRunFlag bool
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Push hub changes into copied .claude directories",
+	Long: `Push changes from the workflow hub into repos whose .claude was installed
+in copy mode (ccflow run --install-mode copy).
+
+For each file in a copied .claude:
+- Files unchanged in the repo are updated, added or removed to match the hub
+- Files edited in the repo are kept when the hub has not changed them
+- Files changed in both the hub and the repo are reported as conflicts
+  and left untouched
+
+Repos that symlink to the hub are skipped. Use --force to replace local
+edits and conflicts with the hub version, and --dry-run to see what would
+change without modifying files. Exits non-zero if conflicts remain.`,
+	Run: runSync,
+}
+
+func init() {
+	syncCmd.Flags().BoolVar(&syncDryRunFlag, "dry-run", false, "show what would change without modifying files")
+}
+
+func runSync(cmd *cobra.Command, args []string) {
+	// Discover workspace
+	ws, err := workspace.Discover(workspaceFlag)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	if ws.Topology != config.TopologyMultiRepo {
+		exitWithError("sync only applies to multi-repo workflows")
+	}
+
+	if !syncDryRunFlag {
+		takeSnapshot(ws.Root, "sync", repoClaudePaths(ws)...)
+	}
+
+	inst := installer.New()
+	results := inst.Sync(installer.SyncOptions{
+		HubPath:       ws.GetHubPath(),
+		Repos:         ws.Config.Repos,
+		WorkspacePath: ws.Root,
+		DryRun:        syncDryRunFlag,
+		Force:         forceFlag,
+	})
+
+	fmt.Println("Syncing hub to repositories")
+	fmt.Println("===========================")
+	fmt.Println()
+
+	failed, conflicts := 0, 0
+	for _, r := range results {
+		switch {
+		case r.Error != nil:
+			fmt.Printf("  ✗ %s: %v\n", r.RepoName, r.Error)
+			failed++
+			continue
+		case r.Skipped || len(r.Changes) == 0:
+			printInfo("  %s: %s", r.RepoName, r.Message)
+			continue
+		}
+
+		fmt.Printf("  %s:\n", r.RepoName)
+		for _, c := range r.Changes {
+			switch c.Action {
+			case installer.SyncLocalEdit:
+				if forceFlag {
+					fmt.Printf("    ✓ %s (local edit replaced)\n", c.Path)
+				} else {
+					fmt.Printf("    ⚠ %s (edited locally, kept)\n", c.Path)
+				}
+			case installer.SyncConflict:
+				if forceFlag {
+					fmt.Printf("    ✓ %s (conflict, replaced with hub version)\n", c.Path)
+				} else {
+					fmt.Printf("    ✗ %s (conflict: changed in hub and locally)\n", c.Path)
+					conflicts++
+				}
+			default:
+				fmt.Printf("    ✓ %s (%s)\n", c.Path, c.Action)
+			}
+		}
+	}
+
+	if syncDryRunFlag {
+		fmt.Println()
+		fmt.Println("This was a dry run. No files were modified.")
+	}
+
+	if conflicts > 0 {
+		fmt.Println()
+		fmt.Printf("%d conflict(s). Merge the hub changes by hand, or rerun with --force to take the hub version.\n", conflicts)
+	}
+	if failed > 0 || conflicts > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,40 @@
+package ccflow
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [n]",
+	Short: "Roll back the last commands that changed files",
+	Long: `Roll back the last n commands (1 by default) that changed files, newest
+first, from the snapshots taken before they ran. Files they changed or
+deleted are restored and files they created are removed.
+
+Run 'ccflow snapshots list' to see what can be rolled back.
+
+Examples:
+  ccflow undo       # Roll back the last command
+  ccflow undo 3     # Roll back the last three`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runUndo,
+}
+
+func runUndo(cmd *cobra.Command, args []string) {
+	n := 1
+	if len(args) == 1 {
+		var err error
+		if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+			exitWithError("invalid count '%s': expected a positive number", args[0])
+		}
+	}
+
+	store := snapshotStore()
+	restored, err := store.Undo(n)
+	reportRestored(store, restored)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+}
@@ -1,24 +1,28 @@
 package ccflow
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
-	"encoding/json"
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/spf13/cobra"
 
 	"github.com/Wameedh/ccflow/internal/blueprint"
 	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/installer"
+	"github.com/Wameedh/ccflow/internal/merge"
+	"github.com/Wameedh/ccflow/internal/settings"
+	"github.com/Wameedh/ccflow/internal/stage"
 	"github.com/Wameedh/ccflow/internal/util"
 	"github.com/Wameedh/ccflow/internal/workspace"
 )
 
 var (
-	upgradeDryRunFlag bool
+	upgradeDryRunFlag      bool
+	upgradeInteractiveFlag bool
 )
 
 var upgradeCmd = &cobra.Command{
@@ -28,15 +32,26 @@ var upgradeCmd = &cobra.Command{
 
 This command updates managed files without overwriting user modifications:
 - Files unchanged from templates are updated automatically
-- Modified files are preserved; new versions written as .new files
+- Modified files get the template changes merged in, line by line, against
+  the content they were generated from; overlapping edits are left between
+  <<<<<<< yours / >>>>>>> template conflict markers
+- Modified files with no recorded base are preserved; new versions are
+  written as .new files
 - User-created files are never touched
-
-Use --dry-run to see what would change without making modifications.`,
+- settings.json gains the permission rules, env variables, hooks and keys
+  the blueprint added since the workflow was generated; entries you added
+  or removed stay as they are
+- workflow.yaml fields added by newer ccflow versions are set to defaults
+
+Use --interactive to accept or reject each template change and pick a side
+for each conflict, and --dry-run to see what would change without making
+modifications.`,
 	Run: runUpgrade,
 }
 
 func init() {
 	upgradeCmd.Flags().BoolVar(&upgradeDryRunFlag, "dry-run", false, "show what would change without modifying files")
+	upgradeCmd.Flags().BoolVarP(&upgradeInteractiveFlag, "interactive", "i", false, "accept or reject each template change in modified files")
 }
 
 func runUpgrade(cmd *cobra.Command, args []string) {
@@ -57,66 +72,95 @@ func runUpgrade(cmd *cobra.Command, args []string) {
 		exitWithError("failed to load blueprint: %v", err)
 	}
 
+	// Changes are made in a stage and applied all at once at the end, so an
+	// upgrade that fails partway leaves the hub as it was. os.Exit skips
+	// deferred calls, so failures go through fail to remove the stage.
+	var st *stage.Stage
+	fail := func(format string, args ...interface{}) {
+		if st != nil {
+			st.Abort()
+		}
+		exitWithError(format, args...)
+	}
+	if !upgradeDryRunFlag {
+		snapshotHub(ws, "upgrade")
+		st, ws, err = stageHub(ws)
+		if err != nil {
+			exitWithError("%v", err)
+		}
+		defer st.Abort()
+	}
+
 	// Load or create managed files manifest
-	manifest := loadManifest(ws)
+	manifest := workspace.LoadManifest(ws.GetHubPath())
 
-	// Create template data
-	templateData := &blueprint.TemplateData{
-		WorkflowName:  ws.Config.Name,
-		DocsStateDir:  ws.Config.State.StateDir,
-		DocsDesignDir: ws.Config.State.DesignsDir,
-		GatesEnabled:  ws.Config.Gates.Enabled,
-		HooksEnabled:  ws.Config.Hooks.Enabled,
+	// Migrate workflow.yaml first so templates render from the current fields
+	configChanges, err := upgradeWorkflowConfig(ws, upgradeDryRunFlag)
+	if err != nil {
+		fail("upgrade failed, no files were changed: %v", err)
 	}
 
 	// Track changes
-	var updated, skipped, newFiles int
-
-	fmt.Println("Checking for updates...")
-	fmt.Println()
-
-	// Check agents
-	for _, agentName := range bp.Agents.Defaults {
-		result := checkAndUpdate(ws, bpManager, bp, "agents", agentName+".md", templateData, manifest, upgradeDryRunFlag)
-		switch result {
+	var updated, merged, skipped, newFiles, failed int
+	var conflicts []string
+	check := func(dir, filename string) string {
+		status := checkAndUpdate(ws, bpManager, bp, dir, filename, manifest, upgradeDryRunFlag)
+		switch status {
 		case "updated":
 			updated++
+		case "merged":
+			merged++
+		case "conflict":
+			merged++
+			conflicts = append(conflicts, filepath.Join(dir, filename))
 		case "skipped":
 			skipped++
 		case "new":
 			newFiles++
+		case "error":
+			failed++
+		}
+		return status
+	}
+
+	fmt.Println("Checking for updates...")
+	fmt.Println()
+
+	// Check agents. Blueprints carry no per-agent permissions, and an agent
+	// without an agent_permissions entry may write every repo, which is
+	// what ccflow run gives default agents; so new default agents get no
+	// entry, and their access is reported for the user to restrict.
+	for _, agentName := range bp.Agents.Defaults {
+		if check("agents", agentName+".md") == "new" {
+			reportNewAgentAccess(ws, agentName)
 		}
 	}
 
 	// Check commands
 	for _, cmdName := range bp.Commands.Defaults {
-		result := checkAndUpdate(ws, bpManager, bp, "commands", cmdName+".md", templateData, manifest, upgradeDryRunFlag)
-		switch result {
-		case "updated":
-			updated++
-		case "skipped":
-			skipped++
-		case "new":
-			newFiles++
-		}
+		check("commands", cmdName+".md")
 	}
 
 	// Check hooks
 	for _, hookName := range bp.Hooks.Defaults {
-		result := checkAndUpdate(ws, bpManager, bp, "hooks", hookName+".sh", templateData, manifest, upgradeDryRunFlag)
-		switch result {
-		case "updated":
-			updated++
-		case "skipped":
-			skipped++
-		case "new":
-			newFiles++
-		}
+		check("hooks", hookName+".sh")
 	}
 
-	// Save manifest if not dry run
+	// Merge new blueprint settings
+	settingsChanges, err := upgradeSettings(ws, bpManager, bp, manifest, upgradeDryRunFlag)
+	if err != nil {
+		failed++
+	}
+
+	// Save manifest and apply the changes if not dry run
 	if !upgradeDryRunFlag {
-		saveManifest(ws, manifest)
+		if failed > 0 {
+			fail("upgrade failed for %d file(s), no files were changed", failed)
+		}
+		_ = workspace.SaveManifest(ws.GetHubPath(), manifest) // Best effort
+		if err := st.Commit(); err != nil {
+			fail("upgrade failed, no files were changed: %v", err)
+		}
 	}
 
 	// Summary
@@ -124,45 +168,53 @@ func runUpgrade(cmd *cobra.Command, args []string) {
 	fmt.Println("Summary")
 	fmt.Println("-------")
 	fmt.Printf("  Updated:     %d\n", updated)
-	fmt.Printf("  Skipped:     %d (user-modified)\n", skipped)
+	fmt.Printf("  Merged:      %d (user-modified)\n", merged)
+	fmt.Printf("  Conflicts:   %d\n", len(conflicts))
+	fmt.Printf("  Skipped:     %d (user-modified, wrote .new)\n", skipped)
 	fmt.Printf("  New files:   %d\n", newFiles)
+	fmt.Printf("  Settings:    %d (settings.json entries added)\n", settingsChanges)
+	fmt.Printf("  Config:      %d (workflow.yaml fields migrated)\n", configChanges)
+
+	if len(conflicts) > 0 && !upgradeDryRunFlag {
+		fmt.Println()
+		fmt.Println("Resolve the <<<<<<< yours / >>>>>>> template markers in:")
+		for _, path := range conflicts {
+			fmt.Printf("  %s\n", path)
+		}
+	}
 
 	if upgradeDryRunFlag {
 		fmt.Println()
 		fmt.Println("This was a dry run. No files were modified.")
+		if failed > 0 {
+			exitWithError("%d file(s) could not be upgraded", failed)
+		}
+	} else if ws.Config.Install.Mode == string(installer.InstallModeCopy) && updated+merged+newFiles+settingsChanges > 0 {
+		fmt.Println()
+		fmt.Println("Repos use copied .claude directories. Run 'ccflow sync' to push these changes.")
 	}
 }
 
-func checkAndUpdate(ws *workspace.Workspace, bpManager *blueprint.Manager, bp *blueprint.Blueprint, dir, filename string, data *blueprint.TemplateData, manifest *config.ManagedFilesManifest, dryRun bool) string {
+func checkAndUpdate(ws *workspace.Workspace, bpManager *blueprint.Manager, bp *blueprint.Blueprint, dir, filename string, manifest *config.ManagedFilesManifest, dryRun bool) string {
 	hubPath := ws.GetHubPath()
 	filePath := filepath.Join(hubPath, dir, filename)
 	relPath := filepath.Join(dir, filename)
 
 	// Get new template content
-	var newContent []byte
-	var err error
-
-	switch dir {
-	case "agents":
-		name := strings.TrimSuffix(filename, ".md")
-		newContent, err = bpManager.GetAgentContent(bp.ID, name, data)
-	case "commands":
-		name := strings.TrimSuffix(filename, ".md")
-		newContent, err = bpManager.GetCommandContent(bp.ID, name, data)
-	case "hooks":
-		name := strings.TrimSuffix(filename, ".sh")
-		newContent, err = bpManager.GetHookContent(bp.ID, name, data)
-	}
-
+	newContent, err := bpManager.RenderFile(bp.ID, relPath, ws.Config)
 	if err != nil {
 		fmt.Printf("  ⚠ %s: failed to get template\n", relPath)
 		return "error"
 	}
 
-	newHash := hashContent(newContent)
+	newHash := util.HashBytes(newContent)
 
 	// Check if file exists
 	if !util.FileExists(filePath) {
+		if manifest.IsRemoved(relPath) {
+			// Removed with ccflow remove-*; don't bring it back
+			return "unchanged"
+		}
 		if dryRun {
 			fmt.Printf("  + %s (would create)\n", relPath)
 		} else {
@@ -177,25 +229,24 @@ func checkAndUpdate(ws *workspace.Workspace, bpManager *blueprint.Manager, bp *b
 				return "error"
 			}
 			fmt.Printf("  + %s (created)\n", relPath)
-			manifest.Files[relPath] = config.ManagedFileInfo{
-				TemplateID: bp.ID + "/" + relPath,
-				Hash:       newHash,
-				Version:    config.Version,
-			}
+			_ = workspace.RecordManaged(hubPath, manifest, bp.ID+"/"+relPath, relPath, newContent) // Best effort
 		}
 		return "new"
 	}
 
 	// File exists - check if managed and unchanged
 	existingContent, _ := os.ReadFile(filePath)
-	existingHash := hashContent(existingContent)
+	existingHash := util.HashBytes(existingContent)
 
 	info, isManaged := manifest.Files[relPath]
 
 	if isManaged && info.Hash == existingHash {
 		// File is managed and unchanged - safe to update
 		if info.Hash == newHash {
-			// Already up to date
+			// Already up to date; record the base if the manifest predates it
+			if _, ok := workspace.LoadBase(hubPath, relPath); !ok && !dryRun {
+				_ = workspace.SaveBase(hubPath, relPath, existingContent) // Best effort
+			}
 			return "unchanged"
 		}
 
@@ -213,16 +264,17 @@ func checkAndUpdate(ws *workspace.Workspace, bpManager *blueprint.Manager, bp *b
 				return "error"
 			}
 			fmt.Printf("  ↑ %s (updated)\n", relPath)
-			manifest.Files[relPath] = config.ManagedFileInfo{
-				TemplateID: bp.ID + "/" + relPath,
-				Hash:       newHash,
-				Version:    config.Version,
-			}
+			_ = workspace.RecordManaged(hubPath, manifest, bp.ID+"/"+relPath, relPath, newContent) // Best effort
 		}
 		return "updated"
 	}
 
-	// File is modified or not managed - write .new file
+	// File is modified - merge the template changes since its base into it
+	if base, ok := workspace.LoadBase(hubPath, relPath); ok {
+		return mergeUpdate(ws, bp, dir, relPath, base, existingContent, newContent, manifest, dryRun)
+	}
+
+	// File is modified with no base to merge against, or not managed - write .new file
 	if dryRun {
 		fmt.Printf("  ~ %s (user-modified, would write .new)\n", relPath)
 	} else {
@@ -242,36 +294,216 @@ func checkAndUpdate(ws *workspace.Workspace, bpManager *blueprint.Manager, bp *b
 	return "skipped"
 }
 
-func hashContent(content []byte) string {
-	hash := sha256.Sum256(content)
-	return hex.EncodeToString(hash[:])
-}
+// mergeUpdate three-way merges the template changes made since base into a
+// file the user modified. Conflicting edits are left between markers.
+func mergeUpdate(ws *workspace.Workspace, bp *blueprint.Blueprint, dir, relPath string, base, existing, newContent []byte, manifest *config.ManagedFilesManifest, dryRun bool) string {
+	if bytes.Equal(base, newContent) {
+		// Template unchanged since the file was generated; keep the user's edits
+		return "unchanged"
+	}
 
-func loadManifest(ws *workspace.Workspace) *config.ManagedFilesManifest {
-	manifestPath := filepath.Join(ws.GetHubPath(), ".ccflow-managed.json")
+	result := merge.Merge(base, existing, newContent)
+	if upgradeInteractiveFlag && !dryRun {
+		if err := resolveInteractively(relPath, result); err != nil {
+			fmt.Printf("  ✗ %s: %v\n", relPath, err)
+			return "error"
+		}
+	}
+	merged := result.Bytes("yours", "template")
+	conflicts := result.Conflicts()
 
-	manifest := &config.ManagedFilesManifest{
-		Version: 1,
-		Files:   make(map[string]config.ManagedFileInfo),
+	status := "merged"
+	if conflicts > 0 {
+		status = "conflict"
 	}
 
-	if util.FileExists(manifestPath) {
-		data, err := os.ReadFile(manifestPath)
-		if err == nil {
-			_ = json.Unmarshal(data, manifest) // Ignore errors, use default if invalid
+	if dryRun {
+		if conflicts > 0 {
+			fmt.Printf("  ! %s (user-modified, would merge with %d conflict(s))\n", relPath, conflicts)
+		} else {
+			fmt.Printf("  ⇄ %s (user-modified, would merge)\n", relPath)
 		}
+		return status
+	}
+
+	filePath := filepath.Join(ws.GetHubPath(), relPath)
+	var writeErr error
+	if dir == "hooks" {
+		writeErr = util.SafeWriteExecutable(filePath, merged, true)
+	} else {
+		writeErr = util.SafeWriteFile(filePath, merged, true)
+	}
+	if writeErr != nil {
+		fmt.Printf("  ✗ %s: %v\n", relPath, writeErr)
+		return "error"
 	}
 
-	return manifest
+	// The new template becomes the base; the manifest keeps its hash so the
+	// merged file still counts as user-modified
+	_ = workspace.RecordManaged(ws.GetHubPath(), manifest, bp.ID+"/"+relPath, relPath, newContent) // Best effort
+
+	if conflicts > 0 {
+		fmt.Printf("  ! %s (user-modified, merged with %d conflict(s))\n", relPath, conflicts)
+	} else {
+		fmt.Printf("  ⇄ %s (user-modified, merged)\n", relPath)
+	}
+	return status
 }
 
-func saveManifest(ws *workspace.Workspace, manifest *config.ManagedFilesManifest) {
-	manifestPath := filepath.Join(ws.GetHubPath(), ".ccflow-managed.json")
+// Choices offered for a conflict in --interactive mode
+const (
+	keepYoursChoice    = "Keep yours"
+	takeTemplateChoice = "Take the template's"
+	leaveMarkersChoice = "Leave conflict markers"
+)
+
+// resolveInteractively asks whether to apply each template change and which
+// side to keep for each conflict. The user's own changes are always kept.
+func resolveInteractively(relPath string, result *merge.Result) error {
+	for i := range result.Chunks {
+		c := &result.Chunks[i]
+		switch c.Kind {
+		case merge.Theirs:
+			fmt.Printf("\n%s: template change\n", relPath)
+			printHunkLines("-", c.Base)
+			printHunkLines("+", c.Theirs)
+			accept := true
+			prompt := &survey.Confirm{Message: "Apply this change?", Default: true}
+			if err := survey.AskOne(prompt, &accept); err != nil {
+				return fmt.Errorf("prompt failed: %w", err)
+			}
+			if !accept {
+				c.Resolution = merge.ResolveOurs
+			}
 
-	data, err := json.MarshalIndent(manifest, "", "  ")
+		case merge.Conflict:
+			fmt.Printf("\n%s: conflict\n", relPath)
+			fmt.Println("  yours:")
+			printHunkLines(" ", c.Ours)
+			fmt.Println("  template:")
+			printHunkLines(" ", c.Theirs)
+			choice := leaveMarkersChoice
+			prompt := &survey.Select{
+				Message: "Keep which version?",
+				Options: []string{keepYoursChoice, takeTemplateChoice, leaveMarkersChoice},
+				Default: leaveMarkersChoice,
+			}
+			if err := survey.AskOne(prompt, &choice); err != nil {
+				return fmt.Errorf("prompt failed: %w", err)
+			}
+			switch choice {
+			case keepYoursChoice:
+				c.Resolution = merge.ResolveOurs
+			case takeTemplateChoice:
+				c.Resolution = merge.ResolveTheirs
+			}
+		}
+	}
+	return nil
+}
+
+// printHunkLines prints the lines of a merge chunk with a diff-style prefix
+func printHunkLines(prefix string, lines []string) {
+	for _, line := range lines {
+		fmt.Printf("  %s %s\n", prefix, strings.TrimRight(line, "\n"))
+	}
+}
+
+// upgradeSettings merges what the blueprint's settings.json gained since
+// the workflow was generated into the hub's settings.json: new permission
+// rules, env variables, hook registrations and top-level keys. Entries the
+// user added or removed are kept as they are, as are hooks removed with
+// ccflow remove-hook. An error means the merged settings couldn't be
+// written; settings that can't be read or rendered are skipped with a
+// warning.
+func upgradeSettings(ws *workspace.Workspace, bpManager *blueprint.Manager, bp *blueprint.Blueprint, manifest *config.ManagedFilesManifest, dryRun bool) (int, error) {
+	next, err := bpManager.RenderHubSettings(bp, ws.Config.Hooks.Enabled, manifest)
 	if err != nil {
+		fmt.Printf("  ⚠ %s: %v\n", settings.FileName, err)
+		return 0, nil
+	}
+
+	settingsPath := filepath.Join(ws.GetHubPath(), settings.FileName)
+	s, err := settings.Load(settingsPath)
+	if err != nil {
+		fmt.Printf("  ⚠ %s: %v\n", settings.FileName, err)
+		return 0, nil
+	}
+
+	var base *settings.Settings
+	if data, ok := workspace.LoadBase(ws.GetHubPath(), settings.FileName); ok {
+		base, _ = settings.Parse(data) // Treat an unreadable base as missing
+	}
+
+	changes := s.Upgrade(base, next)
+	for _, change := range changes {
+		if dryRun {
+			fmt.Printf("  + %s: %s (would add)\n", settings.FileName, change)
+		} else {
+			fmt.Printf("  + %s: %s (added)\n", settings.FileName, change)
+		}
+	}
+	if dryRun {
+		return len(changes), nil
+	}
+
+	if len(changes) > 0 {
+		if err := s.Save(settingsPath); err != nil {
+			fmt.Printf("  ✗ %s: %v\n", settings.FileName, err)
+			return 0, err
+		}
+	}
+	if data, err := next.Marshal(); err == nil {
+		_ = workspace.SaveBase(ws.GetHubPath(), settings.FileName, data) // Best effort
+	}
+	return len(changes), nil
+}
+
+// reportNewAgentAccess reports the repo access of an agent new to the
+// workflow that has no agent_permissions entry
+func reportNewAgentAccess(ws *workspace.Workspace, agentName string) {
+	if _, ok := ws.Config.AgentPermissions[agentName]; ok || len(ws.Config.Repos) == 0 {
 		return
 	}
+	fmt.Printf("    agent_permissions.%s: not set, so it can write every repo (restrict with 'ccflow permissions set %s')\n", agentName, agentName)
+}
+
+// upgradeWorkflowConfig fills in workflow.yaml fields added since the
+// workflow was generated. An error means workflow.yaml couldn't be written.
+func upgradeWorkflowConfig(ws *workspace.Workspace, dryRun bool) (int, error) {
+	changes := config.Migrate(ws.Config)
+	for _, change := range changes {
+		if dryRun {
+			fmt.Printf("  ↑ workflow.yaml: %s (would set)\n", change)
+		} else {
+			fmt.Printf("  ↑ workflow.yaml: %s (set)\n", change)
+		}
+	}
+
+	if len(changes) > 0 && !dryRun {
+		if err := workspace.SaveConfig(ws.ConfigPath, ws.Config); err != nil {
+			fmt.Printf("  ✗ workflow.yaml: %v\n", err)
+			return 0, err
+		}
+	}
+	return len(changes), nil
+}
+
+// stageHub stages what commands that change the hub touch, like
+// snapshotHub records it, and returns a copy of the workspace rooted in the
+// stage to make the changes through
+func stageHub(ws *workspace.Workspace) (*stage.Stage, *workspace.Workspace, error) {
+	st, err := stage.New(ws.Root)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := st.Seed(ws.GetHubPath(), ws.ConfigPath, ws.GetCCFlowPath()); err != nil {
+		st.Abort()
+		return nil, nil, err
+	}
 
-	_ = os.WriteFile(manifestPath, data, 0644) // Best effort
+	staged := *ws
+	staged.Root = st.Dir
+	staged.ConfigPath = st.Path(ws.ConfigPath)
+	return st, &staged, nil
 }
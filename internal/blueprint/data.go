@@ -0,0 +1,68 @@
+package blueprint
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Wameedh/ccflow/internal/config"
+)
+
+// TemplateDataFor derives the data templates are rendered with from a
+// workflow config, so that generation, upgrade, add-* and permissions all
+// render a template identically. Agent templates get the repos the agent may
+// write and read under agent_permissions; an agent without permissions, and
+// any other template (agentName ""), can write every repo.
+func TemplateDataFor(cfg *config.WorkflowConfig, agentName string) *TemplateData {
+	data := &TemplateData{
+		WorkflowName:    cfg.Name,
+		DocsRoot:        cfg.State.Root,
+		DocsStateDir:    cfg.State.StateDir,
+		DocsDesignDir:   cfg.State.DesignsDir,
+		TrackerProvider: string(cfg.MCP.Tracker),
+		VCSProvider:     string(cfg.MCP.VCS),
+		HooksEnabled:    cfg.Hooks.Enabled,
+		GatesEnabled:    cfg.Gates.Enabled,
+	}
+
+	perm, hasPerm := cfg.AgentPermissions[agentName]
+	restricted := agentName != "" && hasPerm && (len(perm.Write) > 0 || len(perm.Read) > 0)
+
+	for _, repo := range cfg.Repos {
+		data.Repos = append(data.Repos, DefaultRepo{Name: repo.Name, Kind: string(repo.Kind)})
+
+		info := RepoInfo{
+			Name: repo.Name,
+			Path: repo.Path,
+			Kind: string(repo.Kind),
+		}
+		switch {
+		case !restricted || slices.Contains(perm.Write, repo.Name):
+			info.CanWrite = true
+			data.WriteRepos = append(data.WriteRepos, info)
+			data.AllRepos = append(data.AllRepos, info)
+		case slices.Contains(perm.Read, repo.Name):
+			data.ReadRepos = append(data.ReadRepos, info)
+			data.AllRepos = append(data.AllRepos, info)
+		}
+	}
+
+	return data
+}
+
+// RenderFile renders the template a file in the .claude directory is
+// written from, given its path relative to that directory (e.g.
+// agents/product-agent.md), with the data derived from cfg
+func (m *Manager) RenderFile(blueprintID, relPath string, cfg *config.WorkflowConfig) ([]byte, error) {
+	name := strings.TrimSuffix(filepath.Base(relPath), filepath.Ext(relPath))
+	switch filepath.Dir(relPath) {
+	case "agents":
+		return m.GetAgentContent(blueprintID, name, TemplateDataFor(cfg, name))
+	case "commands":
+		return m.GetCommandContent(blueprintID, name, TemplateDataFor(cfg, ""))
+	case "hooks":
+		return m.GetHookContent(blueprintID, name, TemplateDataFor(cfg, ""))
+	}
+	return nil, fmt.Errorf("%s is not written from a template", relPath)
+}
@@ -0,0 +1,66 @@
+package blueprint
+
+import (
+	"testing"
+
+	"github.com/Wameedh/ccflow/internal/config"
+)
+
+// repoNames returns the names of repos
+func repoNames(repos []RepoInfo) []string {
+	var names []string
+	for _, r := range repos {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+func TestTemplateDataFor(t *testing.T) {
+	cfg := config.NewDefaultWorkflowConfig("shop")
+	cfg.MCP.Tracker = config.TrackerLinear
+	cfg.Repos = []config.RepoConfig{
+		{Name: "api", Path: "api", Kind: config.RepoKindGo},
+		{Name: "web", Path: "web", Kind: config.RepoKindNode},
+		{Name: "infra", Path: "infra", Kind: config.RepoKindTerraform},
+	}
+	cfg.AgentPermissions = map[string]config.AgentPermission{
+		"backend-agent": {Write: []string{"api"}, Read: []string{"web"}},
+	}
+
+	data := TemplateDataFor(cfg, "backend-agent")
+	if data.WorkflowName != "shop" || data.DocsRoot != "docs/workflow" || data.TrackerProvider != "linear" || !data.HooksEnabled {
+		t.Errorf("Unexpected workflow fields: %+v", data)
+	}
+	if len(data.Repos) != 3 {
+		t.Errorf("Expected all 3 repos in Repos, got %v", data.Repos)
+	}
+	if got := repoNames(data.WriteRepos); len(got) != 1 || got[0] != "api" {
+		t.Errorf("Expected WriteRepos [api], got %v", got)
+	}
+	if got := repoNames(data.ReadRepos); len(got) != 1 || got[0] != "web" {
+		t.Errorf("Expected ReadRepos [web], got %v", got)
+	}
+	if got := repoNames(data.AllRepos); len(got) != 2 {
+		t.Errorf("Expected infra to be hidden from AllRepos, got %v", got)
+	}
+
+	// Agents without permissions, and non-agent templates, can write everywhere
+	for _, name := range []string{"frontend-agent", ""} {
+		data := TemplateDataFor(cfg, name)
+		if len(data.WriteRepos) != 3 || len(data.AllRepos) != 3 || len(data.ReadRepos) != 0 {
+			t.Errorf("%q: expected full access, got write %v read %v", name, repoNames(data.WriteRepos), repoNames(data.ReadRepos))
+		}
+	}
+}
+
+func TestRenderFile_NotATemplate(t *testing.T) {
+	mgr, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+
+	cfg := config.NewDefaultWorkflowConfig("shop")
+	if _, err := mgr.RenderFile("web-dev", "settings.json", cfg); err == nil {
+		t.Error("Expected an error rendering a file that has no template")
+	}
+}
@@ -0,0 +1,100 @@
+package blueprint
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/settings"
+)
+
+// HookRegistrationFor returns how a hook is registered: its hooks_manifest
+// entry, or a Stop hook running hooks/<name>.sh when the manifest has none
+func HookRegistrationFor(bp *Blueprint, hookName string) HookRegistration {
+	if bp != nil {
+		if reg, ok := bp.HooksManifest[hookName]; ok {
+			return reg
+		}
+	}
+	return HookRegistration{
+		Script: "hooks/" + hookName + ".sh",
+		Events: []HookEvent{{Event: "Stop"}},
+	}
+}
+
+// Command returns the settings.json command that runs the hook script
+func (r HookRegistration) Command() string {
+	return "./" + r.Script
+}
+
+// Register adds the hook to settings for each of its events, matching the
+// listed tools. It returns the events the hook was newly added to.
+func (r HookRegistration) Register(s *settings.Settings) []string {
+	var added []string
+	for _, event := range r.Events {
+		if s.AddHook(event.Event, strings.Join(event.Commands, "|"), r.Command()) {
+			added = append(added, event.Event)
+		}
+	}
+	return added
+}
+
+// RenderSettings returns the settings.json a workflow is generated with: the
+// blueprint's settings asset plus, when hooks are enabled, its default hooks
+func (m *Manager) RenderSettings(bp *Blueprint, hooksEnabled bool) (*settings.Settings, error) {
+	content, err := m.GetAsset(bp.ID, ".claude/settings.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings.json: %w", err)
+	}
+	s, err := settings.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid settings.json in blueprint %s: %w", bp.ID, err)
+	}
+
+	if hooksEnabled {
+		for _, hookName := range bp.Hooks.Defaults {
+			HookRegistrationFor(bp, hookName).Register(s)
+		}
+	}
+	return s, nil
+}
+
+// RenderHubSettings renders the blueprint's settings.json for a workflow,
+// leaving out the hooks removed with ccflow remove-hook
+func (m *Manager) RenderHubSettings(bp *Blueprint, hooksEnabled bool, manifest *config.ManagedFilesManifest) (*settings.Settings, error) {
+	s, err := m.RenderSettings(bp, hooksEnabled)
+	if err != nil {
+		return nil, err
+	}
+	for _, relPath := range manifest.Removed {
+		if filepath.Dir(relPath) == "hooks" {
+			hookName := strings.TrimSuffix(filepath.Base(relPath), ".sh")
+			s.RemoveHook("./hooks/" + hookName + ".sh")
+			s.RemoveHook(HookRegistrationFor(bp, hookName).Command())
+		}
+	}
+	return s, nil
+}
+
+// RegisterAddedHooks registers the hooks added from templates with ccflow
+// add-hook, which the manifest tracks but the blueprint's defaults don't
+// include. It returns the names of the hooks newly registered.
+func RegisterAddedHooks(bp *Blueprint, s *settings.Settings, manifest *config.ManagedFilesManifest) []string {
+	var added []string
+	for relPath := range manifest.Files {
+		if filepath.Dir(relPath) != "hooks" || manifest.IsRemoved(relPath) {
+			continue
+		}
+		hookName := strings.TrimSuffix(filepath.Base(relPath), ".sh")
+		if slices.Contains(bp.Hooks.Defaults, hookName) {
+			continue
+		}
+		if len(HookRegistrationFor(bp, hookName).Register(s)) > 0 {
+			added = append(added, hookName)
+		}
+	}
+	slices.Sort(added)
+	return added
+}
@@ -0,0 +1,59 @@
+package blueprint
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/settings"
+)
+
+func TestHookRegistrationFor(t *testing.T) {
+	bp := &Blueprint{
+		HooksManifest: HooksManifest{
+			"post-edit": {
+				Script: "hooks/post-edit.sh",
+				Events: []HookEvent{{Event: "PostToolUse", Commands: []string{"Write", "Edit"}}},
+			},
+		},
+	}
+
+	s := settings.New()
+	if events := HookRegistrationFor(bp, "post-edit").Register(s); len(events) != 1 || events[0] != "PostToolUse" {
+		t.Errorf("Expected PostToolUse registration, got %v", events)
+	}
+	if m := s.Hooks["PostToolUse"]; len(m) != 1 || m[0].Matcher != "Write|Edit" || m[0].Hooks[0].Command != "./hooks/post-edit.sh" {
+		t.Errorf("Unexpected registration: %+v", m)
+	}
+
+	// Registering again is a no-op
+	if events := HookRegistrationFor(bp, "post-edit").Register(s); len(events) != 0 {
+		t.Errorf("Expected no new events, got %v", events)
+	}
+
+	// Hooks missing from the manifest run on Stop
+	reg := HookRegistrationFor(nil, "notify")
+	if reg.Command() != "./hooks/notify.sh" || len(reg.Events) != 1 || reg.Events[0].Event != "Stop" {
+		t.Errorf("Unexpected default registration: %+v", reg)
+	}
+}
+
+func TestRegisterAddedHooks(t *testing.T) {
+	bp := &Blueprint{}
+	bp.Hooks.Defaults = []string{"end-of-turn"}
+	manifest := &config.ManagedFilesManifest{Files: map[string]config.ManagedFileInfo{}}
+	for _, relPath := range []string{"hooks/end-of-turn.sh", "hooks/notify.sh", "hooks/lint.sh", "agents/qa.md"} {
+		manifest.Track(filepath.FromSlash(relPath), "web-dev/"+relPath, "hash")
+	}
+	manifest.Forget(filepath.FromSlash("hooks/lint.sh"))
+
+	s := settings.New()
+	added := RegisterAddedHooks(bp, s, manifest)
+	if len(added) != 1 || added[0] != "notify" {
+		t.Errorf("Expected only notify to be registered, got %v", added)
+	}
+	refs := s.CommandHooks()
+	if len(refs) != 1 || refs[0].Command != "./hooks/notify.sh" {
+		t.Errorf("Unexpected hooks: %+v", refs)
+	}
+}
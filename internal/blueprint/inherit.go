@@ -0,0 +1,141 @@
+package blueprint
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// resolveAll applies extends to every loaded blueprint. An on-disk
+// blueprint that can't be resolved is dropped, bringing back the blueprint
+// it replaced if there was one, and recorded in the load errors. Embedded
+// blueprints must resolve.
+func (m *Manager) resolveAll() error {
+	ids := make([]string, 0, len(m.blueprints))
+	for id := range m.blueprints {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		for e := m.blueprints[id]; e != nil; e = m.blueprints[id] {
+			err := m.resolve(e, nil)
+			if err == nil {
+				break
+			}
+			if e.bp.Source == SourceEmbedded {
+				return err
+			}
+
+			m.loadErrors = append(m.loadErrors, fmt.Errorf("failed to load blueprint from %s: %w", e.bp.Source, err))
+			if e.shadowed != nil {
+				m.blueprints[id] = e.shadowed
+			} else {
+				delete(m.blueprints, id)
+			}
+		}
+	}
+	return nil
+}
+
+// resolve links a blueprint to its parent and merges the inherited
+// agents, commands, hooks and hooks_manifest into it. chain holds the
+// blueprints currently being resolved, for cycle detection.
+func (m *Manager) resolve(e *entry, chain []*entry) error {
+	if e.resolved {
+		return nil
+	}
+	if slices.Contains(chain, e) {
+		return fmt.Errorf("blueprint %s: extends cycle detected", e.bp.ID)
+	}
+
+	if e.bp.Extends == "" {
+		e.resolved = true
+		return nil
+	}
+
+	// A blueprint extending its own ID builds on the one it replaced
+	var parent *entry
+	if e.bp.Extends == e.bp.ID {
+		parent = e.shadowed
+		if parent == nil {
+			return fmt.Errorf("blueprint %s extends itself but no earlier blueprint with that ID exists", e.bp.ID)
+		}
+	} else {
+		parent = m.blueprints[e.bp.Extends]
+		if parent == nil {
+			return fmt.Errorf("blueprint %s extends unknown blueprint: %s", e.bp.ID, e.bp.Extends)
+		}
+	}
+
+	if err := m.resolve(parent, append(chain, e)); err != nil {
+		return err
+	}
+
+	e.parent = parent
+	e.bp = mergeBlueprint(parent.bp, e.bp)
+	e.resolved = true
+	return nil
+}
+
+// mergeBlueprint returns child with everything it does not declare taken from parent.
+// Lists of agents, commands and hooks are the parent's minus the child's
+// removals plus the child's additions.
+func mergeBlueprint(parent, child *Blueprint) *Blueprint {
+	merged := *child
+
+	if merged.DisplayName == "" {
+		merged.DisplayName = parent.DisplayName
+	}
+	if merged.Description == "" {
+		merged.Description = parent.Description
+	}
+	if merged.DefaultTopology == "" {
+		merged.DefaultTopology = parent.DefaultTopology
+	}
+	if len(merged.DefaultRepos) == 0 {
+		merged.DefaultRepos = parent.DefaultRepos
+	}
+
+	merged.Agents.Defaults = mergeNames(parent.Agents.Defaults, child.Agents.Defaults, child.Agents.Remove)
+	merged.Commands.Defaults = mergeNames(parent.Commands.Defaults, child.Commands.Defaults, child.Commands.Remove)
+	merged.Hooks.Defaults = mergeNames(parent.Hooks.Defaults, child.Hooks.Defaults, child.Hooks.Remove)
+
+	merged.HooksManifest = make(HooksManifest)
+	for name, reg := range parent.HooksManifest {
+		if !slices.Contains(child.Hooks.Remove, name) {
+			merged.HooksManifest[name] = reg
+		}
+	}
+	for name, reg := range child.HooksManifest {
+		merged.HooksManifest[name] = reg
+	}
+
+	if len(merged.MCPSuggestions.VCS) == 0 {
+		merged.MCPSuggestions.VCS = parent.MCPSuggestions.VCS
+	}
+	if len(merged.MCPSuggestions.Tracker) == 0 {
+		merged.MCPSuggestions.Tracker = parent.MCPSuggestions.Tracker
+	}
+	if len(merged.MCPSuggestions.Deploy) == 0 {
+		merged.MCPSuggestions.Deploy = parent.MCPSuggestions.Deploy
+	}
+
+	return &merged
+}
+
+// mergeNames returns inherited minus removed, followed by added names not already present
+func mergeNames(inherited, added, removed []string) []string {
+	var result []string
+	for _, name := range inherited {
+		if !slices.Contains(removed, name) {
+			result = append(result, name)
+		}
+	}
+	for _, name := range added {
+		if !slices.Contains(result, name) {
+			result = append(result, name)
+		}
+	}
+	return result
+}
@@ -0,0 +1,240 @@
+package blueprint
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes a map of relative paths to content under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// setupBlueprintPath points CCFLOW_BLUEPRINT_PATH at a fresh directory
+func setupBlueprintPath(t *testing.T) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	t.Setenv(EnvBlueprintPath, dir)
+	return dir
+}
+
+func TestExtends_MergesParent(t *testing.T) {
+	dir := setupBlueprintPath(t)
+
+	writeFiles(t, filepath.Join(dir, "base-dev"), map[string]string{
+		"blueprint.yaml": `id: base-dev
+display_name: Base
+description: Shared base
+default_topology: single-repo
+agents:
+  defaults: [product-agent, review-agent]
+commands:
+  defaults: [idea, design]
+hooks:
+  defaults: [post-edit, end-of-turn]
+hooks_manifest:
+  post-edit:
+    script: hooks/post-edit.sh
+    events:
+      - event: PostToolUse
+  end-of-turn:
+    script: hooks/end-of-turn.sh
+    events:
+      - event: Stop
+`,
+		"assets/.claude/agents/product-agent.md": "base product",
+		"assets/.claude/agents/review-agent.md":  "base review",
+		"assets/.claude/commands/idea.md":        "base idea",
+		"assets/.claude/commands/design.md":      "base design",
+		"templates/state.schema.json":            "{}",
+	})
+
+	writeFiles(t, filepath.Join(dir, "child-dev"), map[string]string{
+		"blueprint.yaml": `id: child-dev
+extends: base-dev
+display_name: Child
+agents:
+  defaults: [rust-subagent]
+  remove: [review-agent]
+hooks:
+  remove: [end-of-turn]
+`,
+		"assets/.claude/agents/product-agent.md": "child product",
+		"assets/.claude/agents/rust-subagent.md": "child rust",
+	})
+
+	mgr, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+
+	child, err := mgr.Get("child-dev")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	if child.DisplayName != "Child" || child.Description != "Shared base" || child.DefaultTopology != "single-repo" {
+		t.Errorf("Unexpected scalar inheritance: %+v", child)
+	}
+	if !slices.Equal(child.Agents.Defaults, []string{"product-agent", "rust-subagent"}) {
+		t.Errorf("Unexpected agents: %v", child.Agents.Defaults)
+	}
+	if !slices.Equal(child.Commands.Defaults, []string{"idea", "design"}) {
+		t.Errorf("Unexpected commands: %v", child.Commands.Defaults)
+	}
+	if !slices.Equal(child.Hooks.Defaults, []string{"post-edit"}) {
+		t.Errorf("Unexpected hooks: %v", child.Hooks.Defaults)
+	}
+	if _, ok := child.HooksManifest["end-of-turn"]; ok {
+		t.Error("Removed hook should be dropped from hooks_manifest")
+	}
+	if _, ok := child.HooksManifest["post-edit"]; !ok {
+		t.Error("Expected post-edit to be inherited in hooks_manifest")
+	}
+
+	// Assets resolve through the chain, child first
+	assets := map[string]string{
+		".claude/agents/product-agent.md": "child product",
+		".claude/agents/rust-subagent.md": "child rust",
+		".claude/commands/design.md":      "base design",
+	}
+	for assetPath, want := range assets {
+		got, err := mgr.GetAsset("child-dev", assetPath)
+		if err != nil {
+			t.Errorf("GetAsset(%s) failed: %v", assetPath, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("GetAsset(%s) = %q, want %q", assetPath, got, want)
+		}
+	}
+
+	if _, err := mgr.GetTemplate("child-dev", "state.schema.json"); err != nil {
+		t.Errorf("Expected template to be inherited: %v", err)
+	}
+
+	listed, err := mgr.ListAssets("child-dev", ".claude/agents")
+	if err != nil {
+		t.Fatalf("ListAssets failed: %v", err)
+	}
+	if len(listed) != 3 {
+		t.Errorf("Expected 3 agent assets across the chain, got %v", listed)
+	}
+
+	// Parent is unchanged
+	base, _ := mgr.Get("base-dev")
+	if !slices.Equal(base.Agents.Defaults, []string{"product-agent", "review-agent"}) {
+		t.Errorf("Parent agents were modified: %v", base.Agents.Defaults)
+	}
+}
+
+func TestExtends_SameIDBuildsOnShadowed(t *testing.T) {
+	dir := setupBlueprintPath(t)
+
+	writeFiles(t, filepath.Join(dir, "web-dev"), map[string]string{
+		"blueprint.yaml": `id: web-dev
+extends: web-dev
+agents:
+  defaults: [team-agent]
+`,
+	})
+
+	mgr, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+
+	webDev, err := mgr.Get("web-dev")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if webDev.DisplayName != "Web Development" {
+		t.Errorf("Expected embedded display name to be inherited, got %s", webDev.DisplayName)
+	}
+	if !slices.Contains(webDev.Agents.Defaults, "team-agent") || !slices.Contains(webDev.Agents.Defaults, "product-agent") {
+		t.Errorf("Expected embedded agents plus team-agent, got %v", webDev.Agents.Defaults)
+	}
+}
+
+func TestExtends_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name: "unknown parent",
+			files: map[string]string{
+				"a/blueprint.yaml": "id: a\nextends: missing\n",
+			},
+			want: "unknown blueprint: missing",
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"a/blueprint.yaml": "id: a\nextends: b\n",
+				"b/blueprint.yaml": "id: b\nextends: a\n",
+			},
+			want: "cycle",
+		},
+		{
+			name: "self without shadowed",
+			files: map[string]string{
+				"a/blueprint.yaml": "id: a\nextends: a\n",
+			},
+			want: "extends itself",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupBlueprintPath(t)
+			writeFiles(t, dir, tt.files)
+
+			// The broken blueprint is skipped, and the error recorded
+			mgr, err := NewManager()
+			if err != nil {
+				t.Fatalf("NewManager failed: %v", err)
+			}
+			if _, err := mgr.Get("a"); err == nil {
+				t.Error("Expected blueprint a to be skipped")
+			}
+			errs := mgr.LoadErrors()
+			if len(errs) == 0 || !strings.Contains(errs[0].Error(), tt.want) {
+				t.Errorf("Expected a load error containing %q, got %v", tt.want, errs)
+			}
+		})
+	}
+}
+
+func TestExtends_ErrorRestoresShadowed(t *testing.T) {
+	dir := setupBlueprintPath(t)
+	writeFiles(t, dir, map[string]string{
+		"web-dev/blueprint.yaml": "id: web-dev\nextends: missing\n",
+	})
+
+	mgr, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	bp, err := mgr.Get("web-dev")
+	if err != nil || bp.Source != SourceEmbedded {
+		t.Errorf("Expected the embedded web-dev to stay available, got %v, %v", bp, err)
+	}
+	if len(mgr.LoadErrors()) != 1 {
+		t.Errorf("Expected one load error, got %v", mgr.LoadErrors())
+	}
+}
@@ -0,0 +1,339 @@
+package blueprint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/Wameedh/ccflow/internal/util"
+)
+
+// LintIssue describes a single integrity problem in a blueprint
+type LintIssue struct {
+	Severity string // "error" or "warn"
+	Asset    string // asset path relative to assets/, or blueprint.yaml
+	Message  string
+}
+
+// String formats the issue for display
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s: %s", i.Asset, i.Message)
+}
+
+// Lint returns the integrity issues found when the blueprint was loaded
+func (m *Manager) Lint(blueprintID string) ([]LintIssue, error) {
+	e, ok := m.blueprints[blueprintID]
+	if !ok {
+		return nil, fmt.Errorf("blueprint not found: %s", blueprintID)
+	}
+	m.lintEntry(e)
+	return e.issues, nil
+}
+
+// lintEntry lints a loaded blueprint once and caches the result
+func (m *Manager) lintEntry(e *entry) {
+	if !e.linted {
+		e.issues = m.lint(e.bp)
+		e.linted = true
+	}
+}
+
+// Verify returns an error listing every error-severity lint issue, so callers
+// can refuse to use a broken blueprint before writing any files
+func (m *Manager) Verify(blueprintID string) error {
+	issues, err := m.Lint(blueprintID)
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	for _, issue := range issues {
+		if issue.Severity == "error" {
+			lines = append(lines, "  - "+issue.String())
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("blueprint %s failed integrity checks (run 'ccflow blueprint lint'):\n%s", blueprintID, strings.Join(lines, "\n"))
+}
+
+// LintDir lints the blueprint in dir, resolving extends against the
+// available blueprints. A blueprint.yaml that doesn't parse and extends that
+// can't be resolved are lint issues, not errors: they are what keeps the
+// blueprint from loading, and lint is where they get diagnosed. The
+// blueprint is returned as far as it could be loaded.
+func LintDir(dir string) (*Blueprint, []LintIssue, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve blueprint directory %s: %w", dir, err)
+	}
+	if !util.FileExists(filepath.Join(absDir, "blueprint.yaml")) {
+		return nil, nil, fmt.Errorf("no blueprint.yaml found in %s", absDir)
+	}
+	loadIssue := func(err error) []LintIssue {
+		return []LintIssue{{Severity: "error", Asset: "blueprint.yaml", Message: err.Error()}}
+	}
+
+	// Parse before loading the other blueprints, which extends needs
+	fsys := os.DirFS(absDir)
+	if _, err := loadBlueprint(fsys, "."); err != nil {
+		// Drop the "failed to parse blueprint.yaml" the issue already says
+		return &Blueprint{ID: filepath.Base(absDir), Source: absDir}, loadIssue(errors.Unwrap(err)), nil
+	}
+
+	m, err := NewManager()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize blueprints: %w", err)
+	}
+	e, err := m.load(fsys, ".", filepath.Base(absDir), absDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := m.resolve(e, nil); err != nil {
+		return e.bp, loadIssue(err), nil
+	}
+
+	m.lintEntry(e)
+	return e.bp, e.issues, nil
+}
+
+// lint checks that every declared artifact has an asset that parses and only
+// references TemplateData fields, and that hooks_manifest agrees with hook defaults
+func (m *Manager) lint(bp *Blueprint) []LintIssue {
+	var issues []LintIssue
+	addError := func(asset, format string, args ...interface{}) {
+		issues = append(issues, LintIssue{Severity: "error", Asset: asset, Message: fmt.Sprintf(format, args...)})
+	}
+	addWarning := func(asset, format string, args ...interface{}) {
+		issues = append(issues, LintIssue{Severity: "warn", Asset: asset, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Declared artifacts must have a renderable asset
+	artifacts := []struct {
+		kind  string
+		dir   string
+		ext   string
+		names []string
+	}{
+		{"agent", "agents", ".md", bp.Agents.Defaults},
+		{"command", "commands", ".md", bp.Commands.Defaults},
+		{"hook", "hooks", ".sh", bp.Hooks.Defaults},
+	}
+	for _, a := range artifacts {
+		for _, name := range a.names {
+			assetPath := path.Join(".claude", a.dir, name+a.ext)
+			content, err := m.GetAsset(bp.ID, assetPath)
+			if err != nil {
+				addError(assetPath, "missing asset for %s %s", a.kind, name)
+				continue
+			}
+
+			unknown, err := unknownTemplateFields(string(content))
+			if err != nil {
+				addError(assetPath, "template does not parse: %v", err)
+				continue
+			}
+			for _, field := range unknown {
+				addError(assetPath, "unknown template field %s", field)
+			}
+		}
+	}
+
+	// settings.json must exist and be valid JSON
+	settingsPath := ".claude/settings.json"
+	if settings, err := m.GetAsset(bp.ID, settingsPath); err != nil {
+		addError(settingsPath, "missing settings.json")
+	} else if !json.Valid(settings) {
+		addError(settingsPath, "settings.json is not valid JSON")
+	}
+
+	// hooks_manifest must agree with hook defaults
+	names := make([]string, 0, len(bp.HooksManifest))
+	for name := range bp.HooksManifest {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		reg := bp.HooksManifest[name]
+		expected := "hooks/" + name + ".sh"
+		if !slices.Contains(bp.Hooks.Defaults, name) {
+			addError("blueprint.yaml", "hooks_manifest entry %s has no matching hook default", name)
+		}
+		if reg.Script != expected {
+			addError("blueprint.yaml", "hooks_manifest script for %s is %q (expected %q)", name, reg.Script, expected)
+		}
+		if reg.Script != "" {
+			if _, err := m.GetAsset(bp.ID, path.Join(".claude", reg.Script)); err != nil {
+				addError("blueprint.yaml", "hooks_manifest script for %s does not exist: %s", name, reg.Script)
+			}
+		}
+		if len(reg.Events) == 0 {
+			addWarning("blueprint.yaml", "hooks_manifest entry %s has no events", name)
+		}
+	}
+	for _, name := range bp.Hooks.Defaults {
+		if _, ok := bp.HooksManifest[name]; !ok {
+			addWarning("blueprint.yaml", "hook %s has no hooks_manifest entry (it will be registered on Stop)", name)
+		}
+	}
+
+	return issues
+}
+
+// unknownTemplateFields parses a template and returns every field reference
+// that does not exist on the data it would be rendered with
+func unknownTemplateFields(content string) ([]string, error) {
+	tmpl, err := template.New("asset").Parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	root := reflect.TypeOf(TemplateData{})
+	w := &fieldWalker{root: root, seen: make(map[string]bool)}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			w.walk(t.Tree.Root, root)
+		}
+	}
+	return w.unknown, nil
+}
+
+// fieldWalker walks a template parse tree tracking the type of dot
+type fieldWalker struct {
+	root    reflect.Type
+	unknown []string
+	seen    map[string]bool
+}
+
+// walk visits a node; dot is nil when its type cannot be determined
+func (w *fieldWalker) walk(node parse.Node, dot reflect.Type) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			w.walk(child, dot)
+		}
+	case *parse.ActionNode:
+		w.walkPipe(n.Pipe, dot)
+	case *parse.IfNode:
+		w.walkPipe(n.Pipe, dot)
+		w.walk(n.List, dot)
+		w.walk(n.ElseList, dot)
+	case *parse.RangeNode:
+		w.walkPipe(n.Pipe, dot)
+		w.walk(n.List, elemType(w.pipeType(n.Pipe, dot)))
+		w.walk(n.ElseList, dot)
+	case *parse.WithNode:
+		w.walkPipe(n.Pipe, dot)
+		w.walk(n.List, w.pipeType(n.Pipe, dot))
+		w.walk(n.ElseList, dot)
+	case *parse.TemplateNode:
+		w.walkPipe(n.Pipe, dot)
+	}
+}
+
+// walkPipe checks every field referenced in a pipeline
+func (w *fieldWalker) walkPipe(pipe *parse.PipeNode, dot reflect.Type) {
+	if pipe == nil {
+		return
+	}
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			w.argType(arg, dot)
+		}
+	}
+}
+
+// pipeType returns the type a single-argument pipeline evaluates to
+func (w *fieldWalker) pipeType(pipe *parse.PipeNode, dot reflect.Type) reflect.Type {
+	if pipe == nil || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return nil
+	}
+	return w.argType(pipe.Cmds[0].Args[0], dot)
+}
+
+// argType checks fields in an argument and returns its type when known
+func (w *fieldWalker) argType(arg parse.Node, dot reflect.Type) reflect.Type {
+	switch n := arg.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return w.lookup(dot, n.Ident)
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			return w.lookup(w.root, n.Ident[1:])
+		}
+	case *parse.ChainNode:
+		if base := w.argType(n.Node, dot); base != nil {
+			return w.lookup(base, n.Field)
+		}
+	case *parse.PipeNode:
+		w.walkPipe(n, dot)
+		return w.pipeType(n, dot)
+	}
+	return nil
+}
+
+// lookup resolves a field chain on t, recording the first unknown field
+func (w *fieldWalker) lookup(t reflect.Type, idents []string) reflect.Type {
+	for _, ident := range idents {
+		if t == nil {
+			return nil
+		}
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		switch t.Kind() {
+		case reflect.Struct:
+			if field, ok := t.FieldByName(ident); ok {
+				t = field.Type
+				continue
+			}
+			if method, ok := reflect.PointerTo(t).MethodByName(ident); ok {
+				t = nil
+				if method.Type.NumOut() > 0 {
+					t = method.Type.Out(0)
+				}
+				continue
+			}
+			name := "." + ident
+			if !w.seen[name] {
+				w.seen[name] = true
+				w.unknown = append(w.unknown, name)
+			}
+			return nil
+		case reflect.Map:
+			t = t.Elem()
+		default:
+			return nil
+		}
+	}
+	return t
+}
+
+// elemType returns the element type ranged over for slices, arrays and maps
+func elemType(t reflect.Type) reflect.Type {
+	if t == nil {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return t.Elem()
+	}
+	return nil
+}
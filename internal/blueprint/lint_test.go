@@ -0,0 +1,165 @@
+package blueprint
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// validLintBlueprint is a complete blueprint that should lint cleanly
+var validLintBlueprint = map[string]string{
+	"blueprint.yaml": `id: lint-dev
+agents:
+  defaults: [product-agent]
+commands:
+  defaults: [idea]
+hooks:
+  defaults: [post-edit]
+hooks_manifest:
+  post-edit:
+    script: hooks/post-edit.sh
+    events:
+      - event: PostToolUse
+`,
+	"assets/.claude/agents/product-agent.md": `# {{.WorkflowName}}
+{{range .AllRepos}}- {{.Name}} ({{.Kind}}){{if .CanWrite}} writable{{end}}
+{{end}}{{with .DocsRoot}}{{.}}{{end}}{{$.DocsStateDir}}`,
+	"assets/.claude/commands/idea.md":   "Save to {{.DocsStateDir}}",
+	"assets/.claude/hooks/post-edit.sh": "#!/bin/bash\nexit 0\n",
+	"assets/.claude/settings.json":      `{"hooks": {}}`,
+}
+
+func TestLint_ValidBlueprint(t *testing.T) {
+	dir := setupBlueprintPath(t)
+	writeFiles(t, filepath.Join(dir, "lint-dev"), validLintBlueprint)
+
+	mgr, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+
+	issues, err := mgr.Lint("lint-dev")
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("Expected no issues, got %v", issues)
+	}
+	if err := mgr.Verify("lint-dev"); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+}
+
+func TestLint_ReportsProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes map[string]string
+		want    string
+	}{
+		{
+			name:    "missing agent asset",
+			changes: map[string]string{"blueprint.yaml": strings.Replace(validLintBlueprint["blueprint.yaml"], "[product-agent]", "[product-agent, ghost-agent]", 1)},
+			want:    "missing asset for agent ghost-agent",
+		},
+		{
+			name:    "unparsable template",
+			changes: map[string]string{"assets/.claude/commands/idea.md": "{{if .HooksEnabled}}"},
+			want:    "template does not parse",
+		},
+		{
+			name:    "unknown top-level field",
+			changes: map[string]string{"assets/.claude/commands/idea.md": "Version {{.Version}}"},
+			want:    "unknown template field .Version",
+		},
+		{
+			name:    "unknown field inside range",
+			changes: map[string]string{"assets/.claude/commands/idea.md": "{{range .WriteRepos}}{{.Branch}}{{end}}"},
+			want:    "unknown template field .Branch",
+		},
+		{
+			name:    "invalid settings.json",
+			changes: map[string]string{"assets/.claude/settings.json": "{"},
+			want:    "settings.json is not valid JSON",
+		},
+		{
+			name:    "manifest script mismatch",
+			changes: map[string]string{"blueprint.yaml": strings.Replace(validLintBlueprint["blueprint.yaml"], "script: hooks/post-edit.sh", "script: hooks/format.sh", 1)},
+			want:    `hooks_manifest script for post-edit is "hooks/format.sh"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupBlueprintPath(t)
+			bpDir := filepath.Join(dir, "lint-dev")
+			writeFiles(t, bpDir, validLintBlueprint)
+			writeFiles(t, bpDir, tt.changes)
+
+			mgr, err := NewManager()
+			if err != nil {
+				t.Fatalf("NewManager failed: %v", err)
+			}
+
+			err = mgr.Verify("lint-dev")
+			if err == nil {
+				t.Fatal("Expected Verify to fail")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLintDir(t *testing.T) {
+	setupBlueprintPath(t)
+	dir := filepath.Join(t.TempDir(), "lint-dev")
+	writeFiles(t, dir, validLintBlueprint)
+
+	bp, issues, err := LintDir(dir)
+	if err != nil {
+		t.Fatalf("LintDir failed: %v", err)
+	}
+	if bp.ID != "lint-dev" || bp.Source != dir {
+		t.Errorf("Unexpected blueprint: id=%s source=%s", bp.ID, bp.Source)
+	}
+	if len(issues) != 0 {
+		t.Errorf("Expected no issues, got %v", issues)
+	}
+
+	if _, _, err := LintDir(t.TempDir()); err == nil {
+		t.Error("Expected LintDir to fail without blueprint.yaml")
+	}
+}
+
+func TestLintDir_LoadProblems(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     string
+	}{
+		{"unparsable", "id: [oops\n", "yaml: line 1"},
+		{"unknown parent", "id: lint-dev\nextends: missing\n", "extends unknown blueprint: missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The broken blueprint is also on the search path, as when it
+			// keeps ccflow from loading it
+			path := setupBlueprintPath(t)
+			dir := filepath.Join(path, "lint-dev")
+			writeFiles(t, dir, map[string]string{"blueprint.yaml": tt.manifest})
+
+			bp, issues, err := LintDir(dir)
+			if err != nil {
+				t.Fatalf("LintDir failed: %v", err)
+			}
+			if bp.ID != "lint-dev" {
+				t.Errorf("Expected blueprint lint-dev, got %s", bp.ID)
+			}
+			if len(issues) != 1 || issues[0].Severity != "error" || issues[0].Asset != "blueprint.yaml" || !strings.Contains(issues[0].Message, tt.want) {
+				t.Errorf("Expected one blueprint.yaml error containing %q, got %v", tt.want, issues)
+			}
+		})
+	}
+}
@@ -4,25 +4,60 @@ import (
 	"embed"
 	"fmt"
 	"io/fs"
+	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/Wameedh/ccflow/internal/util"
 )
 
 //go:embed all:web-dev all:ios-dev all:go-cli-dev all:python-data-dev all:devops-infra
 var blueprintsFS embed.FS
 
-// Manager provides access to embedded blueprints
+const (
+	// EnvBlueprintPath is the environment variable listing extra blueprint directories
+	EnvBlueprintPath = "CCFLOW_BLUEPRINT_PATH"
+	// UserBlueprintDir is the per-user blueprint directory, relative to the home directory
+	UserBlueprintDir = ".ccflow/blueprints"
+	// SourceEmbedded is the Source of blueprints compiled into the binary
+	SourceEmbedded = "embedded"
+)
+
+// Manager provides access to embedded and on-disk blueprints
 type Manager struct {
-	blueprints map[string]*Blueprint
+	blueprints map[string]*entry
+	loadErrors []error // on-disk blueprints that were skipped
 }
 
-// NewManager creates a new blueprint manager
+// entry is a loaded blueprint together with the filesystem it was read from
+type entry struct {
+	bp   *Blueprint
+	fsys fs.FS
+	root string // blueprint directory within fsys
+
+	parent   *entry // blueprint named by extends, once resolved
+	shadowed *entry // earlier blueprint with the same ID that this one replaced
+	resolved bool
+
+	issues []LintIssue // integrity issues found at load time
+	linted bool
+}
+
+// NewManager creates a new blueprint manager.
+// Blueprints are loaded from the embedded filesystem first, then from
+// ~/.ccflow/blueprints, then from each directory in CCFLOW_BLUEPRINT_PATH.
+// A blueprint with the same ID as an earlier one replaces it; within
+// CCFLOW_BLUEPRINT_PATH the first directory wins, as with PATH.
+// An on-disk blueprint that fails to parse or to resolve its extends is
+// skipped and reported by LoadErrors; only broken embedded blueprints fail.
 func NewManager() (*Manager, error) {
 	m := &Manager{
-		blueprints: make(map[string]*Blueprint),
+		blueprints: make(map[string]*entry),
 	}
 
 	// Load all embedded blueprints
@@ -31,25 +66,117 @@ func NewManager() (*Manager, error) {
 		return nil, fmt.Errorf("failed to read blueprints directory: %w", err)
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() {
+	for _, e := range entries {
+		if !e.IsDir() {
 			continue
 		}
 
-		bp, err := m.loadBlueprint(entry.Name())
-		if err != nil {
-			return nil, fmt.Errorf("failed to load blueprint %s: %w", entry.Name(), err)
+		if _, err := m.load(blueprintsFS, e.Name(), e.Name(), SourceEmbedded); err != nil {
+			return nil, fmt.Errorf("failed to load blueprint %s: %w", e.Name(), err)
 		}
-		m.blueprints[bp.ID] = bp
+	}
+
+	// Load on-disk blueprints
+	for _, dir := range searchDirs() {
+		m.loadDirectory(dir)
+	}
+
+	// Apply extends now that every parent is available
+	if err := m.resolveAll(); err != nil {
+		return nil, err
+	}
+
+	// Verify integrity up front so broken blueprints are reported before use
+	for _, e := range m.blueprints {
+		m.lintEntry(e)
 	}
 
 	return m, nil
 }
 
-// loadBlueprint loads a blueprint from the embedded filesystem
-func (m *Manager) loadBlueprint(name string) (*Blueprint, error) {
-	manifestPath := filepath.Join(name, "blueprint.yaml")
-	data, err := blueprintsFS.ReadFile(manifestPath)
+// searchDirs returns the on-disk blueprint directories in load order
+func searchDirs() []string {
+	var dirs []string
+
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, filepath.FromSlash(UserBlueprintDir)))
+	}
+
+	// Reverse so that earlier entries are loaded last and take precedence
+	paths := filepath.SplitList(os.Getenv(EnvBlueprintPath))
+	for i := len(paths) - 1; i >= 0; i-- {
+		if paths[i] != "" {
+			dirs = append(dirs, paths[i])
+		}
+	}
+
+	return dirs
+}
+
+// loadDirectory loads blueprints from a directory on disk. The directory may
+// itself be a blueprint (contains blueprint.yaml) or contain blueprint
+// subdirectories. Missing directories are ignored; blueprints and
+// directories that can't be read are recorded in the load errors.
+func (m *Manager) loadDirectory(dir string) {
+	if !util.DirExists(dir) {
+		return
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		m.loadErrors = append(m.loadErrors, fmt.Errorf("failed to resolve blueprint directory %s: %w", dir, err))
+		return
+	}
+
+	if util.FileExists(filepath.Join(absDir, "blueprint.yaml")) {
+		if _, err := m.load(os.DirFS(absDir), ".", filepath.Base(absDir), absDir); err != nil {
+			m.loadErrors = append(m.loadErrors, fmt.Errorf("failed to load blueprint from %s: %w", absDir, err))
+		}
+		return
+	}
+
+	entries, err := os.ReadDir(absDir)
+	if err != nil {
+		m.loadErrors = append(m.loadErrors, fmt.Errorf("failed to read blueprint directory %s: %w", absDir, err))
+		return
+	}
+
+	for _, e := range entries {
+		bpDir := filepath.Join(absDir, e.Name())
+		if !e.IsDir() || !util.FileExists(filepath.Join(bpDir, "blueprint.yaml")) {
+			continue
+		}
+		if _, err := m.load(os.DirFS(bpDir), ".", e.Name(), bpDir); err != nil {
+			m.loadErrors = append(m.loadErrors, fmt.Errorf("failed to load blueprint from %s: %w", bpDir, err))
+		}
+	}
+}
+
+// LoadErrors returns why on-disk blueprints were skipped, in load order
+func (m *Manager) LoadErrors() []error {
+	return m.loadErrors
+}
+
+// load parses a blueprint and registers it, replacing any blueprint with the same ID
+func (m *Manager) load(fsys fs.FS, root, defaultID, source string) (*entry, error) {
+	bp, err := loadBlueprint(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+
+	if bp.ID == "" {
+		bp.ID = defaultID
+	}
+	bp.Source = source
+
+	e := &entry{bp: bp, fsys: fsys, root: root, shadowed: m.blueprints[bp.ID]}
+	m.blueprints[bp.ID] = e
+	return e, nil
+}
+
+// loadBlueprint parses blueprint.yaml from a blueprint directory
+func loadBlueprint(fsys fs.FS, root string) (*Blueprint, error) {
+	data, err := fs.ReadFile(fsys, path.Join(root, "blueprint.yaml"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read blueprint.yaml: %w", err)
 	}
@@ -62,34 +189,56 @@ func (m *Manager) loadBlueprint(name string) (*Blueprint, error) {
 	return &bp, nil
 }
 
-// List returns all available blueprints
+// List returns all available blueprints, sorted by ID
 func (m *Manager) List() []*Blueprint {
 	var result []*Blueprint
-	for _, bp := range m.blueprints {
-		result = append(result, bp)
+	for _, e := range m.blueprints {
+		result = append(result, e.bp)
 	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
 	return result
 }
 
 // Get returns a blueprint by ID
 func (m *Manager) Get(id string) (*Blueprint, error) {
-	bp, ok := m.blueprints[id]
+	e, ok := m.blueprints[id]
 	if !ok {
 		return nil, fmt.Errorf("blueprint not found: %s", id)
 	}
-	return bp, nil
+	return e.bp, nil
 }
 
 // GetAsset retrieves an asset file from a blueprint
 func (m *Manager) GetAsset(blueprintID, assetPath string) ([]byte, error) {
-	fullPath := filepath.Join(blueprintID, "assets", assetPath)
-	return blueprintsFS.ReadFile(fullPath)
+	return m.readFile(blueprintID, "assets", assetPath)
 }
 
 // GetTemplate retrieves a template file from a blueprint
 func (m *Manager) GetTemplate(blueprintID, templatePath string) ([]byte, error) {
-	fullPath := filepath.Join(blueprintID, "templates", templatePath)
-	return blueprintsFS.ReadFile(fullPath)
+	return m.readFile(blueprintID, "templates", templatePath)
+}
+
+// readFile reads a file from a subdirectory of a blueprint, falling back to
+// the blueprints it extends
+func (m *Manager) readFile(blueprintID, subdir, filePath string) ([]byte, error) {
+	e, ok := m.blueprints[blueprintID]
+	if !ok {
+		return nil, fmt.Errorf("blueprint not found: %s", blueprintID)
+	}
+
+	var firstErr error
+	for ; e != nil; e = e.parent {
+		data, err := fs.ReadFile(e.fsys, path.Join(e.root, subdir, filepath.ToSlash(filePath)))
+		if err == nil {
+			return data, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
 }
 
 // RenderAsset renders an asset template with the given data
@@ -123,26 +272,48 @@ func (m *Manager) renderTemplate(content string, data *TemplateData) ([]byte, er
 	return []byte(buf.String()), nil
 }
 
-// ListAssets lists all assets for a blueprint in a given subdirectory
+// ListAssets lists all assets for a blueprint in a given subdirectory,
+// including assets inherited from the blueprints it extends
 func (m *Manager) ListAssets(blueprintID, subdir string) ([]string, error) {
-	basePath := filepath.Join(blueprintID, "assets", subdir)
+	e, ok := m.blueprints[blueprintID]
+	if !ok {
+		return nil, fmt.Errorf("blueprint not found: %s", blueprintID)
+	}
+
 	var assets []string
+	seen := make(map[string]bool)
+	found := false
 
-	err := fs.WalkDir(blueprintsFS, basePath, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
+	for ; e != nil; e = e.parent {
+		assetsRoot := path.Join(e.root, "assets")
+		basePath := path.Join(assetsRoot, filepath.ToSlash(subdir))
+		if _, err := fs.Stat(e.fsys, basePath); err != nil {
+			continue
 		}
-		if d.IsDir() {
+		found = true
+
+		err := fs.WalkDir(e.fsys, basePath, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			// Get relative path from assets directory
+			relPath := filepath.FromSlash(strings.TrimPrefix(p, assetsRoot+"/"))
+			if !seen[relPath] {
+				seen[relPath] = true
+				assets = append(assets, relPath)
+			}
 			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
-		// Get relative path from assets directory
-		relPath, _ := filepath.Rel(filepath.Join(blueprintID, "assets"), path)
-		assets = append(assets, relPath)
-		return nil
-	})
+	}
 
-	if err != nil {
-		return nil, err
+	if !found {
+		return nil, fmt.Errorf("asset directory not found: %s", subdir)
 	}
 
 	return assets, nil
@@ -150,22 +321,19 @@ func (m *Manager) ListAssets(blueprintID, subdir string) ([]string, error) {
 
 // HasAgent checks if a blueprint has a built-in agent template
 func (m *Manager) HasAgent(blueprintID, agentName string) bool {
-	path := filepath.Join(blueprintID, "assets", ".claude", "agents", agentName+".md")
-	_, err := blueprintsFS.ReadFile(path)
+	_, err := m.GetAsset(blueprintID, path.Join(".claude", "agents", agentName+".md"))
 	return err == nil
 }
 
 // HasCommand checks if a blueprint has a built-in command template
 func (m *Manager) HasCommand(blueprintID, commandName string) bool {
-	path := filepath.Join(blueprintID, "assets", ".claude", "commands", commandName+".md")
-	_, err := blueprintsFS.ReadFile(path)
+	_, err := m.GetAsset(blueprintID, path.Join(".claude", "commands", commandName+".md"))
 	return err == nil
 }
 
 // HasHook checks if a blueprint has a built-in hook template
 func (m *Manager) HasHook(blueprintID, hookName string) bool {
-	path := filepath.Join(blueprintID, "assets", ".claude", "hooks", hookName+".sh")
-	_, err := blueprintsFS.ReadFile(path)
+	_, err := m.GetAsset(blueprintID, path.Join(".claude", "hooks", hookName+".sh"))
 	return err == nil
 }
 
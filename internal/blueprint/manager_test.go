@@ -1,6 +1,9 @@
 package blueprint
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -98,3 +101,117 @@ func TestListBlueprints(t *testing.T) {
 		t.Error("Expected to find ios-dev blueprint")
 	}
 }
+
+// writeDiskBlueprint creates a minimal on-disk blueprint with a single agent
+func writeDiskBlueprint(t *testing.T, dir, id, displayName string) {
+	t.Helper()
+
+	manifest := "id: " + id + "\ndisplay_name: " + displayName + "\nagents:\n  defaults:\n    - custom-agent\n"
+	if err := os.MkdirAll(filepath.Join(dir, "assets", ".claude", "agents"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "blueprint.yaml"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	agent := "# Custom agent for {{.WorkflowName}}\n"
+	if err := os.WriteFile(filepath.Join(dir, "assets", ".claude", "agents", "custom-agent.md"), []byte(agent), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewManager_DiskBlueprints(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	// Home directory adds a new blueprint
+	teamDir := filepath.Join(home, ".ccflow", "blueprints", "team-dev")
+	writeDiskBlueprint(t, teamDir, "team-dev", "Team Development")
+
+	// CCFLOW_BLUEPRINT_PATH overrides an embedded blueprint; first entry wins
+	first := t.TempDir()
+	second := t.TempDir()
+	writeDiskBlueprint(t, filepath.Join(first, "web-dev"), "web-dev", "First Web")
+	writeDiskBlueprint(t, filepath.Join(second, "web-dev"), "web-dev", "Second Web")
+	t.Setenv(EnvBlueprintPath, first+string(os.PathListSeparator)+second)
+
+	mgr, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+
+	team, err := mgr.Get("team-dev")
+	if err != nil {
+		t.Fatalf("Expected team-dev blueprint from home directory: %v", err)
+	}
+	if team.Source != teamDir {
+		t.Errorf("Expected source %s, got %s", teamDir, team.Source)
+	}
+
+	content, err := mgr.GetAgentContent("team-dev", "custom-agent", &TemplateData{WorkflowName: "demo"})
+	if err != nil {
+		t.Fatalf("GetAgentContent failed: %v", err)
+	}
+	if string(content) != "# Custom agent for demo\n" {
+		t.Errorf("Unexpected rendered content: %q", content)
+	}
+
+	webDev, err := mgr.Get("web-dev")
+	if err != nil {
+		t.Fatalf("Failed to get web-dev blueprint: %v", err)
+	}
+	if webDev.DisplayName != "First Web" {
+		t.Errorf("Expected first CCFLOW_BLUEPRINT_PATH entry to win, got %s", webDev.DisplayName)
+	}
+
+	goCli, err := mgr.Get("go-cli-dev")
+	if err != nil {
+		t.Fatalf("Failed to get go-cli-dev blueprint: %v", err)
+	}
+	if goCli.Source != SourceEmbedded {
+		t.Errorf("Expected go-cli-dev to be embedded, got %s", goCli.Source)
+	}
+}
+
+func TestNewManager_BlueprintPathSingleDirectory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// A CCFLOW_BLUEPRINT_PATH entry may point directly at a blueprint
+	dir := filepath.Join(t.TempDir(), "solo")
+	writeDiskBlueprint(t, dir, "", "Solo")
+	t.Setenv(EnvBlueprintPath, dir)
+
+	mgr, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+
+	// ID defaults to the directory name
+	if !mgr.HasAgent("solo", "custom-agent") {
+		t.Error("Expected solo blueprint to provide custom-agent")
+	}
+}
+
+func TestNewManager_SkipsUnparsableBlueprint(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(EnvBlueprintPath, "")
+
+	blueprintsDir := filepath.Join(home, ".ccflow", "blueprints")
+	writeDiskBlueprint(t, filepath.Join(blueprintsDir, "team-dev"), "team-dev", "Team Development")
+	badDir := filepath.Join(blueprintsDir, "bad")
+	os.MkdirAll(badDir, 0755)
+	os.WriteFile(filepath.Join(badDir, "blueprint.yaml"), []byte("id: [oops\n"), 0644)
+
+	mgr, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	if _, err := mgr.Get("team-dev"); err != nil {
+		t.Errorf("Expected team-dev to load despite the bad blueprint: %v", err)
+	}
+	errs := mgr.LoadErrors()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), badDir) {
+		t.Errorf("Expected one load error naming %s, got %v", badDir, errs)
+	}
+}
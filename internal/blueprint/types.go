@@ -3,6 +3,7 @@ package blueprint
 // Blueprint represents a workflow blueprint configuration
 type Blueprint struct {
 	ID              string          `yaml:"id"`
+	Extends         string          `yaml:"extends,omitempty"`
 	DisplayName     string          `yaml:"display_name"`
 	Description     string          `yaml:"description"`
 	DefaultTopology string          `yaml:"default_topology"`
@@ -12,6 +13,9 @@ type Blueprint struct {
 	Hooks           HookDefaults    `yaml:"hooks"`
 	HooksManifest   HooksManifest   `yaml:"hooks_manifest"`
 	MCPSuggestions  MCPSuggestions  `yaml:"mcp_suggestions"`
+
+	// Source is "embedded" or the directory the blueprint was loaded from
+	Source string `yaml:"-"`
 }
 
 // DefaultRepo represents a default repository in a blueprint
@@ -31,16 +35,22 @@ type RepoInfo struct {
 // AgentDefaults defines default agents for a blueprint
 type AgentDefaults struct {
 	Defaults []string `yaml:"defaults"`
+	// Remove drops agents inherited from the extended blueprint
+	Remove []string `yaml:"remove,omitempty"`
 }
 
 // CommandDefaults defines default commands for a blueprint
 type CommandDefaults struct {
 	Defaults []string `yaml:"defaults"`
+	// Remove drops commands inherited from the extended blueprint
+	Remove []string `yaml:"remove,omitempty"`
 }
 
 // HookDefaults defines default hooks for a blueprint
 type HookDefaults struct {
 	Defaults []string `yaml:"defaults"`
+	// Remove drops hooks inherited from the extended blueprint
+	Remove []string `yaml:"remove,omitempty"`
 }
 
 // HooksManifest maps hook names to their settings.json registration info
@@ -0,0 +1,48 @@
+package config
+
+import "fmt"
+
+// CurrentVersion is the workflow.yaml format version this ccflow writes
+const CurrentVersion = 1
+
+// Migrate brings a workflow config written by an older ccflow up to date,
+// filling fields it predates with their defaults. It returns a "field: value"
+// description of each change.
+func Migrate(cfg *WorkflowConfig) []string {
+	defaults := NewDefaultWorkflowConfig(cfg.Name)
+	var changes []string
+
+	if cfg.Version < CurrentVersion {
+		cfg.Version = CurrentVersion
+		changes = append(changes, fmt.Sprintf("version: %d", CurrentVersion))
+	}
+
+	if cfg.Topology == TopologyMultiRepo {
+		fillDefault(&changes, "paths.hub", &cfg.Paths.Hub, defaults.Paths.Hub)
+	}
+	fillDefault(&changes, "paths.docs", &cfg.Paths.Docs, defaults.Paths.Docs)
+	fillDefault(&changes, "state.root", &cfg.State.Root, defaults.State.Root)
+	fillDefault(&changes, "state.state_dir", &cfg.State.StateDir, defaults.State.StateDir)
+	fillDefault(&changes, "state.designs_dir", &cfg.State.DesignsDir, defaults.State.DesignsDir)
+
+	fillDefault(&changes, "mcp.vcs", &cfg.MCP.VCS, defaults.MCP.VCS)
+	fillDefault(&changes, "mcp.tracker", &cfg.MCP.Tracker, defaults.MCP.Tracker)
+	fillDefault(&changes, "mcp.deploy", &cfg.MCP.Deploy, defaults.MCP.Deploy)
+
+	fillDefault(&changes, "transitions.idea_to_design.mode", &cfg.Transitions.IdeaToDesign.Mode, defaults.Transitions.IdeaToDesign.Mode)
+	fillDefault(&changes, "transitions.design_to_implement.mode", &cfg.Transitions.DesignToImplement.Mode, defaults.Transitions.DesignToImplement.Mode)
+	fillDefault(&changes, "transitions.implement_to_review.mode", &cfg.Transitions.ImplementToReview.Mode, defaults.Transitions.ImplementToReview.Mode)
+	fillDefault(&changes, "transitions.review_to_release.mode", &cfg.Transitions.ReviewToRelease.Mode, defaults.Transitions.ReviewToRelease.Mode)
+
+	fillDefault(&changes, "parallel.sync_gate", &cfg.Parallel.SyncGate, defaults.Parallel.SyncGate)
+
+	return changes
+}
+
+// fillDefault sets an empty field to its default and records the change
+func fillDefault[T ~string](changes *[]string, field string, value *T, def T) {
+	if *value == "" && def != "" {
+		*value = def
+		*changes = append(*changes, fmt.Sprintf("%s: %s", field, def))
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	cfg := &WorkflowConfig{
+		Name:     "old",
+		Topology: TopologyMultiRepo,
+		Paths:    PathsConfig{Hub: "hub"},
+		State:    StateConfig{Root: "docs/workflow", StateDir: "docs/workflow/state", DesignsDir: "docs/workflow/designs"},
+		MCP:      MCPConfig{VCS: VCSGitHub},
+		Transitions: TransitionsConfig{
+			DesignToImplement: TransitionConfig{Mode: TransitionManual},
+		},
+	}
+
+	got := Migrate(cfg)
+	want := []string{
+		"version: 1",
+		"paths.docs: docs",
+		"mcp.tracker: none",
+		"mcp.deploy: none",
+		"transitions.idea_to_design.mode: prompt",
+		"transitions.implement_to_review.mode: prompt",
+		"transitions.review_to_release.mode: prompt",
+		"parallel.sync_gate: all",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Migrate() = %v\nwant %v", got, want)
+	}
+
+	// Values already set are kept
+	if cfg.Paths.Hub != "hub" || cfg.MCP.VCS != VCSGitHub || cfg.Transitions.DesignToImplement.Mode != TransitionManual {
+		t.Errorf("Migrate() overwrote existing values: %+v", cfg)
+	}
+
+	if again := Migrate(cfg); len(again) != 0 {
+		t.Errorf("Expected a migrated config to need no changes, got %v", again)
+	}
+}
+
+func TestMigrate_Default(t *testing.T) {
+	if changes := Migrate(NewDefaultWorkflowConfig("new")); len(changes) != 0 {
+		t.Errorf("Expected the default config to need no changes, got %v", changes)
+	}
+}
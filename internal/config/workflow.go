@@ -1,5 +1,7 @@
 package config
 
+import "slices"
+
 // Topology represents the workflow topology type
 type Topology string
 
@@ -22,6 +24,12 @@ const (
 	RepoKindUnknown   RepoKind = "unknown"
 )
 
+// RepoKinds lists every repository kind, in the order they are offered
+var RepoKinds = []RepoKind{
+	RepoKindNode, RepoKindJava, RepoKindGo, RepoKindPython,
+	RepoKindSwift, RepoKindTerraform, RepoKindDocs, RepoKindUnknown,
+}
+
 // VCSProvider represents version control system provider
 type VCSProvider string
 
@@ -70,11 +78,35 @@ type TransitionsConfig struct {
 	ReviewToRelease   TransitionConfig `yaml:"review_to_release" json:"review_to_release"`
 }
 
+// GateCommand is a shell command that must succeed for a gate to pass
+type GateCommand struct {
+	Repo string `yaml:"repo,omitempty" json:"repo,omitempty"` // repo name to run in; empty runs at the workspace root
+	Run  string `yaml:"run" json:"run"`
+}
+
+// GateConfig defines the checks required before leaving a phase.
+// "{feature}" in required files is replaced with the feature ID.
+type GateConfig struct {
+	Commands       []GateCommand `yaml:"commands,omitempty" json:"commands,omitempty"`
+	RequiredFiles  []string      `yaml:"required_files,omitempty" json:"required_files,omitempty"`
+	RequiredFields []string      `yaml:"required_fields,omitempty" json:"required_fields,omitempty"`
+}
+
+// GatesConfig defines quality gates per phase transition
+type GatesConfig struct {
+	Enabled           bool       `yaml:"enabled" json:"enabled"`
+	IdeaToDesign      GateConfig `yaml:"idea_to_design,omitempty" json:"idea_to_design,omitempty"`
+	DesignToImplement GateConfig `yaml:"design_to_implement,omitempty" json:"design_to_implement,omitempty"`
+	ImplementToReview GateConfig `yaml:"implement_to_review,omitempty" json:"implement_to_review,omitempty"`
+	ReviewToRelease   GateConfig `yaml:"review_to_release,omitempty" json:"review_to_release,omitempty"`
+}
+
 // ParallelGroup defines a group of agents that run in parallel
 type ParallelGroup struct {
 	Name   string   `yaml:"name" json:"name"`
 	Agents []string `yaml:"agents" json:"agents"`
-	Sync   string   `yaml:"sync" json:"sync"` // "all" or "any"
+	Sync   string   `yaml:"sync" json:"sync"`                       // "all" or "any"; defaults to sync_gate
+	Phase  string   `yaml:"phase,omitempty" json:"phase,omitempty"` // phase the group runs in; defaults to implement
 }
 
 // ParallelConfig defines parallel execution settings
@@ -104,13 +136,18 @@ type StateConfig struct {
 	DesignsDir string `yaml:"designs_dir" json:"designs_dir"`
 }
 
-// MCPConfig represents MCP integration preferences (guidance only)
+// MCPConfig represents MCP integration preferences, written to .mcp.json
 type MCPConfig struct {
 	VCS     VCSProvider     `yaml:"vcs" json:"vcs"`
 	Tracker TrackerProvider `yaml:"tracker" json:"tracker"`
 	Deploy  DeployProvider  `yaml:"deploy" json:"deploy"`
 }
 
+// InstallConfig controls how the hub .claude directory is installed into repos
+type InstallConfig struct {
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"` // "symlink" (default) or "copy"
+}
+
 // AgentPermission defines repository access permissions for an agent
 type AgentPermission struct {
 	Write []string `yaml:"write,omitempty" json:"write,omitempty"`
@@ -119,19 +156,18 @@ type AgentPermission struct {
 
 // WorkflowConfig represents the workflow.yaml configuration
 type WorkflowConfig struct {
-	Version   int          `yaml:"version" json:"version"`
-	Name      string       `yaml:"name" json:"name"`
-	Topology  Topology     `yaml:"topology" json:"topology"`
-	Blueprint string       `yaml:"blueprint" json:"blueprint"`
-	Paths     PathsConfig  `yaml:"paths" json:"paths"`
-	State     StateConfig  `yaml:"state" json:"state"`
-	Repos     []RepoConfig `yaml:"repos" json:"repos"`
+	Version   int           `yaml:"version" json:"version"`
+	Name      string        `yaml:"name" json:"name"`
+	Topology  Topology      `yaml:"topology" json:"topology"`
+	Blueprint string        `yaml:"blueprint" json:"blueprint"`
+	Paths     PathsConfig   `yaml:"paths" json:"paths"`
+	Install   InstallConfig `yaml:"install,omitempty" json:"install,omitempty"`
+	State     StateConfig   `yaml:"state" json:"state"`
+	Repos     []RepoConfig  `yaml:"repos" json:"repos"`
 	Hooks     struct {
 		Enabled bool `yaml:"enabled" json:"enabled"`
 	} `yaml:"hooks" json:"hooks"`
-	Gates struct {
-		Enabled bool `yaml:"enabled" json:"enabled"`
-	} `yaml:"gates" json:"gates"`
+	Gates            GatesConfig                `yaml:"gates" json:"gates"`
 	MCP              MCPConfig                  `yaml:"mcp" json:"mcp"`
 	AgentPermissions map[string]AgentPermission `yaml:"agent_permissions,omitempty" json:"agent_permissions,omitempty"`
 	Transitions      TransitionsConfig          `yaml:"transitions" json:"transitions"`
@@ -141,7 +177,7 @@ type WorkflowConfig struct {
 // NewDefaultWorkflowConfig creates a new workflow config with sensible defaults
 func NewDefaultWorkflowConfig(name string) *WorkflowConfig {
 	return &WorkflowConfig{
-		Version:   1,
+		Version:   CurrentVersion,
 		Name:      name,
 		Topology:  TopologyMultiRepo,
 		Blueprint: "web-dev",
@@ -158,9 +194,7 @@ func NewDefaultWorkflowConfig(name string) *WorkflowConfig {
 		Hooks: struct {
 			Enabled bool `yaml:"enabled" json:"enabled"`
 		}{Enabled: true},
-		Gates: struct {
-			Enabled bool `yaml:"enabled" json:"enabled"`
-		}{Enabled: true},
+		Gates: GatesConfig{Enabled: true},
 		MCP: MCPConfig{
 			VCS:     VCSNone,
 			Tracker: TrackerNone,
@@ -192,4 +226,40 @@ type ManagedFileInfo struct {
 type ManagedFilesManifest struct {
 	Version int                        `json:"version"`
 	Files   map[string]ManagedFileInfo `json:"files"`
+	// Removed lists files removed with ccflow remove-*, which upgrade
+	// does not recreate
+	Removed []string `json:"removed,omitempty"`
+}
+
+// Track records a file written from a template by the hash of its content
+func (m *ManagedFilesManifest) Track(relPath, templateID, hash string) {
+	m.Untrack(relPath)
+	m.Files[relPath] = ManagedFileInfo{
+		TemplateID: templateID,
+		Hash:       hash,
+		Version:    Version,
+	}
+}
+
+// Untrack stops tracking a file without recording it as removed, e.g. when
+// it is replaced with content that doesn't come from a template
+func (m *ManagedFilesManifest) Untrack(relPath string) {
+	delete(m.Files, relPath)
+	m.Removed = slices.DeleteFunc(m.Removed, func(p string) bool { return p == relPath })
+}
+
+// Forget stops tracking a file and records it as removed. It reports whether
+// the file was tracked.
+func (m *ManagedFilesManifest) Forget(relPath string) bool {
+	_, tracked := m.Files[relPath]
+	delete(m.Files, relPath)
+	if !m.IsRemoved(relPath) {
+		m.Removed = append(m.Removed, relPath)
+	}
+	return tracked
+}
+
+// IsRemoved reports whether a file was removed with ccflow remove-*
+func (m *ManagedFilesManifest) IsRemoved(relPath string) bool {
+	return slices.Contains(m.Removed, relPath)
 }
@@ -0,0 +1,259 @@
+package feature
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Wameedh/ccflow/internal/schema"
+	"github.com/Wameedh/ccflow/internal/util"
+)
+
+// SchemaTemplate is the blueprint template describing feature state files
+const SchemaTemplate = "state.schema.json"
+
+// StatusRollback is never reached by advancing; it must be set explicitly
+const StatusRollback = "rollback"
+
+// Feature is a feature state file. Data holds the full JSON document so
+// blueprint-specific fields are preserved when the file is rewritten.
+type Feature struct {
+	Path string
+	Data map[string]interface{}
+}
+
+// ID returns the feature ID
+func (f *Feature) ID() string { return f.str("id") }
+
+// Title returns the feature title
+func (f *Feature) Title() string { return f.str("title") }
+
+// Status returns the current status
+func (f *Feature) Status() string { return f.str("status") }
+
+// CreatedAt returns the creation timestamp
+func (f *Feature) CreatedAt() string { return f.str("created_at") }
+
+// UpdatedAt returns the last update timestamp
+func (f *Feature) UpdatedAt() string { return f.str("updated_at") }
+
+// str returns a string field, or "" if missing or not a string
+func (f *Feature) str(key string) string {
+	s, _ := f.Data[key].(string)
+	return s
+}
+
+// Store reads and writes feature state files in a workflow's state directory
+type Store struct {
+	dir    string
+	schema *schema.Schema
+	now    func() time.Time
+}
+
+// NewStore creates a store for dir, validating files against the given
+// state.schema.json content
+func NewStore(dir string, schemaData []byte) (*Store, error) {
+	s, err := schema.Parse(schemaData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", SchemaTemplate, err)
+	}
+	if len(s.EnumStrings("status")) == 0 {
+		return nil, fmt.Errorf("invalid %s: status has no enum of statuses", SchemaTemplate)
+	}
+	return &Store{dir: dir, schema: s, now: time.Now}, nil
+}
+
+// Statuses returns the lifecycle statuses in order
+func (s *Store) Statuses() []string {
+	return s.schema.EnumStrings("status")
+}
+
+// Validate returns the schema violations of a feature
+func (s *Store) Validate(f *Feature) []schema.Violation {
+	return s.schema.Validate(f.Data)
+}
+
+// New creates a feature in the first lifecycle status and writes it
+func (s *Store) New(id, title, description string) (*Feature, error) {
+	if err := checkID(id); err != nil {
+		return nil, err
+	}
+	path := s.path(id)
+	if util.FileExists(path) {
+		return nil, fmt.Errorf("feature %s already exists", id)
+	}
+
+	now := s.timestamp()
+	f := &Feature{
+		Path: path,
+		Data: map[string]interface{}{
+			"id":         id,
+			"title":      title,
+			"status":     s.Statuses()[0],
+			"created_at": now,
+			"updated_at": now,
+		},
+	}
+	if description != "" {
+		f.Data["description"] = description
+	}
+
+	if err := s.save(f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Get loads a feature by ID
+func (s *Store) Get(id string) (*Feature, error) {
+	if err := checkID(id); err != nil {
+		return nil, err
+	}
+	path := s.path(id)
+	if !util.FileExists(path) {
+		return nil, fmt.Errorf("feature not found: %s", id)
+	}
+	return Load(path)
+}
+
+// LoadError is a state file List could not read or parse
+type LoadError struct {
+	Path string
+	Err  error
+}
+
+func (e *LoadError) Error() string {
+	return e.Err.Error()
+}
+
+// List loads every feature in the state directory, sorted by ID. Files that
+// can't be read or parsed are skipped and returned as load errors, sorted by
+// path, so that one broken file doesn't hide the rest.
+func (s *Store) List() ([]*Feature, []*LoadError, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var features []*Feature
+	var failed []*LoadError
+	for _, path := range paths {
+		f, err := Load(path)
+		if err != nil {
+			failed = append(failed, &LoadError{Path: path, Err: err})
+			continue
+		}
+		features = append(features, f)
+	}
+
+	sort.Slice(features, func(i, j int) bool {
+		return features[i].ID() < features[j].ID()
+	})
+	return features, failed, nil
+}
+
+// Advance moves a feature to the given status, or to the next status in the
+// lifecycle when to is empty, and updates its timestamp
+func (s *Store) Advance(id, to string) (*Feature, error) {
+	f, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := s.Statuses()
+	if to == "" {
+		to, err = NextStatus(statuses, f.Status())
+		if err != nil {
+			return nil, fmt.Errorf("feature %s: %w", id, err)
+		}
+	} else if !slices.Contains(statuses, to) {
+		return nil, fmt.Errorf("invalid status: %s (must be one of %s)", to, strings.Join(statuses, ", "))
+	}
+	if to == f.Status() {
+		return nil, fmt.Errorf("feature %s is already %s", id, to)
+	}
+
+	f.Data["status"] = to
+	f.Data["updated_at"] = s.timestamp()
+
+	if err := s.save(f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// NextStatus returns the status following current in the lifecycle.
+// Rollback is skipped; it is only reached by setting it explicitly.
+func NextStatus(statuses []string, current string) (string, error) {
+	i := slices.Index(statuses, current)
+	if i < 0 {
+		return "", fmt.Errorf("unknown status: %s", current)
+	}
+	for _, next := range statuses[i+1:] {
+		if next != StatusRollback {
+			return next, nil
+		}
+	}
+	return "", fmt.Errorf("status %s is the last in the lifecycle", current)
+}
+
+// Load reads a feature state file
+func Load(path string) (*Feature, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc map[string]interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &Feature{Path: path, Data: doc}, nil
+}
+
+// save validates a feature and writes it, refusing to write invalid state
+func (s *Store) save(f *Feature) error {
+	if violations := s.Validate(f); len(violations) > 0 {
+		var lines []string
+		for _, v := range violations {
+			lines = append(lines, "  - "+v.String())
+		}
+		return fmt.Errorf("feature %s does not match %s:\n%s", f.ID(), SchemaTemplate, strings.Join(lines, "\n"))
+	}
+
+	data, err := json.MarshalIndent(f.Data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal feature: %w", err)
+	}
+	if err := util.EnsureDir(filepath.Dir(f.Path)); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return os.WriteFile(f.Path, append(data, '\n'), 0644)
+}
+
+// path returns the state file path for a feature ID
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// timestamp returns the current time in RFC 3339, as required by date-time fields
+func (s *Store) timestamp() string {
+	return s.now().UTC().Format(time.RFC3339)
+}
+
+// checkID rejects IDs that would escape the state directory
+func checkID(id string) error {
+	if id == "" || id != filepath.Base(id) || strings.HasPrefix(id, ".") {
+		return fmt.Errorf("invalid feature id: %q", id)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package feature
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSchema = `{
+  "type": "object",
+  "required": ["id", "title", "status", "created_at"],
+  "properties": {
+    "id": {"type": "string", "pattern": "^[a-z0-9-]+$"},
+    "title": {"type": "string"},
+    "status": {"type": "string", "enum": ["ideation", "design", "released", "rollback"]},
+    "created_at": {"type": "string", "format": "date-time"},
+    "updated_at": {"type": "string", "format": "date-time"}
+  }
+}`
+
+// newTestStore creates a store in a temp directory with a controllable clock
+func newTestStore(t *testing.T) (*Store, *time.Time) {
+	t.Helper()
+	store, err := NewStore(t.TempDir(), []byte(testSchema))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	return store, &now
+}
+
+func TestNew(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	f, err := store.New("login-page", "Login page", "Let users sign in")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if f.Status() != "ideation" {
+		t.Errorf("Expected first status, got %s", f.Status())
+	}
+	if f.CreatedAt() != "2024-05-01T10:00:00Z" || f.UpdatedAt() != f.CreatedAt() {
+		t.Errorf("Unexpected timestamps: %s %s", f.CreatedAt(), f.UpdatedAt())
+	}
+	if filepath.Base(f.Path) != "login-page.json" {
+		t.Errorf("Unexpected path: %s", f.Path)
+	}
+
+	loaded, err := store.Get("login-page")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if loaded.Title() != "Login page" || loaded.Data["description"] != "Let users sign in" {
+		t.Errorf("Unexpected loaded feature: %v", loaded.Data)
+	}
+
+	if _, err := store.New("login-page", "Again", ""); err == nil {
+		t.Error("Expected error creating a duplicate feature")
+	}
+}
+
+func TestNew_InvalidID(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	for _, id := range []string{"", "../escape", ".hidden"} {
+		if _, err := store.New(id, "x", ""); err == nil {
+			t.Errorf("Expected error for id %q", id)
+		}
+	}
+
+	_, err := store.New("Login_Page", "x", "")
+	if err == nil || !strings.Contains(err.Error(), "/id") {
+		t.Errorf("Expected schema violation at /id, got %v", err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(store.dir, "*.json")); len(files) != 0 {
+		t.Errorf("Invalid feature should not be written, found %v", files)
+	}
+}
+
+func TestAdvance(t *testing.T) {
+	store, now := newTestStore(t)
+
+	if _, err := store.New("search", "Search", ""); err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	*now = now.Add(time.Hour)
+	f, err := store.Advance("search", "")
+	if err != nil {
+		t.Fatalf("Advance failed: %v", err)
+	}
+	if f.Status() != "design" || f.UpdatedAt() != "2024-05-01T11:00:00Z" {
+		t.Errorf("Unexpected feature after advance: %v", f.Data)
+	}
+	if f.CreatedAt() != "2024-05-01T10:00:00Z" {
+		t.Errorf("created_at should not change, got %s", f.CreatedAt())
+	}
+
+	// Only rollback follows released, and advancing never picks it
+	if f, err = store.Advance("search", ""); err != nil || f.Status() != "released" {
+		t.Fatalf("Expected released, got %v %v", f, err)
+	}
+	if _, err := store.Advance("search", ""); err == nil {
+		t.Error("Expected error advancing past the last status")
+	}
+
+	// Explicit statuses, including rollback
+	if f, err = store.Advance("search", "rollback"); err != nil || f.Status() != "rollback" {
+		t.Fatalf("Expected rollback, got %v %v", f, err)
+	}
+	if _, err := store.Advance("search", "shipped"); err == nil {
+		t.Error("Expected error for unknown status")
+	}
+	if _, err := store.Advance("search", "rollback"); err == nil {
+		t.Error("Expected error when status does not change")
+	}
+	if _, err := store.Advance("missing", ""); err == nil {
+		t.Error("Expected error for missing feature")
+	}
+}
+
+func TestAdvance_PreservesUnknownFields(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	path := filepath.Join(store.dir, "infra.json")
+	content := `{"id": "infra", "title": "Infra", "status": "ideation", "created_at": "2024-01-01T00:00:00Z",
+		"cost_estimate": {"monthly_cost": 12.5}, "environments": ["dev", "prod"]}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Advance("infra", ""); err != nil {
+		t.Fatalf("Advance failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"monthly_cost": 12.5`, `"prod"`, `"status": "design"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %s in rewritten file:\n%s", want, data)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	for _, id := range []string{"zeta", "alpha", "mid"} {
+		if _, err := store.New(id, id, ""); err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+	}
+
+	features, failed, err := store.List()
+	if err != nil || len(failed) > 0 {
+		t.Fatalf("List failed: %v %v", err, failed)
+	}
+	var ids []string
+	for _, f := range features {
+		ids = append(ids, f.ID())
+	}
+	if strings.Join(ids, ",") != "alpha,mid,zeta" {
+		t.Errorf("Expected sorted IDs, got %v", ids)
+	}
+}
+
+func TestNewStore_RequiresStatusEnum(t *testing.T) {
+	if _, err := NewStore(t.TempDir(), []byte(`{"properties": {"status": {"type": "string"}}}`)); err == nil {
+		t.Error("Expected error for schema without status enum")
+	}
+}
+
+func TestList_SkipsMalformedFiles(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	for _, id := range []string{"alpha", "zeta"} {
+		if _, err := store.New(id, id, ""); err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+	}
+	broken := filepath.Join(store.dir, "broken.json")
+	if err := os.WriteFile(broken, []byte(`{"id": "broken",`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	features, failed, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(features) != 2 || features[0].ID() != "alpha" || features[1].ID() != "zeta" {
+		t.Errorf("Expected alpha and zeta to load, got %v", features)
+	}
+	if len(failed) != 1 || failed[0].Path != broken || !strings.Contains(failed[0].Error(), "broken.json") {
+		t.Errorf("Expected broken.json to be reported, got %v", failed)
+	}
+}
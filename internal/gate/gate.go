@@ -0,0 +1,206 @@
+package gate
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/feature"
+	"github.com/Wameedh/ccflow/internal/transition"
+	"github.com/Wameedh/ccflow/internal/util"
+	"github.com/Wameedh/ccflow/internal/workspace"
+)
+
+// FeaturePlaceholder in required file paths is replaced with the feature ID
+const FeaturePlaceholder = "{feature}"
+
+// EnvFeature is set to the feature ID when gate commands run
+const EnvFeature = "CCFLOW_FEATURE"
+
+// CheckResult is the outcome of a single gate check
+type CheckResult struct {
+	Kind    string // "field", "file" or "command"
+	Name    string // field name, file path or command
+	Passed  bool
+	Message string
+	Output  string // combined output of a command
+}
+
+// Result is the outcome of a gate
+type Result struct {
+	Transition transition.Transition
+	Feature    string
+	Checks     []CheckResult
+}
+
+// Passed reports whether every check passed
+func (r *Result) Passed() bool {
+	for _, c := range r.Checks {
+		if !c.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed returns the checks that did not pass
+func (r *Result) Failed() []CheckResult {
+	var failed []CheckResult
+	for _, c := range r.Checks {
+		if !c.Passed {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// Error is returned when a gate blocks a transition
+type Error struct {
+	Result *Result
+}
+
+func (e *Error) Error() string {
+	var names []string
+	for _, c := range e.Result.Failed() {
+		names = append(names, fmt.Sprintf("%s %s", c.Kind, c.Name))
+	}
+	return fmt.Sprintf("gate %s failed for %s: %s (run 'ccflow gate check %s %s' for details)",
+		e.Result.Transition.Key, e.Result.Feature, strings.Join(names, ", "), e.Result.Transition.From, e.Result.Feature)
+}
+
+// Runner runs the gates defined in a workspace's workflow.yaml
+type Runner struct {
+	ws  *workspace.Workspace
+	run func(dir, command string, env []string) ([]byte, error)
+}
+
+// NewRunner creates a gate runner for a workspace
+func NewRunner(ws *workspace.Workspace) *Runner {
+	return &Runner{ws: ws, run: runShell}
+}
+
+// For returns the gate configured for the transition out of a phase
+func For(cfg config.GatesConfig, from transition.Phase) config.GateConfig {
+	switch from {
+	case transition.PhaseIdea:
+		return cfg.IdeaToDesign
+	case transition.PhaseDesign:
+		return cfg.DesignToImplement
+	case transition.PhaseImplement:
+		return cfg.ImplementToReview
+	case transition.PhaseReview:
+		return cfg.ReviewToRelease
+	}
+	return config.GateConfig{}
+}
+
+// Check runs the gate for a transition against a feature: required state
+// fields first, then required files, then commands
+func (r *Runner) Check(t transition.Transition, f *feature.Feature) *Result {
+	gate := For(r.ws.Config.Gates, t.From)
+	result := &Result{Transition: t, Feature: f.ID()}
+
+	for _, field := range gate.RequiredFields {
+		check := CheckResult{Kind: "field", Name: field}
+		if isEmpty(f.Data[field]) {
+			check.Message = fmt.Sprintf("state field %s is missing or empty", field)
+		} else {
+			check.Passed = true
+			check.Message = fmt.Sprintf("state field %s is set", field)
+		}
+		result.Checks = append(result.Checks, check)
+	}
+
+	for _, file := range gate.RequiredFiles {
+		relPath := strings.ReplaceAll(file, FeaturePlaceholder, f.ID())
+		check := CheckResult{Kind: "file", Name: relPath}
+		if util.FileExists(filepath.Join(r.ws.Root, filepath.FromSlash(relPath))) {
+			check.Passed = true
+			check.Message = "file exists"
+		} else {
+			check.Message = "file not found"
+		}
+		result.Checks = append(result.Checks, check)
+	}
+
+	for _, cmd := range gate.Commands {
+		result.Checks = append(result.Checks, r.runCommand(cmd, f.ID()))
+	}
+
+	return result
+}
+
+// Enforce runs the gate when gates are enabled and returns an *Error if it
+// fails. It matches transition.GateFunc so the engine can be blocked by gates.
+func (r *Runner) Enforce(t transition.Transition, f *feature.Feature) error {
+	if !r.ws.Config.Gates.Enabled {
+		return nil
+	}
+	if result := r.Check(t, f); !result.Passed() {
+		return &Error{Result: result}
+	}
+	return nil
+}
+
+// runCommand runs a gate command in its repo
+func (r *Runner) runCommand(cmd config.GateCommand, featureID string) CheckResult {
+	check := CheckResult{Kind: "command", Name: cmd.Run}
+
+	dir := r.ws.Root
+	if cmd.Repo != "" {
+		check.Name = fmt.Sprintf("%s (%s)", cmd.Run, cmd.Repo)
+		repo := findRepo(r.ws.Config.Repos, cmd.Repo)
+		if repo == nil {
+			check.Message = fmt.Sprintf("unknown repo: %s", cmd.Repo)
+			return check
+		}
+		dir = filepath.Join(r.ws.Root, repo.Path)
+	}
+
+	output, err := r.run(dir, cmd.Run, []string{EnvFeature + "=" + featureID})
+	check.Output = string(output)
+	if err != nil {
+		check.Message = fmt.Sprintf("command failed: %v", err)
+		return check
+	}
+
+	check.Passed = true
+	check.Message = "command succeeded"
+	return check
+}
+
+// runShell runs a command with sh -c in dir
+func runShell(dir, command string, env []string) ([]byte, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	return cmd.CombinedOutput()
+}
+
+// findRepo returns the repo with the given name
+func findRepo(repos []config.RepoConfig, name string) *config.RepoConfig {
+	for i := range repos {
+		if repos[i].Name == name {
+			return &repos[i]
+		}
+	}
+	return nil
+}
+
+// isEmpty reports whether a state value is missing, empty or an empty list
+func isEmpty(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(val) == ""
+	case []interface{}:
+		return len(val) == 0
+	case map[string]interface{}:
+		return len(val) == 0
+	}
+	return false
+}
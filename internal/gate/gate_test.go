@@ -0,0 +1,167 @@
+package gate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/feature"
+	"github.com/Wameedh/ccflow/internal/transition"
+	"github.com/Wameedh/ccflow/internal/workspace"
+)
+
+var designToImplement = transition.Transition{
+	From: transition.PhaseDesign,
+	To:   transition.PhaseImplement,
+	Key:  "design_to_implement",
+	Mode: config.TransitionAuto,
+}
+
+// createTestWorkspace creates a workspace with one repo and the given gate
+// out of the design phase
+func createTestWorkspace(t *testing.T, gate config.GateConfig) *workspace.Workspace {
+	t.Helper()
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "api"), 0755)
+
+	cfg := config.NewDefaultWorkflowConfig("test-workflow")
+	cfg.Repos = []config.RepoConfig{{Name: "api", Path: "api", Kind: config.RepoKindGo}}
+	cfg.Gates.DesignToImplement = gate
+
+	return &workspace.Workspace{
+		Root:     tmpDir,
+		Topology: config.TopologyMultiRepo,
+		Config:   cfg,
+	}
+}
+
+func testFeature(data map[string]interface{}) *feature.Feature {
+	data["id"] = "login"
+	return &feature.Feature{Data: data}
+}
+
+func TestCheck_FieldsAndFiles(t *testing.T) {
+	ws := createTestWorkspace(t, config.GateConfig{
+		RequiredFields: []string{"design_doc", "acceptance_criteria"},
+		RequiredFiles:  []string{"docs/design/{feature}.md"},
+	})
+	f := testFeature(map[string]interface{}{
+		"design_doc":          "docs/design/login.md",
+		"acceptance_criteria": []interface{}{},
+	})
+
+	result := NewRunner(ws).Check(designToImplement, f)
+	if len(result.Checks) != 3 {
+		t.Fatalf("Expected 3 checks, got %+v", result.Checks)
+	}
+	if result.Passed() {
+		t.Fatal("Expected gate to fail")
+	}
+
+	var failed []string
+	for _, c := range result.Failed() {
+		failed = append(failed, c.Name)
+	}
+	if strings.Join(failed, ",") != "acceptance_criteria,docs/design/login.md" {
+		t.Errorf("Unexpected failed checks: %v", failed)
+	}
+
+	// Satisfy the gate
+	f.Data["acceptance_criteria"] = []interface{}{"user can log in"}
+	os.MkdirAll(filepath.Join(ws.Root, "docs", "design"), 0755)
+	os.WriteFile(filepath.Join(ws.Root, "docs", "design", "login.md"), []byte("# Login\n"), 0644)
+
+	if result := NewRunner(ws).Check(designToImplement, f); !result.Passed() {
+		t.Errorf("Expected gate to pass, got %+v", result.Failed())
+	}
+}
+
+func TestCheck_Commands(t *testing.T) {
+	ws := createTestWorkspace(t, config.GateConfig{
+		Commands: []config.GateCommand{
+			{Run: "make lint"},
+			{Repo: "api", Run: "go test ./..."},
+			{Repo: "missing", Run: "true"},
+		},
+	})
+
+	var dirs, envs []string
+	runner := NewRunner(ws)
+	runner.run = func(dir, command string, env []string) ([]byte, error) {
+		dirs = append(dirs, dir)
+		envs = append(envs, env...)
+		if command == "go test ./..." {
+			return []byte("FAIL\n"), errors.New("exit status 1")
+		}
+		return nil, nil
+	}
+
+	result := runner.Check(designToImplement, testFeature(map[string]interface{}{}))
+	if len(result.Checks) != 3 {
+		t.Fatalf("Expected 3 checks, got %+v", result.Checks)
+	}
+
+	if !result.Checks[0].Passed {
+		t.Errorf("Expected first command to pass: %+v", result.Checks[0])
+	}
+	if c := result.Checks[1]; c.Passed || c.Output != "FAIL\n" {
+		t.Errorf("Expected failing command with output, got %+v", c)
+	}
+	if c := result.Checks[2]; c.Passed || !strings.Contains(c.Message, "unknown repo") {
+		t.Errorf("Expected unknown repo failure, got %+v", c)
+	}
+
+	// Unknown repos are not run
+	if len(dirs) != 2 || dirs[0] != ws.Root || dirs[1] != filepath.Join(ws.Root, "api") {
+		t.Errorf("Unexpected command dirs: %v", dirs)
+	}
+	if len(envs) == 0 || envs[0] != EnvFeature+"=login" {
+		t.Errorf("Expected %s in env, got %v", EnvFeature, envs)
+	}
+}
+
+func TestCheck_Shell(t *testing.T) {
+	ws := createTestWorkspace(t, config.GateConfig{
+		Commands: []config.GateCommand{
+			{Run: `test "$CCFLOW_FEATURE" = login`},
+			{Run: "echo broken; exit 3"},
+		},
+	})
+
+	result := NewRunner(ws).Check(designToImplement, testFeature(map[string]interface{}{}))
+	if !result.Checks[0].Passed {
+		t.Errorf("Expected feature env check to pass: %+v", result.Checks[0])
+	}
+	if c := result.Checks[1]; c.Passed || strings.TrimSpace(c.Output) != "broken" {
+		t.Errorf("Expected failing command with output, got %+v", c)
+	}
+}
+
+func TestEnforce(t *testing.T) {
+	ws := createTestWorkspace(t, config.GateConfig{RequiredFields: []string{"design_doc"}})
+	f := testFeature(map[string]interface{}{})
+
+	err := NewRunner(ws).Enforce(designToImplement, f)
+	var gateErr *Error
+	if !errors.As(err, &gateErr) {
+		t.Fatalf("Expected gate error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "ccflow gate check design login") {
+		t.Errorf("Expected hint in error, got %q", err.Error())
+	}
+
+	// Other transitions have no gate
+	ideaToDesign := transition.Transition{From: transition.PhaseIdea, To: transition.PhaseDesign, Key: "idea_to_design"}
+	if err := NewRunner(ws).Enforce(ideaToDesign, f); err != nil {
+		t.Errorf("Expected no gate for idea_to_design, got %v", err)
+	}
+
+	// Disabled gates are not enforced
+	ws.Config.Gates.Enabled = false
+	if err := NewRunner(ws).Enforce(designToImplement, f); err != nil {
+		t.Errorf("Expected disabled gates to pass, got %v", err)
+	}
+}
@@ -8,7 +8,11 @@ import (
 
 	"github.com/Wameedh/ccflow/internal/blueprint"
 	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/mcp"
+	"github.com/Wameedh/ccflow/internal/settings"
+	"github.com/Wameedh/ccflow/internal/stage"
 	"github.com/Wameedh/ccflow/internal/util"
+	"github.com/Wameedh/ccflow/internal/workspace"
 )
 
 // Generator handles creating workflow file structures
@@ -34,6 +38,7 @@ type GenerateOptions struct {
 	Tracker       config.TrackerProvider
 	Transitions   config.TransitionsConfig
 	Parallel      config.ParallelConfig
+	InstallMode   string // how repos receive the hub .claude: symlink or copy
 	Force         bool
 }
 
@@ -103,6 +108,11 @@ func (g *Generator) Generate(opts GenerateOptions) (*config.WorkflowConfig, erro
 		return nil, err
 	}
 
+	// Refuse broken blueprints before anything is written
+	if err := g.bpManager.Verify(bp.ID); err != nil {
+		return nil, err
+	}
+
 	// Create the workflow configuration
 	cfg := config.NewDefaultWorkflowConfig(opts.WorkflowName)
 	cfg.Blueprint = opts.Blueprint
@@ -114,6 +124,7 @@ func (g *Generator) Generate(opts GenerateOptions) (*config.WorkflowConfig, erro
 	cfg.MCP.Tracker = opts.Tracker
 	cfg.Transitions = opts.Transitions
 	cfg.Parallel = opts.Parallel
+	cfg.Install.Mode = opts.InstallMode
 
 	// Adjust paths based on topology
 	if opts.Topology == config.TopologySingleRepo {
@@ -124,35 +135,89 @@ func (g *Generator) Generate(opts GenerateOptions) (*config.WorkflowConfig, erro
 		cfg.State.DesignsDir = "docs/workflow/designs"
 	}
 
-	// Create template data
-	templateData := &blueprint.TemplateData{
-		WorkflowName:    opts.WorkflowName,
-		DocsRoot:        cfg.State.Root,
-		DocsStateDir:    cfg.State.StateDir,
-		DocsDesignDir:   cfg.State.DesignsDir,
-		TrackerProvider: string(opts.Tracker),
-		VCSProvider:     string(opts.VCS),
-		HooksEnabled:    opts.HooksEnabled,
-		GatesEnabled:    opts.GatesEnabled,
+	// Everything is written to a stage and moved into the workspace only
+	// once it is complete and valid, so a failure leaves the workspace as
+	// it was. The stage starts with the files generation reads or must not
+	// overwrite without --force.
+	st, err := stage.New(opts.WorkspacePath)
+	if err != nil {
+		return nil, err
+	}
+	defer st.Abort()
+
+	projectRoots := mcp.ProjectRoots(st.Root, cfg)
+	if err := st.Seed(seedPaths(st.Root, cfg, projectRoots)...); err != nil {
+		return nil, err
 	}
 
 	// Generate the structure based on topology
 	if opts.Topology == config.TopologyMultiRepo {
-		hubPath := filepath.Join(opts.WorkspacePath, cfg.Paths.Hub)
-		if err := g.generateMultiRepoStructure(opts.WorkspacePath, hubPath, cfg, bp, templateData, opts.Force); err != nil {
+		hubPath := filepath.Join(st.Dir, cfg.Paths.Hub)
+		if err := g.generateMultiRepoStructure(st.Dir, hubPath, cfg, bp, opts.Force); err != nil {
 			return nil, err
 		}
 	} else {
-		if err := g.generateSingleRepoStructure(opts.WorkspacePath, cfg, bp, templateData, opts.Force); err != nil {
+		if err := g.generateSingleRepoStructure(st.Dir, cfg, bp, opts.Force); err != nil {
 			return nil, err
 		}
 	}
 
+	// Configure MCP servers for the chosen providers
+	for _, root := range projectRoots {
+		if err := util.EnsureDir(st.Path(root)); err != nil {
+			return nil, err
+		}
+		if _, err := mcp.Apply(st.Path(root), cfg.MCP, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateStaged(st, cfg); err != nil {
+		return nil, fmt.Errorf("generated workflow is invalid, nothing was written: %w", err)
+	}
+	if err := st.Commit(); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
+// seedPaths returns the existing paths generation reads or updates: the
+// hub (or .claude and .ccflow), .gitignore and each project's .mcp.json
+func seedPaths(root string, cfg *config.WorkflowConfig, projectRoots []string) []string {
+	paths := []string{filepath.Join(root, ".gitignore")}
+	if cfg.Topology == config.TopologyMultiRepo {
+		paths = append(paths, filepath.Join(root, cfg.Paths.Hub))
+	} else {
+		paths = append(paths, filepath.Join(root, ".claude"), filepath.Join(root, ".ccflow"))
+	}
+	for _, projectRoot := range projectRoots {
+		paths = append(paths, filepath.Join(projectRoot, mcp.FileName))
+	}
+	return paths
+}
+
+// validateStaged checks that the staged workflow.yaml and settings.json
+// load before they replace anything
+func validateStaged(st *stage.Stage, cfg *config.WorkflowConfig) error {
+	claudePath := filepath.Join(st.Dir, ".claude")
+	markerPath := filepath.Join(st.Dir, ".ccflow", "workflow.yaml")
+	if cfg.Topology == config.TopologyMultiRepo {
+		claudePath = filepath.Join(st.Dir, cfg.Paths.Hub, ".claude")
+		markerPath = filepath.Join(st.Dir, cfg.Paths.Hub, "workflow.yaml")
+	}
+
+	if _, err := workspace.LoadConfig(markerPath); err != nil {
+		return err
+	}
+	if _, err := settings.Load(filepath.Join(claudePath, settings.FileName)); err != nil {
+		return err
+	}
+	return nil
+}
+
 // generateMultiRepoStructure creates the multi-repo workflow structure
-func (g *Generator) generateMultiRepoStructure(workspacePath, hubPath string, cfg *config.WorkflowConfig, bp *blueprint.Blueprint, data *blueprint.TemplateData, force bool) error {
+func (g *Generator) generateMultiRepoStructure(workspacePath, hubPath string, cfg *config.WorkflowConfig, bp *blueprint.Blueprint, force bool) error {
 	// Create workflow-hub directory
 	if err := util.EnsureDir(hubPath); err != nil {
 		return fmt.Errorf("failed to create hub directory: %w", err)
@@ -160,7 +225,7 @@ func (g *Generator) generateMultiRepoStructure(workspacePath, hubPath string, cf
 
 	// Create .claude directory structure in hub
 	claudePath := filepath.Join(hubPath, ".claude")
-	if err := g.generateClaudeDirectory(claudePath, bp, data, force); err != nil {
+	if err := g.generateClaudeDirectory(claudePath, cfg, bp, force); err != nil {
 		return err
 	}
 
@@ -185,10 +250,10 @@ func (g *Generator) generateMultiRepoStructure(workspacePath, hubPath string, cf
 }
 
 // generateSingleRepoStructure creates the single-repo workflow structure
-func (g *Generator) generateSingleRepoStructure(repoPath string, cfg *config.WorkflowConfig, bp *blueprint.Blueprint, data *blueprint.TemplateData, force bool) error {
+func (g *Generator) generateSingleRepoStructure(repoPath string, cfg *config.WorkflowConfig, bp *blueprint.Blueprint, force bool) error {
 	// Create .claude directory structure
 	claudePath := filepath.Join(repoPath, ".claude")
-	if err := g.generateClaudeDirectory(claudePath, bp, data, force); err != nil {
+	if err := g.generateClaudeDirectory(claudePath, cfg, bp, force); err != nil {
 		return err
 	}
 
@@ -218,7 +283,9 @@ func (g *Generator) generateSingleRepoStructure(repoPath string, cfg *config.Wor
 }
 
 // generateClaudeDirectory creates the .claude directory with all assets
-func (g *Generator) generateClaudeDirectory(claudePath string, bp *blueprint.Blueprint, data *blueprint.TemplateData, force bool) error {
+func (g *Generator) generateClaudeDirectory(claudePath string, cfg *config.WorkflowConfig, bp *blueprint.Blueprint, force bool) error {
+	data := blueprint.TemplateDataFor(cfg, "")
+
 	// Create subdirectories
 	dirs := []string{"agents", "commands", "hooks"}
 	for _, dir := range dirs {
@@ -227,9 +294,19 @@ func (g *Generator) generateClaudeDirectory(claudePath string, bp *blueprint.Blu
 		}
 	}
 
+	// Every file written from a template is recorded so upgrade can tell
+	// pristine files from edited ones
+	manifest := workspace.LoadManifest(claudePath)
+	record := func(relPath string, content []byte) error {
+		if err := workspace.RecordManaged(claudePath, manifest, bp.ID+"/"+relPath, relPath, content); err != nil {
+			return fmt.Errorf("failed to record %s: %w", relPath, err)
+		}
+		return nil
+	}
+
 	// Write agents
 	for _, agentName := range bp.Agents.Defaults {
-		content, err := g.bpManager.GetAgentContent(bp.ID, agentName, data)
+		content, err := g.bpManager.GetAgentContent(bp.ID, agentName, blueprint.TemplateDataFor(cfg, agentName))
 		if err != nil {
 			return fmt.Errorf("failed to get agent %s: %w", agentName, err)
 		}
@@ -237,6 +314,9 @@ func (g *Generator) generateClaudeDirectory(claudePath string, bp *blueprint.Blu
 		if err := util.SafeWriteFile(agentPath, content, force); err != nil {
 			return fmt.Errorf("failed to write agent %s: %w", agentName, err)
 		}
+		if err := record(filepath.Join("agents", agentName+".md"), content); err != nil {
+			return err
+		}
 	}
 
 	// Write commands
@@ -249,6 +329,9 @@ func (g *Generator) generateClaudeDirectory(claudePath string, bp *blueprint.Blu
 		if err := util.SafeWriteFile(cmdPath, content, force); err != nil {
 			return fmt.Errorf("failed to write command %s: %w", cmdName, err)
 		}
+		if err := record(filepath.Join("commands", cmdName+".md"), content); err != nil {
+			return err
+		}
 	}
 
 	// Write hooks
@@ -261,17 +344,32 @@ func (g *Generator) generateClaudeDirectory(claudePath string, bp *blueprint.Blu
 		if err := util.SafeWriteExecutable(hookPath, content, force); err != nil {
 			return fmt.Errorf("failed to write hook %s: %w", hookName, err)
 		}
+		if err := record(filepath.Join("hooks", hookName+".sh"), content); err != nil {
+			return err
+		}
 	}
 
-	// Write settings.json
-	settingsContent, err := g.bpManager.GetAsset(bp.ID, ".claude/settings.json")
+	// Write settings.json, registering the default hooks from the
+	// blueprint's hooks manifest
+	s, err := g.bpManager.RenderSettings(bp, data.HooksEnabled)
+	if err != nil {
+		return err
+	}
+	settingsContent, err := s.Marshal()
 	if err != nil {
-		return fmt.Errorf("failed to get settings.json: %w", err)
+		return fmt.Errorf("failed to encode settings.json: %w", err)
 	}
-	settingsPath := filepath.Join(claudePath, "settings.json")
+	settingsPath := filepath.Join(claudePath, settings.FileName)
 	if err := util.SafeWriteFile(settingsPath, settingsContent, force); err != nil {
 		return fmt.Errorf("failed to write settings.json: %w", err)
 	}
+	if err := workspace.SaveBase(claudePath, settings.FileName, settingsContent); err != nil {
+		return fmt.Errorf("failed to record settings.json: %w", err)
+	}
+
+	if err := workspace.SaveManifest(claudePath, manifest); err != nil {
+		return fmt.Errorf("failed to write %s: %w", workspace.ManifestFile, err)
+	}
 
 	return nil
 }
@@ -334,6 +432,11 @@ state:
 		cfg.Paths.Hub, cfg.Paths.Docs,
 		cfg.State.Root, cfg.State.StateDir, cfg.State.DesignsDir)
 
+	// Add the install mode if set; without it repos get symlinks
+	if cfg.Install.Mode != "" {
+		yaml += fmt.Sprintf("install:\n  mode: %s\n", cfg.Install.Mode)
+	}
+
 	// Add repos
 	yaml += "repos:\n"
 	for _, repo := range cfg.Repos {
@@ -377,6 +480,9 @@ parallel:
 				yaml += fmt.Sprintf("        - %s\n", agent)
 			}
 			yaml += fmt.Sprintf("      sync: %s\n", group.Sync)
+			if group.Phase != "" {
+				yaml += fmt.Sprintf("      phase: %s\n", group.Phase)
+			}
 		}
 	}
 
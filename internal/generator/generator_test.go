@@ -3,11 +3,14 @@ package generator
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Wameedh/ccflow/internal/blueprint"
 	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/installer"
 	"github.com/Wameedh/ccflow/internal/util"
+	"github.com/Wameedh/ccflow/internal/workspace"
 )
 
 func setupTestGenerator(t *testing.T) (*Generator, *blueprint.Manager) {
@@ -81,6 +84,22 @@ func TestGenerate_SingleRepo(t *testing.T) {
 		t.Error("workflow.yaml marker not created")
 	}
 
+	// Verify generated files are recorded as managed, with their base
+	manifest := workspace.LoadManifest(claudePath)
+	for _, agent := range []string{"product-agent", "go-subagent"} {
+		relPath := filepath.Join("agents", agent+".md")
+		content, _ := os.ReadFile(filepath.Join(claudePath, relPath))
+		info, ok := manifest.Files[relPath]
+		if !ok {
+			t.Errorf("%s not recorded in %s", relPath, workspace.ManifestFile)
+		} else if info.Hash != util.HashBytes(content) || info.TemplateID != "go-cli-dev/"+relPath {
+			t.Errorf("Unexpected manifest entry for %s: %+v", relPath, info)
+		}
+		if base, ok := workspace.LoadBase(claudePath, relPath); !ok || string(base) != string(content) {
+			t.Errorf("Expected base for %s to match the generated content", relPath)
+		}
+	}
+
 	// Verify docs structure
 	docsPath := filepath.Join(tmpDir, "docs", "workflow")
 	if !util.DirExists(docsPath) {
@@ -134,6 +153,17 @@ func TestGenerate_MultiRepo(t *testing.T) {
 	if !util.FileExists(markerPath) {
 		t.Error("workflow.yaml not created in hub")
 	}
+
+	// Verify .mcp.json in hub lists the chosen providers
+	mcpData, err := os.ReadFile(filepath.Join(hubPath, ".mcp.json"))
+	if err != nil {
+		t.Fatalf(".mcp.json not created in hub: %v", err)
+	}
+	for _, server := range []string{`"github"`, `"linear"`, `"https://mcp.linear.app/mcp"`} {
+		if !strings.Contains(string(mcpData), server) {
+			t.Errorf("Expected %s in .mcp.json, got %s", server, mcpData)
+		}
+	}
 }
 
 func TestCheckExistingFiles_NoExisting(t *testing.T) {
@@ -323,3 +353,25 @@ func TestCheckExistingFiles_MultiRepo(t *testing.T) {
 		t.Error("Failed to detect existing workflow.yaml in hub")
 	}
 }
+
+func TestSaveWorkflowConfig_InstallMode(t *testing.T) {
+	gen, _ := setupTestGenerator(t)
+
+	for _, mode := range []string{"", string(installer.InstallModeCopy)} {
+		cfg := config.NewDefaultWorkflowConfig("test-workflow")
+		cfg.Blueprint = "web-dev"
+		cfg.Install.Mode = mode
+
+		path := filepath.Join(t.TempDir(), "workflow.yaml")
+		if err := gen.SaveWorkflowConfig(path, cfg); err != nil {
+			t.Fatalf("SaveWorkflowConfig failed: %v", err)
+		}
+		loaded, err := workspace.LoadConfig(path)
+		if err != nil {
+			t.Fatalf("LoadConfig failed: %v", err)
+		}
+		if loaded.Install.Mode != mode {
+			t.Errorf("Expected install mode %q to round-trip, got %q", mode, loaded.Install.Mode)
+		}
+	}
+}
@@ -26,6 +26,17 @@ const (
 	InstallModeCopy InstallMode = "copy"
 )
 
+// ParseInstallMode parses an install mode, defaulting to symlink when empty
+func ParseInstallMode(s string) (InstallMode, error) {
+	switch InstallMode(s) {
+	case "", InstallModeSymlink:
+		return InstallModeSymlink, nil
+	case InstallModeCopy:
+		return InstallModeCopy, nil
+	}
+	return "", fmt.Errorf("invalid install mode: %s (must be symlink or copy)", s)
+}
+
 // InstallOptions contains options for installation
 type InstallOptions struct {
 	HubPath       string              // Path to the .claude directory in the hub
@@ -74,6 +85,13 @@ func (i *Installer) installToRepo(hubPath string, repo config.RepoConfig, worksp
 
 	// Check if .claude already exists
 	if util.FileExists(claudePath) || util.IsSymlink(claudePath) {
+		if mode == InstallModeCopy && IsCopyInstall(claudePath) && !force {
+			result.Success = true
+			result.Skipped = true
+			result.Message = "already copied from hub (run 'ccflow sync' to update)"
+			return result
+		}
+
 		if util.IsSymlink(claudePath) {
 			// Check if it points to our hub
 			target, err := util.ReadSymlinkTarget(claudePath)
@@ -138,11 +156,26 @@ func (i *Installer) calculateRelativePath(repoPath, hubPath string) string {
 	return rel
 }
 
-// copyDirectory copies a directory recursively
+// copyDirectory copies the hub into a repo and records what was copied,
+// so that later syncs can tell hub changes from local edits
 func (i *Installer) copyDirectory(src, dst string) error {
-	// This would be implemented for Windows support
-	// For now, return an error suggesting symlink mode
-	return fmt.Errorf("copy mode not yet implemented; use symlink mode on macOS/Linux")
+	files, err := hashTree(src)
+	if err != nil {
+		return fmt.Errorf("failed to read hub: %w", err)
+	}
+
+	manifest := newSyncManifest()
+	for relPath, hash := range files {
+		if err := util.CopyFile(filepath.Join(src, relPath), filepath.Join(dst, relPath)); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", relPath, err)
+		}
+		manifest.Files[filepath.ToSlash(relPath)] = hash
+	}
+
+	if err := saveSyncManifest(dst, manifest); err != nil {
+		return fmt.Errorf("failed to write sync manifest: %w", err)
+	}
+	return nil
 }
 
 // removeExisting removes an existing .claude (file, directory, or symlink)
@@ -188,6 +221,9 @@ func (i *Installer) VerifyInstallation(repoPath, hubPath string) (bool, string)
 	}
 
 	// It's a regular directory
+	if IsCopyInstall(claudePath) {
+		return true, "ok (copy)"
+	}
 	if util.DirExists(claudePath) {
 		return true, "ok (local)"
 	}
@@ -0,0 +1,280 @@
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/util"
+)
+
+// SyncManifestFile records the hub content last copied into a repo's .claude
+const SyncManifestFile = ".ccflow-sync.json"
+
+// SyncManifest maps each copied file (slash-separated, relative to .claude)
+// to the hash of the hub content it was last synced from
+type SyncManifest struct {
+	Version int               `json:"version"`
+	Files   map[string]string `json:"files"`
+}
+
+// SyncAction describes what sync does with a single file
+type SyncAction string
+
+const (
+	// SyncAdd copies a file that is new in the hub
+	SyncAdd SyncAction = "add"
+	// SyncUpdate overwrites an unmodified copy with the hub's newer content
+	SyncUpdate SyncAction = "update"
+	// SyncRemove deletes an unmodified copy of a file removed from the hub
+	SyncRemove SyncAction = "remove"
+	// SyncLocalEdit keeps a file edited in the repo while the hub is unchanged
+	SyncLocalEdit SyncAction = "local-edit"
+	// SyncConflict reports a file changed both in the hub and in the repo
+	SyncConflict SyncAction = "conflict"
+)
+
+// SyncChange is a single file considered by sync
+type SyncChange struct {
+	Path   string // relative to .claude
+	Action SyncAction
+}
+
+// SyncOptions contains options for syncing copied installs
+type SyncOptions struct {
+	HubPath       string              // Path to the .claude directory in the hub
+	Repos         []config.RepoConfig // Repositories to sync
+	WorkspacePath string              // Root workspace path
+	DryRun        bool                // Report changes without writing
+	Force         bool                // Overwrite conflicts and local edits with hub content
+}
+
+// SyncResult represents the result of syncing a single repo
+type SyncResult struct {
+	RepoName string
+	RepoPath string
+	Changes  []SyncChange
+	Skipped  bool
+	Message  string
+	Error    error
+}
+
+// Conflicts returns the paths that changed in both the hub and the repo
+func (r SyncResult) Conflicts() []string {
+	var paths []string
+	for _, c := range r.Changes {
+		if c.Action == SyncConflict {
+			paths = append(paths, c.Path)
+		}
+	}
+	return paths
+}
+
+// IsCopyInstall reports whether claudePath is a directory copied from the hub
+func IsCopyInstall(claudePath string) bool {
+	return !util.IsSymlink(claudePath) && util.FileExists(filepath.Join(claudePath, SyncManifestFile))
+}
+
+// Sync pushes hub changes into every repo that has a copied .claude.
+// Files edited locally are kept, and files changed on both sides are
+// reported as conflicts rather than overwritten, unless Force is set.
+func (i *Installer) Sync(opts SyncOptions) []SyncResult {
+	var results []SyncResult
+
+	for _, repo := range opts.Repos {
+		results = append(results, i.syncRepo(opts, repo))
+	}
+
+	return results
+}
+
+// syncRepo syncs a single repository
+func (i *Installer) syncRepo(opts SyncOptions, repo config.RepoConfig) SyncResult {
+	result := SyncResult{
+		RepoName: repo.Name,
+		RepoPath: filepath.Join(opts.WorkspacePath, repo.Path),
+	}
+	claudePath := filepath.Join(result.RepoPath, ".claude")
+
+	switch {
+	case util.IsSymlink(claudePath):
+		result.Skipped = true
+		result.Message = "symlinked to hub (nothing to sync)"
+		return result
+	case !util.DirExists(claudePath):
+		result.Error = fmt.Errorf(".claude is not installed in %s", repo.Name)
+		return result
+	case !IsCopyInstall(claudePath):
+		result.Error = fmt.Errorf(".claude in %s was not copied by ccflow (reinstall with --force)", repo.Name)
+		return result
+	}
+
+	manifest, err := loadSyncManifest(claudePath)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	hubFiles, err := hashTree(opts.HubPath)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to read hub: %w", err)
+		return result
+	}
+	localFiles, err := hashTree(claudePath)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to read %s: %w", claudePath, err)
+		return result
+	}
+
+	// Consider every file the hub has or the last sync copied; files only
+	// created locally are left alone
+	paths := make(map[string]bool)
+	for p := range hubFiles {
+		paths[filepath.ToSlash(p)] = true
+	}
+	for p := range manifest.Files {
+		paths[p] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	for _, p := range sorted {
+		rel := filepath.FromSlash(p)
+		hub, base, local := hubFiles[rel], manifest.Files[p], localFiles[rel]
+
+		action, ok := classify(hub, base, local)
+		if !ok {
+			// Already identical; just remember the hub content
+			if hub == "" {
+				delete(manifest.Files, p)
+			} else {
+				manifest.Files[p] = hub
+			}
+			continue
+		}
+		result.Changes = append(result.Changes, SyncChange{Path: p, Action: action})
+
+		if opts.DryRun || ((action == SyncLocalEdit || action == SyncConflict) && !opts.Force) {
+			continue
+		}
+
+		dst := filepath.Join(claudePath, rel)
+		if hub == "" {
+			if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+				result.Error = fmt.Errorf("failed to remove %s: %w", p, err)
+				return result
+			}
+			delete(manifest.Files, p)
+			continue
+		}
+		if err := util.CopyFile(filepath.Join(opts.HubPath, rel), dst); err != nil {
+			result.Error = fmt.Errorf("failed to copy %s: %w", p, err)
+			return result
+		}
+		manifest.Files[p] = hub
+	}
+
+	if !opts.DryRun {
+		if err := saveSyncManifest(claudePath, manifest); err != nil {
+			result.Error = fmt.Errorf("failed to write sync manifest: %w", err)
+			return result
+		}
+	}
+
+	if len(result.Changes) == 0 {
+		result.Message = "up to date"
+	}
+	return result
+}
+
+// classify decides what to do with a file given its hub, last-synced and
+// local hashes (empty when absent). It returns false when nothing needs doing.
+func classify(hub, base, local string) (SyncAction, bool) {
+	switch {
+	case hub == local:
+		return "", false
+	case local == base:
+		// Unmodified locally: take the hub's version
+		if hub == "" {
+			return SyncRemove, true
+		}
+		if local == "" {
+			return SyncAdd, true
+		}
+		return SyncUpdate, true
+	case hub == base:
+		return SyncLocalEdit, true
+	default:
+		return SyncConflict, true
+	}
+}
+
+// hashTree returns the hash of every regular file under root, keyed by
+// OS-specific path relative to root, excluding the sync manifest
+func hashTree(root string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if relPath == SyncManifestFile {
+			return nil
+		}
+
+		hash, err := util.HashFileContent(path)
+		if err != nil {
+			return err
+		}
+		files[relPath] = hash
+		return nil
+	})
+	return files, err
+}
+
+// newSyncManifest creates an empty sync manifest
+func newSyncManifest() *SyncManifest {
+	return &SyncManifest{
+		Version: 1,
+		Files:   make(map[string]string),
+	}
+}
+
+// loadSyncManifest reads the sync manifest from a copied .claude directory
+func loadSyncManifest(claudePath string) (*SyncManifest, error) {
+	data, err := os.ReadFile(filepath.Join(claudePath, SyncManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync manifest: %w", err)
+	}
+
+	manifest := newSyncManifest()
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse sync manifest: %w", err)
+	}
+	if manifest.Files == nil {
+		manifest.Files = make(map[string]string)
+	}
+	return manifest, nil
+}
+
+// saveSyncManifest writes the sync manifest into a copied .claude directory
+func saveSyncManifest(claudePath string, manifest *SyncManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(claudePath, SyncManifestFile), data, 0644)
+}
@@ -0,0 +1,221 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/util"
+)
+
+// setupCopyInstall creates a hub with the given files and copies it into repo
+func setupCopyInstall(t *testing.T, files map[string]string) (tmpDir, hubPath, claudePath string) {
+	t.Helper()
+	tmpDir = t.TempDir()
+
+	hubPath = filepath.Join(tmpDir, "hub", ".claude")
+	for rel, content := range files {
+		writeFile(t, filepath.Join(hubPath, rel), content)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, "repo"), 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+
+	results := New().Install(InstallOptions{
+		HubPath:       hubPath,
+		Repos:         []config.RepoConfig{{Name: "repo", Path: "repo"}},
+		WorkspacePath: tmpDir,
+		Mode:          InstallModeCopy,
+	})
+	if !results[0].Success {
+		t.Fatalf("Copy install failed: %v", results[0].Error)
+	}
+
+	return tmpDir, hubPath, filepath.Join(tmpDir, "repo", ".claude")
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(content)
+}
+
+func TestInstall_Copy(t *testing.T) {
+	tmpDir, hubPath, claudePath := setupCopyInstall(t, map[string]string{
+		"settings.json":   `{"hub": true}`,
+		"agents/agent.md": "agent",
+	})
+
+	if util.IsSymlink(claudePath) {
+		t.Error(".claude should be a directory in copy mode")
+	}
+	if got := readFile(t, filepath.Join(claudePath, "agents", "agent.md")); got != "agent" {
+		t.Errorf("Unexpected copied content: %q", got)
+	}
+	if !IsCopyInstall(claudePath) {
+		t.Error("Expected sync manifest to be written")
+	}
+
+	ok, status := New().VerifyInstallation(filepath.Join(tmpDir, "repo"), hubPath)
+	if !ok || status != "ok (copy)" {
+		t.Errorf("Expected 'ok (copy)', got %v %s", ok, status)
+	}
+
+	// Installing again is a no-op
+	results := New().Install(InstallOptions{
+		HubPath:       hubPath,
+		Repos:         []config.RepoConfig{{Name: "repo", Path: "repo"}},
+		WorkspacePath: tmpDir,
+		Mode:          InstallModeCopy,
+	})
+	if !results[0].Success || !results[0].Skipped {
+		t.Errorf("Expected reinstall to be skipped, got %+v", results[0])
+	}
+}
+
+func TestSync(t *testing.T) {
+	tmpDir, hubPath, claudePath := setupCopyInstall(t, map[string]string{
+		"settings.json":         "settings v1",
+		"agents/updated.md":     "updated v1",
+		"agents/local.md":       "local v1",
+		"agents/conflict.md":    "conflict v1",
+		"agents/removed.md":     "removed v1",
+		"commands/unchanged.md": "unchanged",
+	})
+
+	// Hub changes
+	writeFile(t, filepath.Join(hubPath, "agents", "updated.md"), "updated v2")
+	writeFile(t, filepath.Join(hubPath, "agents", "conflict.md"), "conflict hub")
+	writeFile(t, filepath.Join(hubPath, "agents", "added.md"), "added")
+	if err := os.Remove(filepath.Join(hubPath, "agents", "removed.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Local changes
+	writeFile(t, filepath.Join(claudePath, "agents", "local.md"), "local edit")
+	writeFile(t, filepath.Join(claudePath, "agents", "conflict.md"), "conflict local")
+	writeFile(t, filepath.Join(claudePath, "notes.md"), "untracked")
+
+	opts := SyncOptions{
+		HubPath:       hubPath,
+		Repos:         []config.RepoConfig{{Name: "repo", Path: "repo"}},
+		WorkspacePath: tmpDir,
+	}
+
+	want := map[string]SyncAction{
+		"agents/added.md":    SyncAdd,
+		"agents/conflict.md": SyncConflict,
+		"agents/local.md":    SyncLocalEdit,
+		"agents/removed.md":  SyncRemove,
+		"agents/updated.md":  SyncUpdate,
+	}
+	checkChanges := func(result SyncResult) {
+		t.Helper()
+		if result.Error != nil {
+			t.Fatalf("Sync failed: %v", result.Error)
+		}
+		if len(result.Changes) != len(want) {
+			t.Errorf("Expected %d changes, got %v", len(want), result.Changes)
+		}
+		for _, c := range result.Changes {
+			if want[c.Path] != c.Action {
+				t.Errorf("%s: expected %s, got %s", c.Path, want[c.Path], c.Action)
+			}
+		}
+	}
+
+	// Dry run reports but writes nothing
+	opts.DryRun = true
+	checkChanges(New().Sync(opts)[0])
+	if got := readFile(t, filepath.Join(claudePath, "agents", "updated.md")); got != "updated v1" {
+		t.Errorf("Dry run modified a file: %q", got)
+	}
+
+	opts.DryRun = false
+	result := New().Sync(opts)[0]
+	checkChanges(result)
+	if conflicts := result.Conflicts(); len(conflicts) != 1 || conflicts[0] != "agents/conflict.md" {
+		t.Errorf("Unexpected conflicts: %v", conflicts)
+	}
+
+	expected := map[string]string{
+		"agents/updated.md":  "updated v2",
+		"agents/added.md":    "added",
+		"agents/local.md":    "local edit",
+		"agents/conflict.md": "conflict local",
+		"notes.md":           "untracked",
+	}
+	for rel, content := range expected {
+		if got := readFile(t, filepath.Join(claudePath, filepath.FromSlash(rel))); got != content {
+			t.Errorf("%s = %q, want %q", rel, got, content)
+		}
+	}
+	if util.FileExists(filepath.Join(claudePath, "agents", "removed.md")) {
+		t.Error("Expected removed.md to be deleted")
+	}
+
+	// Conflicts and local edits persist until forced
+	want = map[string]SyncAction{
+		"agents/conflict.md": SyncConflict,
+		"agents/local.md":    SyncLocalEdit,
+	}
+	checkChanges(New().Sync(opts)[0])
+
+	opts.Force = true
+	checkChanges(New().Sync(opts)[0])
+	if got := readFile(t, filepath.Join(claudePath, "agents", "conflict.md")); got != "conflict hub" {
+		t.Errorf("Force did not take hub content: %q", got)
+	}
+
+	opts.Force = false
+	if result := New().Sync(opts)[0]; result.Message != "up to date" {
+		t.Errorf("Expected up to date, got %+v", result)
+	}
+}
+
+func TestSync_SkipsSymlinkAndRejectsForeignDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	hubPath := filepath.Join(tmpDir, "hub", ".claude")
+	writeFile(t, filepath.Join(hubPath, "settings.json"), "{}")
+
+	linked := filepath.Join(tmpDir, "linked")
+	if err := os.MkdirAll(linked, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := util.CreateRelativeSymlink(hubPath, filepath.Join(linked, ".claude")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, "foreign", ".claude"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	results := New().Sync(SyncOptions{
+		HubPath: hubPath,
+		Repos: []config.RepoConfig{
+			{Name: "linked", Path: "linked"},
+			{Name: "foreign", Path: "foreign"},
+		},
+		WorkspacePath: tmpDir,
+	})
+
+	if !results[0].Skipped {
+		t.Errorf("Expected symlinked repo to be skipped, got %+v", results[0])
+	}
+	if results[1].Error == nil {
+		t.Error("Expected error for .claude not copied by ccflow")
+	}
+}
@@ -0,0 +1,152 @@
+// Package jsonobj reads and rewrites JSON objects without losing their key
+// order or the keys the caller doesn't model, so that files ccflow updates
+// keep the user's layout.
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// Object is a JSON object that remembers its key order
+type Object struct {
+	keys   []string
+	values map[string]json.RawMessage
+	order  []string // preferred order for new keys, see SetKeyOrder
+}
+
+// New returns an empty object
+func New() *Object {
+	return &Object{values: make(map[string]json.RawMessage)}
+}
+
+// Parse decodes a JSON object, recording key order
+func Parse(data []byte) (*Object, error) {
+	o := New()
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key := tok.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		if _, dup := o.values[key]; !dup {
+			o.keys = append(o.keys, key)
+		}
+		o.values[key] = raw
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// SetKeyOrder makes Set insert a new key before the first key that follows
+// it in order, instead of at the end. Keys not in order still go at the end.
+func (o *Object) SetKeyOrder(order []string) {
+	o.order = order
+}
+
+// Keys returns the keys in order
+func (o *Object) Keys() []string {
+	return o.keys
+}
+
+// Len returns the number of keys
+func (o *Object) Len() int {
+	return len(o.keys)
+}
+
+// Get returns the encoded value of a key
+func (o *Object) Get(key string) (json.RawMessage, bool) {
+	raw, ok := o.values[key]
+	return raw, ok
+}
+
+// Clone copies the object; a nil object clones to an empty one
+func (o *Object) Clone() *Object {
+	if o == nil {
+		return New()
+	}
+	c := &Object{keys: slices.Clone(o.keys), values: make(map[string]json.RawMessage, len(o.values)), order: o.order}
+	for k, v := range o.values {
+		c.values[k] = v
+	}
+	return c
+}
+
+// Set encodes and stores a value, in place for an existing key
+func (o *Object) Set(key string, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	if _, ok := o.values[key]; !ok {
+		o.insertKey(key)
+	}
+	o.values[key] = raw
+	return nil
+}
+
+// SetUnlessEmpty sets key when the value is non-empty or the key was already
+// present (so an empty "env": {} stays as written); otherwise it is omitted
+func (o *Object) SetUnlessEmpty(key string, value interface{}, nonEmpty bool) error {
+	if _, present := o.values[key]; !nonEmpty && !present {
+		return nil
+	}
+	return o.Set(key, value)
+}
+
+// Delete removes a key
+func (o *Object) Delete(key string) {
+	delete(o.values, key)
+	o.keys = slices.DeleteFunc(o.keys, func(k string) bool { return k == key })
+}
+
+// insertKey adds a key before the first key that follows it in the key
+// order, or at the end
+func (o *Object) insertKey(key string) {
+	rank := slices.Index(o.order, key)
+	at := len(o.keys)
+	if rank >= 0 {
+		for i, k := range o.keys {
+			if slices.Index(o.order, k) > rank {
+				at = i
+				break
+			}
+		}
+	}
+	o.keys = slices.Insert(o.keys, at, key)
+}
+
+// Marshal encodes the object compactly in key order
+func (o *Object) Marshal() []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(k)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(o.values[k])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
@@ -0,0 +1,72 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestParse_KeepsOrder(t *testing.T) {
+	o, err := Parse([]byte(`{"zeta": 1, "alpha": {"b": 2, "a": 1}, "mid": "x"}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if !slices.Equal(o.Keys(), []string{"zeta", "alpha", "mid"}) {
+		t.Errorf("Unexpected keys: %v", o.Keys())
+	}
+
+	if err := o.Set("alpha", 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Set("new", true); err != nil {
+		t.Fatal(err)
+	}
+	o.Delete("zeta")
+	if got := string(o.Marshal()); got != `{"alpha":3,"mid":"x","new":true}` {
+		t.Errorf("Unexpected encoding: %s", got)
+	}
+
+	if _, err := Parse([]byte(`[1, 2]`)); err == nil {
+		t.Error("Expected an error for a JSON array")
+	}
+}
+
+func TestSetKeyOrder(t *testing.T) {
+	o, err := Parse([]byte(`{"custom": 1, "hooks": {}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.SetKeyOrder([]string{"$schema", "permissions", "env", "hooks"})
+
+	// New keys go before the first key that follows them, unknown ones last
+	for _, key := range []string{"env", "$schema", "other"} {
+		if err := o.Set(key, json.RawMessage(`{}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := []string{"custom", "$schema", "env", "hooks", "other"}; !slices.Equal(o.Keys(), want) {
+		t.Errorf("Expected keys %v, got %v", want, o.Keys())
+	}
+}
+
+func TestSetUnlessEmptyAndClone(t *testing.T) {
+	o, err := Parse([]byte(`{"env": {}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := o.Clone()
+	c.SetUnlessEmpty("env", map[string]string{}, false) // Kept, as it was present
+	c.SetUnlessEmpty("deny", []string{}, false)         // Omitted
+	c.SetUnlessEmpty("allow", []string{"Read"}, true)   // Set
+	if got := string(c.Marshal()); got != `{"env":{},"allow":["Read"]}` {
+		t.Errorf("Unexpected encoding: %s", got)
+	}
+	if o.Len() != 1 {
+		t.Errorf("Clone changed the original: %s", o.Marshal())
+	}
+
+	var none *Object
+	if none.Clone().Len() != 0 {
+		t.Error("Expected a nil object to clone to an empty one")
+	}
+}
@@ -0,0 +1,310 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/jsonobj"
+	"github.com/Wameedh/ccflow/internal/util"
+)
+
+// FileName is the project MCP configuration read by Claude Code
+const FileName = ".mcp.json"
+
+// Provider categories, matching the keys under mcp in workflow.yaml
+const (
+	CategoryVCS     = "vcs"
+	CategoryTracker = "tracker"
+	CategoryDeploy  = "deploy"
+)
+
+// Server is an entry under mcpServers in .mcp.json: a local command, or a
+// remote server of the given type at URL
+type Server struct {
+	Type    string            `json:"type,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+}
+
+// Provider is an MCP server ccflow knows how to configure
+type Provider struct {
+	Name     string
+	Category string
+	Server   Server
+}
+
+// EnvVars returns the environment variables the server reads, sorted
+func (p Provider) EnvVars() []string {
+	var vars []string
+	for name := range p.Server.Env {
+		vars = append(vars, name)
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+// Setup returns what the user does before the server works: set its
+// environment variables, or sign in to a remote server from Claude Code
+func (p Provider) Setup() string {
+	if vars := p.EnvVars(); len(vars) > 0 {
+		return "set " + strings.Join(vars, ", ")
+	}
+	if p.Server.URL != "" {
+		return "sign in with /mcp in Claude Code"
+	}
+	return ""
+}
+
+// Location returns the command that starts the server, or its URL
+func (p Provider) Location() string {
+	if p.Server.URL != "" {
+		return p.Server.URL
+	}
+	return strings.Join(append([]string{p.Server.Command}, p.Server.Args...), " ")
+}
+
+// env maps each variable to a ${VAR} placeholder, which Claude Code expands
+// from the environment so secrets never land in .mcp.json
+func env(vars ...string) map[string]string {
+	m := make(map[string]string, len(vars))
+	for _, v := range vars {
+		m[v] = "${" + v + "}"
+	}
+	return m
+}
+
+// Providers lists the supported providers in display order
+var Providers = []Provider{
+	{string(config.VCSGitHub), CategoryVCS, Server{
+		Command: "npx",
+		Args:    []string{"-y", "@modelcontextprotocol/server-github"},
+		Env:     env("GITHUB_PERSONAL_ACCESS_TOKEN"),
+	}},
+	{string(config.VCSGitLab), CategoryVCS, Server{
+		Command: "npx",
+		Args:    []string{"-y", "@modelcontextprotocol/server-gitlab"},
+		Env:     env("GITLAB_PERSONAL_ACCESS_TOKEN", "GITLAB_API_URL"),
+	}},
+	// Linear's hosted server; Claude Code signs in with OAuth
+	{string(config.TrackerLinear), CategoryTracker, Server{
+		Type: "http",
+		URL:  "https://mcp.linear.app/mcp",
+	}},
+	{string(config.TrackerJira), CategoryTracker, Server{
+		Command: "uvx",
+		Args:    []string{"mcp-atlassian"},
+		Env:     env("JIRA_URL", "JIRA_USERNAME", "JIRA_API_TOKEN"),
+	}},
+	{string(config.DeployArgoCD), CategoryDeploy, Server{
+		Command: "npx",
+		Args:    []string{"-y", "argocd-mcp@latest", "stdio"},
+		Env:     env("ARGOCD_BASE_URL", "ARGOCD_API_TOKEN"),
+	}},
+}
+
+// previousServers lists entries earlier ccflow versions generated that no
+// longer work. Apply replaces them with the provider's current server.
+var previousServers = map[string][]Server{
+	string(config.TrackerLinear): {{
+		Command: "npx",
+		Args:    []string{"-y", "@modelcontextprotocol/server-linear"},
+		Env:     env("LINEAR_API_KEY"),
+	}},
+}
+
+// Lookup returns the provider with the given name
+func Lookup(name string) (Provider, bool) {
+	for _, p := range Providers {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Provider{}, false
+}
+
+// Names returns the names of all supported providers
+func Names() []string {
+	names := make([]string, len(Providers))
+	for i, p := range Providers {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// Selected returns the providers chosen in the MCP preferences
+func Selected(cfg config.MCPConfig) []Provider {
+	var selected []Provider
+	for _, name := range []string{string(cfg.VCS), string(cfg.Tracker), string(cfg.Deploy)} {
+		if p, ok := Lookup(name); ok {
+			selected = append(selected, p)
+		}
+	}
+	return selected
+}
+
+// Select records a provider in the MCP preferences, replacing any other
+// provider of the same category
+func Select(cfg *config.MCPConfig, name string) (Provider, error) {
+	p, ok := Lookup(name)
+	if !ok {
+		return Provider{}, fmt.Errorf("unknown MCP provider: %s (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	switch p.Category {
+	case CategoryVCS:
+		cfg.VCS = config.VCSProvider(name)
+	case CategoryTracker:
+		cfg.Tracker = config.TrackerProvider(name)
+	case CategoryDeploy:
+		cfg.Deploy = config.DeployProvider(name)
+	}
+	return p, nil
+}
+
+// Deselect sets the provider's category to none in the MCP preferences
+func Deselect(cfg *config.MCPConfig, name string) (Provider, error) {
+	p, ok := Lookup(name)
+	if !ok {
+		return Provider{}, fmt.Errorf("unknown MCP provider: %s (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	for _, s := range Selected(*cfg) {
+		if s.Name == name {
+			switch p.Category {
+			case CategoryVCS:
+				cfg.VCS = config.VCSNone
+			case CategoryTracker:
+				cfg.Tracker = config.TrackerNone
+			case CategoryDeploy:
+				cfg.Deploy = config.DeployNone
+			}
+			return p, nil
+		}
+	}
+	return Provider{}, fmt.Errorf("MCP provider %s is not configured", name)
+}
+
+// ProjectRoots returns the directories that get a .mcp.json: the repo root in
+// single-repo workflows; the hub and every existing repo in multi-repo ones,
+// since Claude Code reads .mcp.json from the project it is started in
+func ProjectRoots(workspacePath string, cfg *config.WorkflowConfig) []string {
+	if cfg.Topology != config.TopologyMultiRepo {
+		return []string{workspacePath}
+	}
+
+	roots := []string{filepath.Join(workspacePath, cfg.Paths.Hub)}
+	for _, repo := range cfg.Repos {
+		repoPath := filepath.Join(workspacePath, repo.Path)
+		if util.DirExists(repoPath) {
+			roots = append(roots, repoPath)
+		}
+	}
+	return roots
+}
+
+// Change describes what Apply did, or would do, to one .mcp.json
+type Change struct {
+	Path    string
+	Added   []string
+	Updated []string // entries an earlier ccflow generated, replaced
+	Removed []string
+}
+
+// Changed reports whether the file was (or would be) modified
+func (c Change) Changed() bool {
+	return len(c.Added) > 0 || len(c.Updated) > 0 || len(c.Removed) > 0
+}
+
+// Apply brings the .mcp.json in dir in line with the MCP preferences.
+// Servers for selected providers are added when missing, and replaced when
+// an earlier ccflow generated them; servers for other providers are removed
+// only while they still match what ccflow generated. Other servers and
+// top-level keys are left alone, in the order they were written. No file is
+// created when there is nothing to add.
+func Apply(dir string, cfg config.MCPConfig, dryRun bool) (Change, error) {
+	path := filepath.Join(dir, FileName)
+	change := Change{Path: path}
+
+	doc := jsonobj.New()
+	servers := jsonobj.New()
+	if data, err := os.ReadFile(path); err == nil {
+		if doc, err = jsonobj.Parse(data); err != nil {
+			return change, fmt.Errorf("invalid JSON in %s: %w", path, err)
+		}
+		if raw, ok := doc.Get("mcpServers"); ok {
+			if servers, err = jsonobj.Parse(raw); err != nil {
+				return change, fmt.Errorf("invalid mcpServers in %s: %w", path, err)
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return change, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	selected := make(map[string]bool)
+	for _, p := range Selected(cfg) {
+		selected[p.Name] = true
+	}
+
+	for _, p := range Providers {
+		raw, exists := servers.Get(p.Name)
+		switch {
+		case selected[p.Name] && (!exists || isPrevious(raw, p.Name)):
+			if err := servers.Set(p.Name, p.Server); err != nil {
+				return change, err
+			}
+			if exists {
+				change.Updated = append(change.Updated, p.Name)
+			} else {
+				change.Added = append(change.Added, p.Name)
+			}
+		case !selected[p.Name] && exists && (isGenerated(raw, p.Server) || isPrevious(raw, p.Name)):
+			servers.Delete(p.Name)
+			change.Removed = append(change.Removed, p.Name)
+		}
+	}
+
+	if !change.Changed() || dryRun {
+		return change, nil
+	}
+
+	if err := doc.Set("mcpServers", json.RawMessage(servers.Marshal())); err != nil {
+		return change, err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, doc.Marshal(), "", "  "); err != nil {
+		return change, err
+	}
+	buf.WriteByte('\n')
+
+	if err := util.SafeWriteFile(path, buf.Bytes(), true); err != nil {
+		return change, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return change, nil
+}
+
+// isGenerated reports whether a server entry is the one ccflow writes
+func isGenerated(raw json.RawMessage, want Server) bool {
+	var got Server
+	if err := json.Unmarshal(raw, &got); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(got, want)
+}
+
+// isPrevious reports whether a server entry is one an earlier ccflow wrote
+// for the provider
+func isPrevious(raw json.RawMessage, name string) bool {
+	for _, server := range previousServers[name] {
+		if isGenerated(raw, server) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+package mcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Wameedh/ccflow/internal/config"
+)
+
+// readServers returns the mcpServers in dir's .mcp.json
+func readServers(t *testing.T, dir string) map[string]Server {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", FileName, err)
+	}
+	var doc struct {
+		MCPServers map[string]Server `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Invalid %s: %v", FileName, err)
+	}
+	return doc.MCPServers
+}
+
+func TestApply(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.MCPConfig{VCS: config.VCSGitHub, Tracker: config.TrackerLinear, Deploy: config.DeployNone}
+
+	change, err := Apply(dir, cfg, false)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if strings.Join(change.Added, ",") != "github,linear" {
+		t.Errorf("Expected github and linear added, got %+v", change)
+	}
+
+	servers := readServers(t, dir)
+	github := servers["github"]
+	if github.Command != "npx" || github.Env["GITHUB_PERSONAL_ACCESS_TOKEN"] != "${GITHUB_PERSONAL_ACCESS_TOKEN}" {
+		t.Errorf("Unexpected github server: %+v", github)
+	}
+
+	// Applying again changes nothing
+	if change, _ := Apply(dir, cfg, false); change.Changed() {
+		t.Errorf("Expected no changes, got %+v", change)
+	}
+
+	// Switching trackers swaps the server
+	cfg.Tracker = config.TrackerJira
+	change, err = Apply(dir, cfg, false)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if strings.Join(change.Added, ",") != "jira" || strings.Join(change.Removed, ",") != "linear" {
+		t.Errorf("Expected jira added and linear removed, got %+v", change)
+	}
+	servers = readServers(t, dir)
+	if _, ok := servers["linear"]; ok {
+		t.Error("linear should be removed")
+	}
+	if _, ok := servers["jira"]; !ok {
+		t.Error("jira should be added")
+	}
+}
+
+func TestApply_PreservesUserEntries(t *testing.T) {
+	dir := t.TempDir()
+	existing := `{
+  "mcpServers": {
+    "custom": {"command": "my-server"},
+    "linear": {"command": "linear-mcp", "env": {"LINEAR_API_KEY": "${MY_KEY}"}}
+  },
+  "extra": {"keep": true}
+}`
+	os.WriteFile(filepath.Join(dir, FileName), []byte(existing), 0644)
+
+	// linear is not selected, but the entry was customized, so it stays
+	cfg := config.MCPConfig{VCS: config.VCSGitHub, Tracker: config.TrackerNone}
+	change, err := Apply(dir, cfg, false)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if len(change.Removed) != 0 {
+		t.Errorf("Customized server should not be removed: %+v", change)
+	}
+
+	servers := readServers(t, dir)
+	for _, name := range []string{"custom", "linear", "github"} {
+		if _, ok := servers[name]; !ok {
+			t.Errorf("Expected server %s", name)
+		}
+	}
+	if servers["linear"].Command != "linear-mcp" {
+		t.Errorf("Customized server was rewritten: %+v", servers["linear"])
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, FileName))
+	if !strings.Contains(string(data), `"keep": true`) {
+		t.Errorf("Unknown keys should be preserved, got %s", data)
+	}
+}
+
+func TestApply_KeepsOrderAndReplacesPrevious(t *testing.T) {
+	dir := t.TempDir()
+	existing := `{
+  "zeta": 1,
+  "mcpServers": {
+    "custom": {"command": "my-server"},
+    "linear": {"command": "npx", "args": ["-y", "@modelcontextprotocol/server-linear"], "env": {"LINEAR_API_KEY": "${LINEAR_API_KEY}"}},
+    "another": {"command": "other-server"}
+  },
+  "alpha": 2
+}`
+	os.WriteFile(filepath.Join(dir, FileName), []byte(existing), 0644)
+
+	cfg := config.MCPConfig{VCS: config.VCSGitHub, Tracker: config.TrackerLinear}
+	change, err := Apply(dir, cfg, false)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if strings.Join(change.Added, ",") != "github" || strings.Join(change.Updated, ",") != "linear" {
+		t.Errorf("Expected github added and linear updated, got %+v", change)
+	}
+
+	servers := readServers(t, dir)
+	if linear := servers["linear"]; linear.URL != "https://mcp.linear.app/mcp" || linear.Command != "" {
+		t.Errorf("Expected the hosted Linear server, got %+v", linear)
+	}
+
+	// Keys stay where they were; new servers follow
+	data, _ := os.ReadFile(filepath.Join(dir, FileName))
+	order := []string{`"zeta"`, `"mcpServers"`, `"custom"`, `"linear"`, `"another"`, `"github"`, `"alpha"`}
+	last := -1
+	for _, key := range order {
+		i := strings.Index(string(data), key)
+		if i < last {
+			t.Errorf("Expected %s after the keys before it:\n%s", key, data)
+		}
+		last = i
+	}
+
+	// A server an earlier ccflow generated is removed once deselected
+	os.WriteFile(filepath.Join(dir, FileName), []byte(existing), 0644)
+	change, err = Apply(dir, config.MCPConfig{Tracker: config.TrackerNone}, false)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if strings.Join(change.Removed, ",") != "linear" {
+		t.Errorf("Expected linear removed, got %+v", change)
+	}
+}
+
+func TestApply_DryRunAndNoFile(t *testing.T) {
+	dir := t.TempDir()
+
+	// Nothing selected: no file is created
+	if _, err := Apply(dir, config.MCPConfig{VCS: config.VCSNone}, false); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, FileName)); !os.IsNotExist(err) {
+		t.Error("Expected no .mcp.json when no providers are selected")
+	}
+
+	change, err := Apply(dir, config.MCPConfig{VCS: config.VCSGitLab}, true)
+	if err != nil || !change.Changed() {
+		t.Errorf("Expected pending change, got %+v %v", change, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, FileName)); !os.IsNotExist(err) {
+		t.Error("Dry run should not write .mcp.json")
+	}
+
+	os.WriteFile(filepath.Join(dir, FileName), []byte("{not json"), 0644)
+	if _, err := Apply(dir, config.MCPConfig{VCS: config.VCSGitLab}, false); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestSelectDeselect(t *testing.T) {
+	cfg := config.MCPConfig{VCS: config.VCSGitHub, Tracker: config.TrackerNone, Deploy: config.DeployNone}
+
+	if _, err := Select(&cfg, "gitlab"); err != nil || cfg.VCS != config.VCSGitLab {
+		t.Errorf("Expected gitlab to replace github, got %+v %v", cfg, err)
+	}
+	if _, err := Select(&cfg, "argocd"); err != nil || cfg.Deploy != config.DeployArgoCD {
+		t.Errorf("Expected argocd deploy, got %+v %v", cfg, err)
+	}
+	if _, err := Select(&cfg, "bitbucket"); err == nil {
+		t.Error("Expected error for unknown provider")
+	}
+
+	if _, err := Deselect(&cfg, "linear"); err == nil {
+		t.Error("Expected error removing a provider that is not configured")
+	}
+	if _, err := Deselect(&cfg, "gitlab"); err != nil || cfg.VCS != config.VCSNone {
+		t.Errorf("Expected vcs none, got %+v %v", cfg, err)
+	}
+
+	if names := Selected(cfg); len(names) != 1 || names[0].Name != "argocd" {
+		t.Errorf("Expected only argocd selected, got %+v", names)
+	}
+}
+
+func TestProjectRoots(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "web"), 0755)
+
+	cfg := config.NewDefaultWorkflowConfig("test")
+	cfg.Repos = []config.RepoConfig{
+		{Name: "web", Path: "web", Kind: config.RepoKindNode},
+		{Name: "api", Path: "api", Kind: config.RepoKindGo},
+	}
+
+	roots := ProjectRoots(root, cfg)
+	want := []string{filepath.Join(root, "workflow-hub"), filepath.Join(root, "web")}
+	if strings.Join(roots, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %v, got %v", want, roots)
+	}
+
+	cfg.Topology = config.TopologySingleRepo
+	if roots := ProjectRoots(root, cfg); len(roots) != 1 || roots[0] != root {
+		t.Errorf("Expected workspace root only, got %v", roots)
+	}
+}
@@ -0,0 +1,303 @@
+// Package merge implements a line-based three-way merge.
+package merge
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+)
+
+// Kind classifies a chunk of a merge by which sides changed it
+type Kind int
+
+const (
+	// Unchanged lines are the same in base, ours and theirs
+	Unchanged Kind = iota
+	// Ours lines were changed only in ours
+	Ours
+	// Theirs lines were changed only in theirs
+	Theirs
+	// Same lines were changed identically in ours and theirs
+	Same
+	// Conflict lines were changed differently in ours and theirs
+	Conflict
+)
+
+// Resolution decides which side a chunk takes in the merged output
+type Resolution int
+
+const (
+	// ResolveDefault takes the changed side, or writes conflict markers
+	ResolveDefault Resolution = iota
+	// ResolveOurs takes the ours side
+	ResolveOurs
+	// ResolveTheirs takes the theirs side
+	ResolveTheirs
+)
+
+// Chunk is a run of lines in the merge. Base, Ours and Theirs hold each
+// side's version of the run, lines including their trailing newline.
+type Chunk struct {
+	Kind       Kind
+	Base       []string
+	Ours       []string
+	Theirs     []string
+	Resolution Resolution
+}
+
+// Result is a merge broken into chunks in file order
+type Result struct {
+	Chunks []Chunk
+}
+
+// Conflicts returns the number of conflicting chunks left unresolved
+func (r *Result) Conflicts() int {
+	n := 0
+	for _, c := range r.Chunks {
+		if c.Kind == Conflict && c.Resolution == ResolveDefault {
+			n++
+		}
+	}
+	return n
+}
+
+// Bytes renders the merge. Unresolved conflicts are written between
+// <<<<<<< oursLabel, ======= and >>>>>>> theirsLabel markers.
+func (r *Result) Bytes(oursLabel, theirsLabel string) []byte {
+	var buf bytes.Buffer
+	for _, c := range r.Chunks {
+		switch {
+		case c.Resolution == ResolveOurs:
+			writeLines(&buf, c.Ours)
+		case c.Resolution == ResolveTheirs:
+			writeLines(&buf, c.Theirs)
+		case c.Kind == Unchanged:
+			writeLines(&buf, c.Base)
+		case c.Kind == Theirs:
+			writeLines(&buf, c.Theirs)
+		case c.Kind == Conflict:
+			buf.WriteString("<<<<<<< " + oursLabel + "\n")
+			writeBlock(&buf, c.Ours)
+			buf.WriteString("=======\n")
+			writeBlock(&buf, c.Theirs)
+			buf.WriteString(">>>>>>> " + theirsLabel + "\n")
+		default:
+			writeLines(&buf, c.Ours)
+		}
+	}
+	return buf.Bytes()
+}
+
+// Merge combines the changes ours and theirs made to base. Changes to
+// separate lines merge cleanly; changes that overlap or touch the same lines
+// conflict unless both sides made the same change.
+func Merge(base, ours, theirs []byte) *Result {
+	b, o, t := splitLines(base), splitLines(ours), splitLines(theirs)
+	oh, th := diff(b, o), diff(b, t)
+
+	result := &Result{}
+	pos, i, j := 0, 0, 0
+	for i < len(oh) || j < len(th) {
+		start := len(b)
+		if i < len(oh) {
+			start = oh[i].start
+		}
+		if j < len(th) && th[j].start < start {
+			start = th[j].start
+		}
+		if start > pos {
+			result.Chunks = append(result.Chunks, unchanged(b[pos:start]))
+		}
+
+		// Grow the group while hunks from either side start inside or at the
+		// end of it
+		end := start
+		var og, tg []hunk
+		for {
+			if i < len(oh) && oh[i].start <= end {
+				og = append(og, oh[i])
+				end = max(end, oh[i].end)
+				i++
+			} else if j < len(th) && th[j].start <= end {
+				tg = append(tg, th[j])
+				end = max(end, th[j].end)
+				j++
+			} else {
+				break
+			}
+		}
+
+		c := Chunk{
+			Base:   b[start:end],
+			Ours:   apply(b, start, end, og),
+			Theirs: apply(b, start, end, tg),
+		}
+		switch {
+		case len(tg) == 0:
+			c.Kind = Ours
+		case len(og) == 0:
+			c.Kind = Theirs
+		case slices.Equal(c.Ours, c.Theirs):
+			c.Kind = Same
+		default:
+			c.Kind = Conflict
+		}
+		result.Chunks = append(result.Chunks, c)
+		pos = end
+	}
+	if pos < len(b) {
+		result.Chunks = append(result.Chunks, unchanged(b[pos:]))
+	}
+
+	return result
+}
+
+func unchanged(lines []string) Chunk {
+	return Chunk{Kind: Unchanged, Base: lines, Ours: lines, Theirs: lines}
+}
+
+// hunk replaces base[start:end] with lines
+type hunk struct {
+	start, end int
+	lines      []string
+}
+
+// apply returns base[start:end] with the hunks applied
+func apply(base []string, start, end int, hunks []hunk) []string {
+	var out []string
+	pos := start
+	for _, h := range hunks {
+		out = append(out, base[pos:h.start]...)
+		out = append(out, h.lines...)
+		pos = h.end
+	}
+	return append(out, base[pos:end]...)
+}
+
+// diff returns the hunks that turn a into b, in order. Hunks are separated
+// by at least one unchanged line.
+func diff(a, b []string) []hunk {
+	var hunks []hunk
+	x, y := 0, 0
+	for _, m := range matches(a, b) {
+		if m[0] > x || m[1] > y {
+			hunks = append(hunks, hunk{start: x, end: m[0], lines: b[y:m[1]]})
+		}
+		x, y = m[0]+1, m[1]+1
+	}
+	if x < len(a) || y < len(b) {
+		hunks = append(hunks, hunk{start: x, end: len(a), lines: b[y:]})
+	}
+	return hunks
+}
+
+// matches returns the index pairs of a longest common subsequence of a and
+// b in ascending order, using Myers' O(ND) algorithm
+func matches(a, b []string) [][2]int {
+	// Common prefix and suffix are matched without searching
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var pairs [][2]int
+	for k := 0; k < prefix; k++ {
+		pairs = append(pairs, [2]int{k, k})
+	}
+	for _, p := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		pairs = append(pairs, [2]int{p[0] + prefix, p[1] + prefix})
+	}
+	for k := suffix; k > 0; k-- {
+		pairs = append(pairs, [2]int{len(a) - k, len(b) - k})
+	}
+	return pairs
+}
+
+func myers(a, b []string) [][2]int {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return nil
+	}
+
+	// v[offset+k] is the furthest x reached on diagonal k; trace[d] is v
+	// before step d
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, slices.Clone(v))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk back through the trace collecting the diagonal moves
+	var pairs [][2]int
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY && x > 0 && y > 0 {
+			x--
+			y--
+			pairs = append(pairs, [2]int{x, y})
+		}
+		x, y = prevX, prevY
+	}
+	slices.Reverse(pairs)
+	return pairs
+}
+
+// splitLines splits content into lines that keep their trailing newline
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func writeLines(buf *bytes.Buffer, lines []string) {
+	for _, line := range lines {
+		buf.WriteString(line)
+	}
+}
+
+// writeBlock writes lines between conflict markers, ending the last line so
+// the marker that follows starts on its own line
+func writeBlock(buf *bytes.Buffer, lines []string) {
+	writeLines(buf, lines)
+	if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		buf.WriteByte('\n')
+	}
+}
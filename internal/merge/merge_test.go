@@ -0,0 +1,150 @@
+package merge
+
+import (
+	"strings"
+	"testing"
+)
+
+// lines joins each argument as a newline-terminated line
+func lines(ls ...string) []byte {
+	var b strings.Builder
+	for _, l := range ls {
+		b.WriteString(l + "\n")
+	}
+	return []byte(b.String())
+}
+
+func TestMerge_Clean(t *testing.T) {
+	base := lines("# Agent", "", "intro", "rules", "footer")
+	ours := lines("# Agent", "", "intro, edited by user", "rules", "footer")
+	theirs := lines("# Agent", "", "intro", "rules", "new rule", "footer")
+
+	r := Merge(base, ours, theirs)
+	if r.Conflicts() != 0 {
+		t.Fatalf("Expected no conflicts, got %d", r.Conflicts())
+	}
+
+	want := lines("# Agent", "", "intro, edited by user", "rules", "new rule", "footer")
+	if got := r.Bytes("yours", "template"); string(got) != string(want) {
+		t.Errorf("Merged content:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMerge_OneSideChanged(t *testing.T) {
+	base := lines("a", "b", "c")
+	changed := lines("a", "B", "c", "d")
+
+	if got := Merge(base, base, changed).Bytes("", ""); string(got) != string(changed) {
+		t.Errorf("Theirs only: got %q, want %q", got, changed)
+	}
+	if got := Merge(base, changed, base).Bytes("", ""); string(got) != string(changed) {
+		t.Errorf("Ours only: got %q, want %q", got, changed)
+	}
+}
+
+func TestMerge_SameChange(t *testing.T) {
+	base := lines("a", "b", "c")
+	both := lines("a", "x", "c")
+
+	r := Merge(base, both, both)
+	if r.Conflicts() != 0 {
+		t.Fatalf("Expected identical changes to merge cleanly, got %d conflicts", r.Conflicts())
+	}
+	if got := r.Bytes("", ""); string(got) != string(both) {
+		t.Errorf("got %q, want %q", got, both)
+	}
+}
+
+func TestMerge_Conflict(t *testing.T) {
+	base := lines("a", "b", "c", "d", "e")
+	ours := lines("a", "ours", "c", "d", "e")
+	theirs := lines("a", "theirs", "c", "d", "E")
+
+	r := Merge(base, ours, theirs)
+	if r.Conflicts() != 1 {
+		t.Fatalf("Expected 1 conflict, got %d", r.Conflicts())
+	}
+
+	want := lines("a", "<<<<<<< yours", "ours", "=======", "theirs", ">>>>>>> template", "c", "d", "E")
+	if got := r.Bytes("yours", "template"); string(got) != string(want) {
+		t.Errorf("Merged content:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMerge_AdjacentChangesConflict(t *testing.T) {
+	base := lines("a", "b", "c", "d")
+	ours := lines("a", "B", "c", "d")
+	theirs := lines("a", "b", "C", "d")
+
+	if n := Merge(base, ours, theirs).Conflicts(); n != 1 {
+		t.Errorf("Expected changes to neighbouring lines to conflict, got %d conflicts", n)
+	}
+}
+
+func TestMerge_Resolution(t *testing.T) {
+	base := lines("a", "b", "c")
+	ours := lines("a", "ours", "c")
+	theirs := lines("a", "theirs", "c", "d")
+
+	r := Merge(base, ours, theirs)
+	for i := range r.Chunks {
+		switch r.Chunks[i].Kind {
+		case Conflict:
+			r.Chunks[i].Resolution = ResolveTheirs
+		case Theirs:
+			r.Chunks[i].Resolution = ResolveOurs
+		}
+	}
+
+	if r.Conflicts() != 0 {
+		t.Errorf("Expected resolved conflicts not to count, got %d", r.Conflicts())
+	}
+	// The template's added line was rejected
+	want := lines("a", "theirs", "c")
+	if got := r.Bytes("", ""); string(got) != string(want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMerge_NoTrailingNewline(t *testing.T) {
+	base := []byte("a\nb")
+	ours := []byte("a\nours")
+	theirs := []byte("a\ntheirs")
+
+	want := "a\n<<<<<<< yours\nours\n=======\ntheirs\n>>>>>>> template\n"
+	if got := Merge(base, ours, theirs).Bytes("yours", "template"); string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		a, b []string
+	}{
+		{nil, nil},
+		{nil, []string{"x"}},
+		{[]string{"x"}, nil},
+		{[]string{"a", "b", "c", "a", "b", "b", "a"}, []string{"c", "b", "a", "b", "a", "c"}},
+		{[]string{"a", "b", "c"}, []string{"x", "b", "y"}},
+		{[]string{"a", "a", "a"}, []string{"a", "b", "a"}},
+	}
+
+	for _, tt := range tests {
+		hunks := diff(tt.a, tt.b)
+		if got := apply(tt.a, 0, len(tt.a), hunks); strings.Join(got, ",") != strings.Join(tt.b, ",") {
+			t.Errorf("diff(%v, %v) applied gives %v", tt.a, tt.b, got)
+		}
+		for i := 1; i < len(hunks); i++ {
+			if hunks[i].start <= hunks[i-1].end {
+				t.Errorf("diff(%v, %v): hunks %d and %d are not separated", tt.a, tt.b, i-1, i)
+			}
+		}
+	}
+
+	// The classic example has an edit distance of 5
+	a := []string{"a", "b", "c", "a", "b", "b", "a"}
+	b := []string{"c", "b", "a", "b", "a", "c"}
+	if got := len(matches(a, b)); got != 4 {
+		t.Errorf("Expected an LCS of 4 lines, got %d", got)
+	}
+}
@@ -0,0 +1,78 @@
+package merge
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Unified renders the changes that turn a into b as a unified diff with
+// context lines around each change. It returns nil when a and b are equal.
+func Unified(a, b []byte, aLabel, bLabel string, context int) []byte {
+	al, bl := splitLines(a), splitLines(b)
+	hunks := diff(al, bl)
+	if len(hunks) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("--- " + aLabel + "\n")
+	buf.WriteString("+++ " + bLabel + "\n")
+
+	// delta is how far b's line numbers have moved from a's before hunk i
+	delta := 0
+	for i := 0; i < len(hunks); {
+		// Group hunks whose context would touch or overlap
+		j := i + 1
+		for j < len(hunks) && hunks[j].start-hunks[j-1].end <= 2*context {
+			j++
+		}
+		group := hunks[i:j]
+
+		aStart := max(0, group[0].start-context)
+		aEnd := min(len(al), group[len(group)-1].end+context)
+		groupDelta := 0
+		for _, h := range group {
+			groupDelta += len(h.lines) - (h.end - h.start)
+		}
+		bStart := aStart + delta
+		bEnd := aEnd + delta + groupDelta
+
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n", hunkRange(aStart, aEnd-aStart), hunkRange(bStart, bEnd-bStart))
+		pos := aStart
+		for _, h := range group {
+			writePrefixed(&buf, " ", al[pos:h.start])
+			writePrefixed(&buf, "-", al[h.start:h.end])
+			writePrefixed(&buf, "+", h.lines)
+			pos = h.end
+		}
+		writePrefixed(&buf, " ", al[pos:aEnd])
+
+		delta += groupDelta
+		i = j
+	}
+
+	return buf.Bytes()
+}
+
+// hunkRange formats a hunk header range from a 0-based start line
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+// writePrefixed writes diff lines, marking a last line without a newline
+func writePrefixed(buf *bytes.Buffer, prefix string, lines []string) {
+	for _, line := range lines {
+		buf.WriteString(prefix + line)
+		if !strings.HasSuffix(line, "\n") {
+			buf.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package merge
+
+import (
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	a := lines("1", "2", "3", "4", "5", "6", "7", "8", "9", "10")
+	b := lines("1", "2", "three", "4", "5", "6", "7", "8", "9", "10", "11")
+
+	want := "--- a\n+++ b\n" +
+		"@@ -2,3 +2,3 @@\n 2\n-3\n+three\n 4\n" +
+		"@@ -10 +10,2 @@\n 10\n+11\n"
+	if got := Unified(a, b, "a", "b", 1); string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// With more context the two changes share a hunk
+	want = "--- a\n+++ b\n" +
+		"@@ -1,10 +1,11 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n 7\n 8\n 9\n 10\n+11\n"
+	if got := Unified(a, b, "a", "b", 4); string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnified_Equal(t *testing.T) {
+	if got := Unified(lines("a"), lines("a"), "a", "b", 3); got != nil {
+		t.Errorf("Expected no diff for equal content, got %q", got)
+	}
+}
+
+func TestUnified_EmptyAndNoTrailingNewline(t *testing.T) {
+	want := "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+x\n+y\n\\ No newline at end of file\n"
+	if got := Unified(nil, []byte("x\ny"), "a", "b", 3); string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -1,14 +1,14 @@
 package mutator
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/Wameedh/ccflow/internal/blueprint"
+	"github.com/Wameedh/ccflow/internal/settings"
 	"github.com/Wameedh/ccflow/internal/util"
+	"github.com/Wameedh/ccflow/internal/workspace"
 )
 
 // Mutator handles adding agents, commands, and hooks to a workflow
@@ -52,8 +52,11 @@ func (m *Mutator) AddAgent(opts AddOptions) error {
 		return err
 	}
 
-	agentPath := filepath.Join(opts.HubPath, "agents", opts.Name+".md")
-	return util.SafeWriteFile(agentPath, content, opts.Force)
+	relPath := filepath.Join("agents", opts.Name+".md")
+	if err := util.SafeWriteFile(filepath.Join(opts.HubPath, relPath), content, opts.Force); err != nil {
+		return err
+	}
+	return track(opts, relPath, content)
 }
 
 // AddCommand adds a command to the workflow
@@ -63,8 +66,11 @@ func (m *Mutator) AddCommand(opts AddOptions) error {
 		return err
 	}
 
-	cmdPath := filepath.Join(opts.HubPath, "commands", opts.Name+".md")
-	return util.SafeWriteFile(cmdPath, content, opts.Force)
+	relPath := filepath.Join("commands", opts.Name+".md")
+	if err := util.SafeWriteFile(filepath.Join(opts.HubPath, relPath), content, opts.Force); err != nil {
+		return err
+	}
+	return track(opts, relPath, content)
 }
 
 // AddHook adds a hook to the workflow and updates settings.json
@@ -75,8 +81,11 @@ func (m *Mutator) AddHook(opts AddOptions) error {
 	}
 
 	// Write the hook script
-	hookPath := filepath.Join(opts.HubPath, "hooks", opts.Name+".sh")
-	if err := util.SafeWriteExecutable(hookPath, content, opts.Force); err != nil {
+	relPath := filepath.Join("hooks", opts.Name+".sh")
+	if err := util.SafeWriteExecutable(filepath.Join(opts.HubPath, relPath), content, opts.Force); err != nil {
+		return err
+	}
+	if err := track(opts, relPath, content); err != nil {
 		return err
 	}
 
@@ -88,6 +97,22 @@ func (m *Mutator) AddHook(opts AddOptions) error {
 	return nil
 }
 
+// track records a file written from a built-in template in the managed-files
+// manifest. A file written from other content is no longer tracked, so
+// upgrade leaves it alone.
+func track(opts AddOptions, relPath string, content []byte) error {
+	var err error
+	if opts.Source == SourceTemplate {
+		err = workspace.RecordManagedFile(opts.HubPath, opts.BlueprintID+"/"+relPath, relPath, content)
+	} else {
+		err = workspace.ForgetManagedFile(opts.HubPath, relPath)
+	}
+	if err != nil {
+		return fmt.Errorf("%s written but failed to update %s: %w", relPath, workspace.ManifestFile, err)
+	}
+	return nil
+}
+
 // GetTemplateContent returns the template content for an artifact without writing
 func (m *Mutator) GetTemplateContent(blueprintID, artifactType, name string, data *blueprint.TemplateData) ([]byte, error) {
 	switch artifactType {
@@ -131,148 +156,17 @@ func (m *Mutator) resolveContent(opts AddOptions, artifactType string) ([]byte,
 
 // registerHook updates settings.json to include the hook
 func (m *Mutator) registerHook(opts AddOptions) error {
-	settingsPath := filepath.Join(opts.HubPath, "settings.json")
-
-	// Read existing settings
-	var settings map[string]interface{}
-	if util.FileExists(settingsPath) {
-		data, err := os.ReadFile(settingsPath)
-		if err != nil {
-			return fmt.Errorf("failed to read settings.json: %w", err)
-		}
-		if err := json.Unmarshal(data, &settings); err != nil {
-			return fmt.Errorf("failed to parse settings.json: %w", err)
-		}
-	} else {
-		settings = make(map[string]interface{})
-	}
-
-	// Get hook registration info from blueprint
-	bp, err := m.bpManager.Get(opts.BlueprintID)
+	settingsPath := filepath.Join(opts.HubPath, settings.FileName)
+	s, err := settings.Load(settingsPath)
 	if err != nil {
-		// If blueprint not found, use default registration
-		return m.addDefaultHookRegistration(settings, settingsPath, opts.Name)
-	}
-
-	hookReg, ok := bp.HooksManifest[opts.Name]
-	if !ok {
-		// Hook not in manifest, use default registration
-		return m.addDefaultHookRegistration(settings, settingsPath, opts.Name)
-	}
-
-	// Add hook events to settings
-	return m.addHookRegistration(settings, settingsPath, hookReg)
-}
-
-// addHookRegistration adds a hook registration to settings
-func (m *Mutator) addHookRegistration(settings map[string]interface{}, settingsPath string, hookReg blueprint.HookRegistration) error {
-	hooks, ok := settings["hooks"].(map[string]interface{})
-	if !ok {
-		hooks = make(map[string]interface{})
-	}
-
-	// Add each event from the hook registration
-	for _, event := range hookReg.Events {
-		// Build the hook entry in new format
-		hookEntry := map[string]interface{}{
-			"hooks": []interface{}{
-				map[string]interface{}{
-					"type":    "command",
-					"command": "./" + hookReg.Script,
-				},
-			},
-		}
-
-		// Add matcher if there are tool filters (string format with pipe separator)
-		if len(event.Commands) > 0 {
-			hookEntry["matcher"] = strings.Join(event.Commands, "|")
-		}
-
-		// Get or create the event array
-		eventHooks, ok := hooks[event.Event].([]interface{})
-		if !ok {
-			eventHooks = []interface{}{}
-		}
-
-		// Check if this hook already exists
-		if !m.hookExists(eventHooks, hookReg.Script) {
-			eventHooks = append(eventHooks, hookEntry)
-		}
-		hooks[event.Event] = eventHooks
-	}
-
-	settings["hooks"] = hooks
-
-	// Write updated settings
-	return m.writeSettings(settingsPath, settings)
-}
-
-// addDefaultHookRegistration adds a default hook registration
-func (m *Mutator) addDefaultHookRegistration(settings map[string]interface{}, settingsPath, hookName string) error {
-	hooks, ok := settings["hooks"].(map[string]interface{})
-	if !ok {
-		hooks = make(map[string]interface{})
-	}
-
-	// Default: register as Stop event
-	hookScript := "./hooks/" + hookName + ".sh"
-	hookEntry := map[string]interface{}{
-		"hooks": []interface{}{
-			map[string]interface{}{
-				"type":    "command",
-				"command": hookScript,
-			},
-		},
-	}
-
-	// Get or create the Stop event array
-	stopHooks, ok := hooks["Stop"].([]interface{})
-	if !ok {
-		stopHooks = []interface{}{}
-	}
-
-	if !m.hookExists(stopHooks, hookScript) {
-		stopHooks = append(stopHooks, hookEntry)
-	}
-
-	hooks["Stop"] = stopHooks
-	settings["hooks"] = hooks
-	return m.writeSettings(settingsPath, settings)
-}
-
-// hookExists checks if a hook with the same script already exists in an event's hook list
-func (m *Mutator) hookExists(eventHooks []interface{}, script string) bool {
-	for _, h := range eventHooks {
-		hookEntry, ok := h.(map[string]interface{})
-		if !ok {
-			continue
-		}
-		// Check in the hooks array within this entry
-		hooksArr, ok := hookEntry["hooks"].([]interface{})
-		if !ok {
-			continue
-		}
-		for _, hk := range hooksArr {
-			hook, ok := hk.(map[string]interface{})
-			if !ok {
-				continue
-			}
-			if hook["command"] == script || hook["command"] == "./"+script {
-				return true
-			}
-		}
+		return err
 	}
-	return false
-}
 
-// writeSettings writes the settings map to file
-func (m *Mutator) writeSettings(path string, settings map[string]interface{}) error {
-	data, err := json.MarshalIndent(settings, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal settings: %w", err)
-	}
+	// Use the blueprint's registration when it has one for this hook
+	bp, _ := m.bpManager.Get(opts.BlueprintID)
+	blueprint.HookRegistrationFor(bp, opts.Name).Register(s)
 
-	return os.WriteFile(path, data, 0644)
+	return s.Save(settingsPath)
 }
 
 // HasTemplate checks if a template exists for the given artifact
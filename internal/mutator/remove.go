@@ -0,0 +1,165 @@
+package mutator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/Wameedh/ccflow/internal/blueprint"
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/settings"
+	"github.com/Wameedh/ccflow/internal/util"
+	"github.com/Wameedh/ccflow/internal/workspace"
+)
+
+// RemoveOptions contains options for removing an artifact
+type RemoveOptions struct {
+	Name        string
+	BlueprintID string                 // For hook registrations
+	HubPath     string                 // Path to .claude directory
+	Config      *config.WorkflowConfig // Updated in place unless DryRun; the caller saves it
+	DryRun      bool
+}
+
+// Removal describes what removing an artifact changed, or would change
+type Removal struct {
+	Path        string   // File removed, relative to the hub's .claude directory
+	FileExisted bool     // Whether the file was there to remove
+	Events      []string // Hook events deregistered from settings.json
+	Permissions bool     // Whether the agent_permissions entry was dropped
+	Groups      []string // Parallel groups the agent was dropped from
+	Managed     bool     // Whether the file was tracked in the managed-files manifest
+}
+
+// ConfigChanged reports whether the removal changed workflow.yaml
+func (r *Removal) ConfigChanged() bool {
+	return r.Permissions || len(r.Groups) > 0
+}
+
+// RemoveAgent deletes an agent, drops it from agent_permissions and the
+// parallel groups, and records it as removed in the managed-files manifest
+func (m *Mutator) RemoveAgent(opts RemoveOptions) (*Removal, error) {
+	removal, err := m.removeFile(opts, filepath.Join("agents", opts.Name+".md"), true)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Config != nil {
+		_, removal.Permissions = opts.Config.AgentPermissions[opts.Name]
+		parallel := opts.Config.Parallel
+		removal.Groups = dropFromGroups(&parallel, opts.Name)
+		if !opts.DryRun {
+			delete(opts.Config.AgentPermissions, opts.Name)
+			opts.Config.Parallel = parallel
+		}
+	}
+
+	return removal, nil
+}
+
+// RemoveCommand deletes a command and records it as removed in the
+// managed-files manifest
+func (m *Mutator) RemoveCommand(opts RemoveOptions) (*Removal, error) {
+	return m.removeFile(opts, filepath.Join("commands", opts.Name+".md"), true)
+}
+
+// RemoveHook deletes a hook script, deregisters it from settings.json and
+// records it as removed in the managed-files manifest. A hook that is only
+// registered, with its script already gone, is deregistered.
+func (m *Mutator) RemoveHook(opts RemoveOptions) (*Removal, error) {
+	relPath := filepath.Join("hooks", opts.Name+".sh")
+
+	settingsPath := filepath.Join(opts.HubPath, settings.FileName)
+	s, err := settings.Load(settingsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop both the script ccflow writes and the blueprint's registration,
+	// which may point elsewhere
+	bp, _ := m.bpManager.Get(opts.BlueprintID)
+	commands := []string{"./" + relPath}
+	if reg := blueprint.HookRegistrationFor(bp, opts.Name); reg.Command() != commands[0] {
+		commands = append(commands, reg.Command())
+	}
+	var events []string
+	for _, command := range commands {
+		for _, event := range s.RemoveHook(command) {
+			if !slices.Contains(events, event) {
+				events = append(events, event)
+			}
+		}
+	}
+
+	if len(events) == 0 && !util.FileExists(filepath.Join(opts.HubPath, relPath)) {
+		return nil, fmt.Errorf("hook '%s' not found: no %s and no registration in %s", opts.Name, relPath, settings.FileName)
+	}
+
+	removal, err := m.removeFile(opts, relPath, false)
+	if err != nil {
+		return nil, err
+	}
+	removal.Events = events
+
+	if len(events) > 0 && !opts.DryRun {
+		if err := s.Save(settingsPath); err != nil {
+			return removal, fmt.Errorf("hook script removed but failed to update settings.json: %w", err)
+		}
+	}
+
+	return removal, nil
+}
+
+// removeFile deletes an artifact file and records it as removed in the
+// managed-files manifest
+func (m *Mutator) removeFile(opts RemoveOptions, relPath string, mustExist bool) (*Removal, error) {
+	path := filepath.Join(opts.HubPath, relPath)
+	removal := &Removal{Path: relPath, FileExisted: util.FileExists(path)}
+
+	if !removal.FileExisted && mustExist {
+		return nil, fmt.Errorf("%s not found in %s", relPath, opts.HubPath)
+	}
+
+	manifest := workspace.LoadManifest(opts.HubPath)
+	_, removal.Managed = manifest.Files[relPath]
+
+	if opts.DryRun {
+		return removal, nil
+	}
+
+	if removal.FileExisted {
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove %s: %w", relPath, err)
+		}
+	}
+
+	manifest.Forget(relPath)
+	_ = workspace.RemoveBase(opts.HubPath, relPath) // Best effort
+	if err := workspace.SaveManifest(opts.HubPath, manifest); err != nil {
+		return removal, fmt.Errorf("%s removed but failed to update %s: %w", relPath, workspace.ManifestFile, err)
+	}
+
+	return removal, nil
+}
+
+// dropFromGroups removes an agent from every parallel group, dropping groups
+// left without agents. It returns the groups the agent was removed from.
+func dropFromGroups(cfg *config.ParallelConfig, agent string) []string {
+	var dropped []string
+	var groups []config.ParallelGroup
+	for _, group := range cfg.Groups {
+		if slices.Contains(group.Agents, agent) {
+			dropped = append(dropped, group.Name)
+			group.Agents = slices.DeleteFunc(slices.Clone(group.Agents), func(a string) bool { return a == agent })
+			if len(group.Agents) == 0 {
+				continue
+			}
+		}
+		groups = append(groups, group)
+	}
+	if len(dropped) > 0 {
+		cfg.Groups = groups
+	}
+	return dropped
+}
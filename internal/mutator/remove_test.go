@@ -0,0 +1,271 @@
+package mutator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/Wameedh/ccflow/internal/blueprint"
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/settings"
+	"github.com/Wameedh/ccflow/internal/util"
+	"github.com/Wameedh/ccflow/internal/workspace"
+)
+
+// testBlueprint registers the lint hook under a script path other than the
+// hooks/lint.sh ccflow writes, so removal must drop both spellings
+const testBlueprint = `id: test-bp
+display_name: Test
+description: Blueprint for mutator tests
+default_topology: single-repo
+agents:
+  defaults: [qa-agent]
+commands:
+  defaults: [ship]
+hooks:
+  defaults: [lint]
+hooks_manifest:
+  lint:
+    script: hooks/checks/lint.sh
+    events:
+      - event: PostToolUse
+        commands: [Write, Edit]
+`
+
+// hubFixture is a hub .claude directory with one agent, command and hook,
+// all tracked in the manifest, and a config that refers to the agent
+type hubFixture struct {
+	hub string
+	cfg *config.WorkflowConfig
+	m   *Mutator
+}
+
+func setupHub(t *testing.T) *hubFixture {
+	t.Helper()
+
+	bpDir := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(blueprint.EnvBlueprintPath, bpDir)
+	os.MkdirAll(filepath.Join(bpDir, "test-bp"), 0755)
+	if err := os.WriteFile(filepath.Join(bpDir, "test-bp", "blueprint.yaml"), []byte(testBlueprint), 0644); err != nil {
+		t.Fatal(err)
+	}
+	settingsAsset := filepath.Join(bpDir, "test-bp", "assets", ".claude", settings.FileName)
+	if err := util.SafeWriteFile(settingsAsset, []byte("{}\n"), true); err != nil {
+		t.Fatal(err)
+	}
+	bpManager, err := blueprint.NewManager()
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+
+	hub := filepath.Join(t.TempDir(), ".claude")
+	files := map[string]string{
+		"agents/qa-agent.md": "qa",
+		"commands/ship.md":   "ship",
+		"hooks/lint.sh":      "#!/bin/sh\n",
+	}
+	for rel, content := range files {
+		relPath := filepath.FromSlash(rel)
+		if err := util.SafeWriteFile(filepath.Join(hub, relPath), []byte(content), true); err != nil {
+			t.Fatal(err)
+		}
+		if err := workspace.RecordManagedFile(hub, "test-bp/"+rel, relPath, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := settings.New()
+	s.AddHook("Stop", "", "./hooks/lint.sh")
+	s.AddHook("PostToolUse", "Write|Edit", "./hooks/checks/lint.sh")
+	s.AddHook("Stop", "", "./hooks/end-of-turn.sh")
+	if err := s.Save(filepath.Join(hub, settings.FileName)); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.NewDefaultWorkflowConfig("shop")
+	cfg.AgentPermissions = map[string]config.AgentPermission{
+		"qa-agent":  {Write: []string{"web"}},
+		"dev-agent": {Write: []string{"api"}},
+	}
+	cfg.Parallel.Groups = []config.ParallelGroup{
+		{Name: "checks", Agents: []string{"qa-agent"}},
+		{Name: "build", Agents: []string{"dev-agent", "qa-agent"}},
+		{Name: "docs", Agents: []string{"dev-agent"}},
+	}
+
+	return &hubFixture{hub: hub, cfg: cfg, m: New(bpManager)}
+}
+
+// snapshotHub reads every file under the hub and its .ccflow directory
+func snapshotHub(t *testing.T, hub string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	root := filepath.Dir(hub)
+	filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			data, _ := os.ReadFile(path)
+			rel, _ := filepath.Rel(root, path)
+			files[rel] = string(data)
+		}
+		return nil
+	})
+	return files
+}
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove func(f *hubFixture, opts RemoveOptions) (*Removal, error)
+		opts   RemoveOptions
+		check  func(t *testing.T, f *hubFixture, removal *Removal, before map[string]string)
+	}{
+		{
+			name: "dry run changes nothing",
+			remove: func(f *hubFixture, opts RemoveOptions) (*Removal, error) {
+				return f.m.RemoveAgent(opts)
+			},
+			opts: RemoveOptions{Name: "qa-agent", DryRun: true},
+			check: func(t *testing.T, f *hubFixture, removal *Removal, before map[string]string) {
+				if !removal.FileExisted || !removal.Managed || !removal.Permissions || len(removal.Groups) != 2 {
+					t.Errorf("Expected the removal to be described, got %+v", removal)
+				}
+				if after := snapshotHub(t, f.hub); !reflect.DeepEqual(before, after) {
+					t.Errorf("Dry run changed files:\nbefore %v\nafter  %v", before, after)
+				}
+				if _, ok := f.cfg.AgentPermissions["qa-agent"]; !ok || len(f.cfg.Parallel.Groups) != 3 {
+					t.Errorf("Dry run changed the config: %+v %+v", f.cfg.AgentPermissions, f.cfg.Parallel.Groups)
+				}
+			},
+		},
+		{
+			name: "agent is dropped from permissions and groups",
+			remove: func(f *hubFixture, opts RemoveOptions) (*Removal, error) {
+				return f.m.RemoveAgent(opts)
+			},
+			opts: RemoveOptions{Name: "qa-agent"},
+			check: func(t *testing.T, f *hubFixture, removal *Removal, before map[string]string) {
+				if !removal.Permissions || !slices.Equal(removal.Groups, []string{"checks", "build"}) || !removal.ConfigChanged() {
+					t.Errorf("Unexpected removal: %+v", removal)
+				}
+				if _, ok := f.cfg.AgentPermissions["qa-agent"]; ok {
+					t.Error("Expected qa-agent's permissions to be dropped")
+				}
+				if _, ok := f.cfg.AgentPermissions["dev-agent"]; !ok {
+					t.Error("Expected dev-agent's permissions to be kept")
+				}
+				// checks had only qa-agent and is gone; the others keep dev-agent
+				want := []config.ParallelGroup{
+					{Name: "build", Agents: []string{"dev-agent"}},
+					{Name: "docs", Agents: []string{"dev-agent"}},
+				}
+				if !reflect.DeepEqual(f.cfg.Parallel.Groups, want) {
+					t.Errorf("Expected groups %+v, got %+v", want, f.cfg.Parallel.Groups)
+				}
+				if util.FileExists(filepath.Join(f.hub, "agents", "qa-agent.md")) {
+					t.Error("Expected the agent file to be removed")
+				}
+			},
+		},
+		{
+			name: "hook is deregistered under both spellings",
+			remove: func(f *hubFixture, opts RemoveOptions) (*Removal, error) {
+				return f.m.RemoveHook(opts)
+			},
+			opts: RemoveOptions{Name: "lint", BlueprintID: "test-bp"},
+			check: func(t *testing.T, f *hubFixture, removal *Removal, before map[string]string) {
+				if !slices.Equal(removal.Events, []string{"Stop", "PostToolUse"}) {
+					t.Errorf("Expected Stop and PostToolUse deregistered, got %v", removal.Events)
+				}
+				s, err := settings.Load(filepath.Join(f.hub, settings.FileName))
+				if err != nil {
+					t.Fatal(err)
+				}
+				refs := s.CommandHooks()
+				if len(refs) != 1 || refs[0].Command != "./hooks/end-of-turn.sh" {
+					t.Errorf("Expected only end-of-turn to stay registered, got %+v", refs)
+				}
+				if util.FileExists(filepath.Join(f.hub, "hooks", "lint.sh")) {
+					t.Error("Expected the hook script to be removed")
+				}
+			},
+		},
+		{
+			name: "manifest records the removal and drops the base",
+			remove: func(f *hubFixture, opts RemoveOptions) (*Removal, error) {
+				return f.m.RemoveCommand(opts)
+			},
+			opts: RemoveOptions{Name: "ship"},
+			check: func(t *testing.T, f *hubFixture, removal *Removal, before map[string]string) {
+				relPath := filepath.Join("commands", "ship.md")
+				if !removal.Managed || removal.Path != relPath {
+					t.Errorf("Unexpected removal: %+v", removal)
+				}
+				manifest := workspace.LoadManifest(f.hub)
+				if _, ok := manifest.Files[relPath]; ok || !manifest.IsRemoved(relPath) {
+					t.Errorf("Expected %s recorded as removed, got %+v", relPath, manifest)
+				}
+				if _, ok := workspace.LoadBase(f.hub, relPath); ok {
+					t.Error("Expected the base to be deleted")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := setupHub(t)
+			before := snapshotHub(t, f.hub)
+
+			opts := tt.opts
+			opts.HubPath = f.hub
+			opts.Config = f.cfg
+			removal, err := tt.remove(f, opts)
+			if err != nil {
+				t.Fatalf("Remove failed: %v", err)
+			}
+			tt.check(t, f, removal, before)
+		})
+	}
+}
+
+// TestRemoveHook_MatchesRendering checks that ccflow diff finds nothing after
+// the last hook is removed: the hub settings.json matches a fresh rendering
+func TestRemoveHook_MatchesRendering(t *testing.T) {
+	f := setupHub(t)
+	bp, err := f.m.bpManager.Get("test-bp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	render := func() []byte {
+		t.Helper()
+		s, err := f.m.bpManager.RenderHubSettings(bp, true, workspace.LoadManifest(f.hub))
+		if err != nil {
+			t.Fatalf("RenderHubSettings failed: %v", err)
+		}
+		data, err := s.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	settingsPath := filepath.Join(f.hub, settings.FileName)
+	if err := os.WriteFile(settingsPath, render(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.m.RemoveHook(RemoveOptions{Name: "lint", BlueprintID: "test-bp", HubPath: f.hub, Config: f.cfg}); err != nil {
+		t.Fatalf("RemoveHook failed: %v", err)
+	}
+
+	current, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if template := render(); !bytes.Equal(current, template) {
+		t.Errorf("Expected settings.json to match the rendering\ncurrent:\n%s\ntemplate:\n%s", current, template)
+	}
+}
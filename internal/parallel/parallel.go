@@ -0,0 +1,213 @@
+package parallel
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/transition"
+)
+
+// Sync modes for parallel groups and the sync gate
+const (
+	SyncAll = "all" // wait for every agent (or group)
+	SyncAny = "any" // continue once the first agent (or group) finishes
+)
+
+// DefaultPhase is the phase a group runs in when it names none
+const DefaultPhase = transition.PhaseImplement
+
+// Issue describes a problem with the parallel configuration
+type Issue struct {
+	Severity string // "error" or "warn"
+	Group    string // group name, empty for the parallel section itself
+	Message  string
+}
+
+// String formats the issue for display
+func (i Issue) String() string {
+	if i.Group == "" {
+		return i.Message
+	}
+	return fmt.Sprintf("group %s: %s", i.Group, i.Message)
+}
+
+// ValidSync reports whether s is a legal sync or sync_gate value
+func ValidSync(s string) bool {
+	return s == SyncAll || s == SyncAny
+}
+
+// PhaseOf returns the phase a group runs in
+func PhaseOf(group config.ParallelGroup) transition.Phase {
+	if group.Phase == "" {
+		return DefaultPhase
+	}
+	return transition.Phase(group.Phase)
+}
+
+// SyncGate returns the configured sync gate, which defaults to all
+func SyncGate(cfg config.ParallelConfig) string {
+	if cfg.SyncGate == "" {
+		return SyncAll
+	}
+	return cfg.SyncGate
+}
+
+// SyncOf returns a group's sync mode, falling back to the sync gate
+func SyncOf(cfg config.ParallelConfig, group config.ParallelGroup) string {
+	if group.Sync == "" {
+		return SyncGate(cfg)
+	}
+	return group.Sync
+}
+
+// HubAgents lists the agents installed in a hub .claude directory
+func HubAgents(hubPath string) []string {
+	var agents []string
+	entries, _ := os.ReadDir(filepath.Join(hubPath, "agents"))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
+			agents = append(agents, strings.TrimSuffix(entry.Name(), ".md"))
+		}
+	}
+	return agents
+}
+
+// Validate checks the parallel section against the agents installed in the
+// hub. Groups in the same phase run concurrently, so an agent may appear in
+// at most one group per phase.
+func Validate(cfg config.ParallelConfig, hubAgents []string) []Issue {
+	var issues []Issue
+	errorf := func(group, format string, args ...interface{}) {
+		issues = append(issues, Issue{Severity: "error", Group: group, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !ValidSync(SyncGate(cfg)) {
+		errorf("", "invalid sync_gate %q (must be 'all' or 'any')", cfg.SyncGate)
+	}
+	if !cfg.Enabled && len(cfg.Groups) > 0 {
+		issues = append(issues, Issue{
+			Severity: "warn",
+			Message:  fmt.Sprintf("%d group(s) defined but parallel execution is disabled", len(cfg.Groups)),
+		})
+	}
+
+	names := make(map[string]bool)
+	// owner[phase][agent] is the first group in phase that lists agent
+	owner := make(map[transition.Phase]map[string]string)
+
+	for i, group := range cfg.Groups {
+		name := group.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+			errorf(name, "missing name")
+		} else if names[name] {
+			errorf(name, "duplicate group name")
+		}
+		names[name] = true
+
+		if group.Sync != "" && !ValidSync(group.Sync) {
+			errorf(name, "invalid sync %q (must be 'all' or 'any')", group.Sync)
+		}
+
+		phase := PhaseOf(group)
+		if !slices.Contains(transition.Phases, phase) {
+			errorf(name, "unknown phase %q", group.Phase)
+		}
+
+		switch len(group.Agents) {
+		case 0:
+			errorf(name, "no agents")
+		case 1:
+			issues = append(issues, Issue{Severity: "warn", Group: name, Message: "only one agent; nothing runs in parallel"})
+		}
+
+		if owner[phase] == nil {
+			owner[phase] = make(map[string]string)
+		}
+		seen := make(map[string]bool)
+		for _, agent := range group.Agents {
+			if seen[agent] {
+				errorf(name, "agent %s listed twice", agent)
+				continue
+			}
+			seen[agent] = true
+
+			if !slices.Contains(hubAgents, agent) {
+				errorf(name, "agent %s not found in hub agents/", agent)
+			}
+			if other, ok := owner[phase][agent]; ok {
+				errorf(name, "agent %s is also in group %s, which runs concurrently in the %s phase", agent, other, phase)
+			} else {
+				owner[phase][agent] = name
+			}
+		}
+	}
+
+	return issues
+}
+
+// Stage is one phase of the execution plan
+type Stage struct {
+	Phase      transition.Phase
+	Groups     []config.ParallelGroup // groups that run concurrently in this phase, with sync resolved
+	Transition *transition.Transition // transition out of the phase; nil for the last phase
+}
+
+// Plan is the order in which phases and parallel groups run
+type Plan struct {
+	Enabled  bool
+	SyncGate string
+	Stages   []Stage
+}
+
+// BuildPlan derives the execution plan from the workflow's transitions and
+// parallel groups. Groups with an unknown phase are left out.
+func BuildPlan(cfg *config.WorkflowConfig) *Plan {
+	plan := &Plan{Enabled: cfg.Parallel.Enabled, SyncGate: SyncGate(cfg.Parallel)}
+
+	for _, phase := range transition.Phases {
+		stage := Stage{Phase: phase}
+		for _, group := range cfg.Parallel.Groups {
+			if PhaseOf(group) == phase {
+				group.Sync = SyncOf(cfg.Parallel, group)
+				stage.Groups = append(stage.Groups, group)
+			}
+		}
+		if t, err := transition.For(cfg.Transitions, phase); err == nil {
+			stage.Transition = &t
+		}
+		plan.Stages = append(plan.Stages, stage)
+	}
+
+	return plan
+}
+
+// Join describes when a stage's parallel groups are joined before the
+// transition out of the phase
+func (p *Plan) Join(stage Stage) string {
+	if len(stage.Groups) == 0 {
+		return ""
+	}
+
+	var parts []string
+	for _, group := range stage.Groups {
+		if group.Sync == SyncAny {
+			parts = append(parts, fmt.Sprintf("%s after its first agent", group.Name))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s after all %d agent(s)", group.Name, len(group.Agents)))
+		}
+	}
+
+	joined := strings.Join(parts, "; ")
+	if len(stage.Groups) == 1 {
+		return joined
+	}
+	if p.SyncGate == SyncAny {
+		return "first group to finish: " + joined
+	}
+	return "every group: " + joined
+}
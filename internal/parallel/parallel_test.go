@@ -0,0 +1,135 @@
+package parallel
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/transition"
+)
+
+var hubAgents = []string{"backend-agent", "frontend-agent", "test-agent", "review-agent"}
+
+// messages returns the issues as strings with their severity
+func messages(issues []Issue) []string {
+	var out []string
+	for _, issue := range issues {
+		out = append(out, issue.Severity+": "+issue.String())
+	}
+	return out
+}
+
+func TestValidate_Valid(t *testing.T) {
+	cfg := config.ParallelConfig{
+		Enabled:  true,
+		SyncGate: "all",
+		Groups: []config.ParallelGroup{
+			{Name: "build", Agents: []string{"backend-agent", "frontend-agent"}, Sync: "all"},
+			{Name: "checks", Agents: []string{"test-agent", "review-agent"}},
+			// The same agent may run in a different phase
+			{Name: "review", Agents: []string{"backend-agent", "review-agent"}, Sync: "any", Phase: "review"},
+		},
+	}
+
+	if issues := Validate(cfg, hubAgents); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %v", messages(issues))
+	}
+}
+
+func TestValidate_Problems(t *testing.T) {
+	cfg := config.ParallelConfig{
+		Enabled:  true,
+		SyncGate: "some",
+		Groups: []config.ParallelGroup{
+			{Name: "build", Agents: []string{"backend-agent", "frontend-agent"}},
+			{Name: "build", Agents: []string{"frontend-agent", "ghost-agent"}, Sync: "most"},
+			{Name: "solo", Agents: []string{"test-agent"}, Phase: "deploy"},
+			{Agents: nil},
+		},
+	}
+
+	got := messages(Validate(cfg, hubAgents))
+	want := []string{
+		`error: invalid sync_gate "some" (must be 'all' or 'any')`,
+		"error: group build: duplicate group name",
+		`error: group build: invalid sync "most" (must be 'all' or 'any')`,
+		"error: group build: agent frontend-agent is also in group build, which runs concurrently in the implement phase",
+		"error: group build: agent ghost-agent not found in hub agents/",
+		`error: group solo: unknown phase "deploy"`,
+		"warn: group solo: only one agent; nothing runs in parallel",
+		"error: group #4: missing name",
+		"error: group #4: no agents",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestValidate_Disabled(t *testing.T) {
+	cfg := config.ParallelConfig{
+		SyncGate: "all",
+		Groups:   []config.ParallelGroup{{Name: "build", Agents: []string{"backend-agent", "frontend-agent"}}},
+	}
+
+	issues := Validate(cfg, hubAgents)
+	if len(issues) != 1 || issues[0].Severity != "warn" {
+		t.Errorf("Expected a single warning, got %v", messages(issues))
+	}
+}
+
+func TestBuildPlan(t *testing.T) {
+	cfg := config.NewDefaultWorkflowConfig("test")
+	cfg.Transitions.ImplementToReview.Mode = config.TransitionAuto
+	cfg.Parallel = config.ParallelConfig{
+		Enabled:  true,
+		SyncGate: "any",
+		Groups: []config.ParallelGroup{
+			{Name: "build", Agents: []string{"backend-agent", "frontend-agent"}},
+			{Name: "checks", Agents: []string{"test-agent", "review-agent"}, Sync: "all"},
+			{Name: "review", Agents: []string{"review-agent"}, Phase: "review"},
+		},
+	}
+
+	plan := BuildPlan(cfg)
+	if len(plan.Stages) != len(transition.Phases) {
+		t.Fatalf("Expected %d stages, got %d", len(transition.Phases), len(plan.Stages))
+	}
+
+	implement := plan.Stages[2]
+	if implement.Phase != transition.PhaseImplement || len(implement.Groups) != 2 {
+		t.Fatalf("Unexpected implement stage: %+v", implement)
+	}
+	if implement.Groups[0].Sync != "any" {
+		t.Errorf("Expected build to inherit sync_gate, got %s", implement.Groups[0].Sync)
+	}
+	if implement.Transition == nil || implement.Transition.Key != "implement_to_review" || implement.Transition.Mode != config.TransitionAuto {
+		t.Errorf("Unexpected transition: %+v", implement.Transition)
+	}
+	if join := plan.Join(implement); join != "first group to finish: build after its first agent; checks after all 2 agent(s)" {
+		t.Errorf("Unexpected join: %s", join)
+	}
+
+	if len(plan.Stages[3].Groups) != 1 || plan.Stages[3].Groups[0].Name != "review" {
+		t.Errorf("Expected review group in review stage, got %+v", plan.Stages[3])
+	}
+	if len(plan.Stages[0].Groups) != 0 || plan.Join(plan.Stages[0]) != "" {
+		t.Errorf("Expected no groups in idea stage, got %+v", plan.Stages[0])
+	}
+	if plan.Stages[4].Transition != nil {
+		t.Errorf("Expected no transition out of release, got %+v", plan.Stages[4].Transition)
+	}
+}
+
+func TestHubAgents(t *testing.T) {
+	hub := t.TempDir()
+	os.MkdirAll(filepath.Join(hub, "agents", "nested"), 0755)
+	os.WriteFile(filepath.Join(hub, "agents", "backend-agent.md"), []byte("# backend\n"), 0644)
+	os.WriteFile(filepath.Join(hub, "agents", "notes.txt"), []byte("notes\n"), 0644)
+
+	agents := HubAgents(hub)
+	if len(agents) != 1 || agents[0] != "backend-agent" {
+		t.Errorf("Expected [backend-agent], got %v", agents)
+	}
+}
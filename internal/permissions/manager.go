@@ -135,7 +135,7 @@ func (m *Manager) RegenerateAgent(agentName string) error {
 	}
 
 	// Build template data with permissions
-	data := m.buildAgentTemplateData(agentName)
+	data := blueprint.TemplateDataFor(m.workspace.Config, agentName)
 
 	// Render the agent template
 	content, err := m.bpManager.GetAgentContent(bp.ID, agentName, data)
@@ -144,11 +144,17 @@ func (m *Manager) RegenerateAgent(agentName string) error {
 	}
 
 	// Write to the agent file
-	agentPath := filepath.Join(m.workspace.GetHubPath(), "agents", agentName+".md")
-	if err := util.SafeWriteFile(agentPath, content, true); err != nil {
+	hubPath := m.workspace.GetHubPath()
+	relPath := filepath.Join("agents", agentName+".md")
+	if err := util.SafeWriteFile(filepath.Join(hubPath, relPath), content, true); err != nil {
 		return fmt.Errorf("failed to write agent file: %w", err)
 	}
 
+	// Record the rendered content so upgrade treats the file as pristine
+	if err := workspace.RecordManagedFile(hubPath, bp.ID+"/"+relPath, relPath, content); err != nil {
+		return fmt.Errorf("failed to update %s: %w", workspace.ManifestFile, err)
+	}
+
 	return nil
 }
 
@@ -170,59 +176,67 @@ func (m *Manager) GetAgentNames() ([]string, error) {
 	return bp.Agents.Defaults, nil
 }
 
-// buildAgentTemplateData creates TemplateData with permission-aware repo lists
-func (m *Manager) buildAgentTemplateData(agentName string) *blueprint.TemplateData {
-	cfg := m.workspace.Config
-
-	data := &blueprint.TemplateData{
-		WorkflowName:    cfg.Name,
-		DocsRoot:        cfg.State.Root,
-		DocsStateDir:    cfg.State.StateDir,
-		DocsDesignDir:   cfg.State.DesignsDir,
-		TrackerProvider: string(cfg.MCP.Tracker),
-		VCSProvider:     string(cfg.MCP.VCS),
-		HooksEnabled:    cfg.Hooks.Enabled,
-		GatesEnabled:    cfg.Gates.Enabled,
-	}
+// Access levels in a Matrix
+const (
+	AccessWrite = "write"
+	AccessRead  = "read"
+	AccessNone  = "none"
+)
 
-	// Build permission-aware repo lists
-	var allRepos []blueprint.RepoInfo
-	var writeRepos []blueprint.RepoInfo
-	var readRepos []blueprint.RepoInfo
+// Matrix is every agent's access to every repo in the workflow. The tags
+// are the schema of ccflow permissions list --output json|yaml
+// (docs/OUTPUT.md).
+type Matrix struct {
+	Workflow string        `json:"workflow" yaml:"workflow"`
+	Repos    []string      `json:"repos" yaml:"repos"`
+	Agents   []AgentAccess `json:"agents" yaml:"agents"`
+}
+
+// AgentAccess is one agent's row of a Matrix
+type AgentAccess struct {
+	Agent      string            `json:"agent" yaml:"agent"`
+	Restricted bool              `json:"restricted" yaml:"restricted"` // false: no explicit permissions, full access
+	Access     map[string]string `json:"access" yaml:"access"`         // repo name to AccessWrite, AccessRead or AccessNone
+}
 
-	perm, hasPerm := cfg.AgentPermissions[agentName]
+// Matrix returns the access of the blueprint's agents, and of any other
+// agent with explicit permissions, to each repo
+func (m *Manager) Matrix() (*Matrix, error) {
+	agentNames, err := m.GetAgentNames()
+	if err != nil {
+		return nil, err
+	}
 
-	for _, repo := range cfg.Repos {
-		repoInfo := blueprint.RepoInfo{
-			Name: repo.Name,
-			Path: repo.Path,
-			Kind: string(repo.Kind),
+	perms := m.List()
+	var extra []string
+	for agentName := range perms {
+		if !slices.Contains(agentNames, agentName) {
+			extra = append(extra, agentName)
 		}
+	}
+	slices.Sort(extra)
 
-		// If no permissions configured, default to full access
-		if !hasPerm || len(perm.Write) == 0 && len(perm.Read) == 0 {
-			repoInfo.CanWrite = true
-			allRepos = append(allRepos, repoInfo)
-			writeRepos = append(writeRepos, repoInfo)
-		} else {
-			// Check if agent has write access
-			if slices.Contains(perm.Write, repo.Name) {
-				repoInfo.CanWrite = true
-				writeRepos = append(writeRepos, repoInfo)
-				allRepos = append(allRepos, repoInfo)
-			} else if slices.Contains(perm.Read, repo.Name) {
-				repoInfo.CanWrite = false
-				readRepos = append(readRepos, repoInfo)
-				allRepos = append(allRepos, repoInfo)
+	matrix := &Matrix{
+		Workflow: m.workspace.Config.Name,
+		Repos:    append([]string{}, m.GetRepoNames()...),
+		Agents:   []AgentAccess{},
+	}
+	for _, agentName := range append(slices.Clone(agentNames), extra...) {
+		perm, restricted := perms[agentName]
+		row := AgentAccess{Agent: agentName, Restricted: restricted, Access: make(map[string]string)}
+		for _, repo := range matrix.Repos {
+			switch {
+			case !restricted || slices.Contains(perm.Write, repo):
+				row.Access[repo] = AccessWrite
+			case slices.Contains(perm.Read, repo):
+				row.Access[repo] = AccessRead
+			default:
+				row.Access[repo] = AccessNone
 			}
 		}
+		matrix.Agents = append(matrix.Agents, row)
 	}
-
-	data.AllRepos = allRepos
-	data.WriteRepos = writeRepos
-	data.ReadRepos = readRepos
-
-	return data
+	return matrix, nil
 }
 
 // validateRepoNames validates that all repo names exist in the workflow
@@ -0,0 +1,76 @@
+package report
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/Wameedh/ccflow/internal/validator"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes one test case per check. Failed checks carry a
+// <failure>; JUnit has no warnings, so warned checks pass with the warning
+// in <system-out>.
+func writeJUnit(w io.Writer, result *validator.DoctorResult, root string) error {
+	suite := junitSuite{Name: "ccflow doctor", Cases: []junitCase{}}
+	for _, check := range result.Checks {
+		file := relPath(root, check.Path)
+		tc := junitCase{Name: check.Name, Classname: "ccflow.doctor." + ruleID(check.Name), File: file}
+		switch check.Status {
+		case "fail":
+			tc.Failure = &junitFailure{Message: check.Message, Type: "fail", Text: describe(check, file)}
+			suite.Failures++
+		case "warn":
+			tc.SystemOut = "warning: " + describe(check, file)
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Tests = len(suite.Cases)
+
+	doc := junitSuites{
+		Name:     suite.Name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitSuite{suite},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
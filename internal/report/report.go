@@ -0,0 +1,90 @@
+// Package report writes doctor results as JUnit XML and SARIF, so that CI
+// systems can show each check next to test results or code scanning alerts.
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Wameedh/ccflow/internal/validator"
+)
+
+// Report formats
+const (
+	FormatJUnit = "junit"
+	FormatSARIF = "sarif"
+)
+
+// Formats lists the supported report formats
+var Formats = []string{FormatJUnit, FormatSARIF}
+
+// Write writes result to w in format. Files under root are reported
+// relative to it, as CI systems expect paths relative to the checkout.
+func Write(w io.Writer, format string, result *validator.DoctorResult, root string) error {
+	switch format {
+	case FormatJUnit:
+		return writeJUnit(w, result, root)
+	case FormatSARIF:
+		return writeSARIF(w, result, root)
+	default:
+		return fmt.Errorf("unknown report format %q (want %s)", format, strings.Join(Formats, " or "))
+	}
+}
+
+// WriteFile writes result to path in format, creating parent directories
+func WriteFile(path, format string, result *validator.DoctorResult, root string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Write(f, format, result, root); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// relPath returns path relative to root with forward slashes, or path
+// unchanged if it is outside root
+func relPath(root, path string) string {
+	if path == "" || root == "" {
+		return path
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// describe renders a check's message, details, file and remediation as
+// plain text
+func describe(check validator.Check, file string) string {
+	var b strings.Builder
+	b.WriteString(check.Message)
+	for _, detail := range check.Details {
+		fmt.Fprintf(&b, "\n- %s", detail)
+	}
+	if file != "" {
+		fmt.Fprintf(&b, "\nFile: %s", file)
+	}
+	if check.Remediation != "" {
+		fmt.Fprintf(&b, "\nRemediation: %s", check.Remediation)
+	}
+	return b.String()
+}
+
+// ruleID derives a stable identifier from a check name: the part before
+// any colon, so that "Hook: post-edit.sh" and "Hook: end-of-turn.sh" share
+// the rule "hook"
+func ruleID(name string) string {
+	name, _, _ = strings.Cut(name, ":")
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.Join(strings.Fields(name), "-")
+}
@@ -0,0 +1,110 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Wameedh/ccflow/internal/validator"
+)
+
+func testResult(root string) *validator.DoctorResult {
+	return &validator.DoctorResult{
+		Checks: []validator.Check{
+			{Name: "Workflow marker", Status: "pass", Message: "ok", Path: filepath.Join(root, ".ccflow", "workflow.yaml")},
+			{
+				Name:        "Hook: post-edit.sh",
+				Status:      "fail",
+				Message:     "not executable",
+				Remediation: "Run: chmod +x post-edit.sh",
+				Path:        filepath.Join(root, ".claude", "hooks", "post-edit.sh"),
+			},
+			{Name: "Hook: end-of-turn.sh", Status: "warn", Message: "not registered", Details: []string{"Stop"}},
+			{Name: "Symlink: web", Status: "fail", Message: "broken", Path: "/elsewhere/web/.claude"},
+		},
+		Passed:   1,
+		Failed:   2,
+		Warnings: 1,
+	}
+}
+
+func TestWrite_JUnit(t *testing.T) {
+	root := filepath.FromSlash("/work/shop")
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJUnit, testResult(root), root); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var doc junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Invalid XML: %v\n%s", err, buf.String())
+	}
+	if doc.Tests != 4 || doc.Failures != 2 || len(doc.Suites) != 1 {
+		t.Fatalf("Expected 4 tests and 2 failures, got %+v", doc)
+	}
+
+	hook := doc.Suites[0].Cases[1]
+	if hook.File != ".claude/hooks/post-edit.sh" {
+		t.Errorf("Expected a path relative to the root, got %q", hook.File)
+	}
+	if hook.Failure == nil || !strings.Contains(hook.Failure.Text, "Remediation: Run: chmod +x post-edit.sh") {
+		t.Errorf("Expected a failure with the remediation, got %+v", hook.Failure)
+	}
+	if warn := doc.Suites[0].Cases[2]; warn.Failure != nil || !strings.Contains(warn.SystemOut, "- Stop") {
+		t.Errorf("Expected the warning to pass with its details, got %+v", warn)
+	}
+	if pass := doc.Suites[0].Cases[0]; pass.Failure != nil || pass.SystemOut != "" {
+		t.Errorf("Expected a plain passing case, got %+v", pass)
+	}
+}
+
+func TestWrite_SARIF(t *testing.T) {
+	root := filepath.FromSlash("/work/shop")
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatSARIF, testResult(root), root); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Expected one SARIF 2.1.0 run, got %+v", log)
+	}
+	run := log.Runs[0]
+
+	// Passing checks are not findings; both hooks share a rule
+	if len(run.Results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(run.Results))
+	}
+	if len(run.Tool.Driver.Rules) != 2 || run.Tool.Driver.Rules[0].ID != "hook" {
+		t.Errorf("Expected rules hook and symlink, got %+v", run.Tool.Driver.Rules)
+	}
+	if help := run.Tool.Driver.Rules[0].Help; help == nil || help.Text != "Run: chmod +x post-edit.sh" {
+		t.Errorf("Expected the remediation as rule help, got %+v", help)
+	}
+
+	hook := run.Results[0]
+	if hook.Level != "error" || !strings.Contains(hook.Message.Text, "Remediation:") {
+		t.Errorf("Expected an error with the remediation, got %+v", hook)
+	}
+	if loc := hook.Locations[0].PhysicalLocation.ArtifactLocation; loc.URI != ".claude/hooks/post-edit.sh" || loc.URIBaseID != srcRoot {
+		t.Errorf("Expected a location relative to %s, got %+v", srcRoot, loc)
+	}
+	if warn := run.Results[1]; warn.Level != "warning" || len(warn.Locations) != 0 {
+		t.Errorf("Expected a warning without a location, got %+v", warn)
+	}
+	if loc := run.Results[2].Locations[0].PhysicalLocation.ArtifactLocation; loc.URI != "file:///elsewhere/web/.claude" || loc.URIBaseID != "" {
+		t.Errorf("Expected an absolute URI outside the root, got %+v", loc)
+	}
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, "html", testResult("/"), "/"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
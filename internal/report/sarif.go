@@ -0,0 +1,147 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/validator"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	// srcRoot is the base that relative artifact locations resolve against
+	srcRoot = "%SRCROOT%"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                   `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLoc `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult               `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string        `json:"id"`
+	Name             string        `json:"name"`
+	ShortDescription sarifMessage  `json:"shortDescription"`
+	Help             *sarifMessage `json:"help,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLoc `json:"physicalLocation"`
+}
+
+type sarifPhysicalLoc struct {
+	ArtifactLocation sarifArtifactLoc `json:"artifactLocation"`
+}
+
+type sarifArtifactLoc struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// writeSARIF writes a SARIF log with one result per failed or warned
+// check; passing checks are not findings and are left out. Checks with the
+// same rule ID share a rule, whose help is the first remediation seen.
+func writeSARIF(w io.Writer, result *validator.DoctorResult, root string) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "ccflow",
+			Version:        config.Version,
+			InformationURI: "https://github.com/Wameedh/ccflow",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	if root != "" {
+		run.OriginalURIBaseIDs = map[string]sarifArtifactLoc{
+			srcRoot: {URI: fileURI(root) + "/"},
+		}
+	}
+
+	rules := make(map[string]int)
+	for _, check := range result.Checks {
+		var level string
+		switch check.Status {
+		case "fail":
+			level = "error"
+		case "warn":
+			level = "warning"
+		default:
+			continue
+		}
+
+		id := ruleID(check.Name)
+		index, ok := rules[id]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			rules[id] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               id,
+				Name:             check.Name,
+				ShortDescription: sarifMessage{Text: check.Name},
+			})
+		}
+		rule := &run.Tool.Driver.Rules[index]
+		if rule.Help == nil && check.Remediation != "" {
+			rule.Help = &sarifMessage{Text: check.Remediation}
+		}
+
+		file := relPath(root, check.Path)
+		res := sarifResult{
+			RuleID:    id,
+			RuleIndex: index,
+			Level:     level,
+			Message:   sarifMessage{Text: check.Name + ": " + describe(check, "")},
+		}
+		if file != "" {
+			loc := sarifArtifactLoc{URI: file, URIBaseID: srcRoot}
+			if file == check.Path {
+				loc = sarifArtifactLoc{URI: fileURI(file)}
+			}
+			res.Locations = []sarifLocation{{PhysicalLocation: sarifPhysicalLoc{ArtifactLocation: loc}}}
+		}
+		run.Results = append(run.Results, res)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+// fileURI returns a file:// URI for an absolute path
+func fileURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
@@ -0,0 +1,375 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is the subset of JSON Schema (draft-07) used by blueprint templates:
+// type, required, properties, additionalProperties, items, enum, const,
+// pattern, format (date-time, date), length, item count and numeric bounds.
+// Unsupported keywords are ignored.
+type Schema struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Type  Types         `json:"type,omitempty"`
+	Enum  []interface{} `json:"enum,omitempty"`
+	Const interface{}   `json:"const,omitempty"`
+
+	// Objects
+	Required             []string           `json:"required,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Additional        `json:"additionalProperties,omitempty"`
+
+	// Arrays
+	Items    *Schema `json:"items,omitempty"`
+	MinItems *int    `json:"minItems,omitempty"`
+	MaxItems *int    `json:"maxItems,omitempty"`
+
+	// Strings
+	Pattern   string `json:"pattern,omitempty"`
+	Format    string `json:"format,omitempty"`
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+
+	// Numbers
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Types is the "type" keyword, which may be a single type or a list
+type Types []string
+
+// UnmarshalJSON accepts either "string" or ["string", "null"]
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("type must be a string or list of strings")
+	}
+	*t = list
+	return nil
+}
+
+// Additional is the "additionalProperties" keyword: a boolean or a schema
+type Additional struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+// UnmarshalJSON accepts either a boolean or a schema
+func (a *Additional) UnmarshalJSON(data []byte) error {
+	var allowed bool
+	if err := json.Unmarshal(data, &allowed); err == nil {
+		a.Allowed = allowed
+		return nil
+	}
+	a.Allowed = true
+	a.Schema = &Schema{}
+	return json.Unmarshal(data, a.Schema)
+}
+
+// Violation is a single place where a document does not match its schema
+type Violation struct {
+	Pointer string // JSON pointer to the offending value ("" is the document root)
+	Message string
+}
+
+// String formats the violation with its location
+func (v Violation) String() string {
+	pointer := v.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	return fmt.Sprintf("%s: %s", pointer, v.Message)
+}
+
+// Parse parses a JSON schema and compiles its patterns
+func Parse(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	if err := s.compile(""); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// compile compiles pattern regexps throughout the schema
+func (s *Schema) compile(pointer string) error {
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern at %s: %w", pointer, err)
+		}
+		s.pattern = re
+	}
+	for name, prop := range s.Properties {
+		if err := prop.compile(pointer + "/properties/" + escape(name)); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		if err := s.Items.compile(pointer + "/items"); err != nil {
+			return err
+		}
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+		if err := s.AdditionalProperties.Schema.compile(pointer + "/additionalProperties"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateJSON decodes a JSON document and validates it
+func (s *Schema) ValidateJSON(data []byte) ([]Violation, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return s.Validate(doc), nil
+}
+
+// Validate checks a decoded JSON document (as produced by encoding/json)
+// and returns every violation, ordered by pointer
+func (s *Schema) Validate(doc interface{}) []Violation {
+	var violations []Violation
+	s.validate(doc, "", &violations)
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Pointer < violations[j].Pointer
+	})
+	return violations
+}
+
+// EnumStrings returns the string values of a property's enum, in order
+func (s *Schema) EnumStrings(property string) []string {
+	prop, ok := s.Properties[property]
+	if !ok {
+		return nil
+	}
+	var values []string
+	for _, v := range prop.Enum {
+		if str, ok := v.(string); ok {
+			values = append(values, str)
+		}
+	}
+	return values
+}
+
+// validate appends the violations of value against s
+func (s *Schema) validate(value interface{}, pointer string, violations *[]Violation) {
+	add := func(format string, args ...interface{}) {
+		*violations = append(*violations, Violation{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.Type) > 0 && !s.matchesType(value) {
+		add("expected %s, got %s", strings.Join(s.Type, " or "), typeOf(value))
+		return
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, allowed := range s.Enum {
+			if equal(value, allowed) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			add("value %s is not one of %s", display(value), displayList(s.Enum))
+		}
+	}
+	if s.Const != nil && !equal(value, s.Const) {
+		add("value %s must be %s", display(value), display(s.Const))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		s.validateObject(v, pointer, violations)
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			add("expected at least %d items, got %d", *s.MinItems, len(v))
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			add("expected at most %d items, got %d", *s.MaxItems, len(v))
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(item, pointer+"/"+strconv.Itoa(i), violations)
+			}
+		}
+	case string:
+		length := len([]rune(v))
+		if s.MinLength != nil && length < *s.MinLength {
+			add("expected at least %d characters, got %d", *s.MinLength, length)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			add("expected at most %d characters, got %d", *s.MaxLength, length)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			add("value %q does not match pattern %s", v, s.Pattern)
+		}
+		if msg := checkFormat(s.Format, v); msg != "" {
+			add("%s", msg)
+		}
+	default:
+		if n, ok := toFloat(value); ok {
+			if s.Minimum != nil && n < *s.Minimum {
+				add("value %v is less than minimum %v", n, *s.Minimum)
+			}
+			if s.Maximum != nil && n > *s.Maximum {
+				add("value %v is greater than maximum %v", n, *s.Maximum)
+			}
+		}
+	}
+}
+
+// validateObject checks required, properties and additionalProperties
+func (s *Schema) validateObject(obj map[string]interface{}, pointer string, violations *[]Violation) {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			*violations = append(*violations, Violation{
+				Pointer: pointer + "/" + escape(name),
+				Message: "required property is missing",
+			})
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		child := pointer + "/" + escape(name)
+		if prop, ok := s.Properties[name]; ok {
+			prop.validate(obj[name], child, violations)
+			continue
+		}
+		if s.AdditionalProperties == nil {
+			continue
+		}
+		if !s.AdditionalProperties.Allowed {
+			*violations = append(*violations, Violation{Pointer: child, Message: "property is not allowed"})
+		} else if s.AdditionalProperties.Schema != nil {
+			s.AdditionalProperties.Schema.validate(obj[name], child, violations)
+		}
+	}
+}
+
+// matchesType reports whether value satisfies any of the schema's types
+func (s *Schema) matchesType(value interface{}) bool {
+	actual := typeOf(value)
+	for _, t := range s.Type {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf returns the JSON Schema type name of a decoded value
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		if n, ok := toFloat(v); ok {
+			if n == math.Trunc(n) {
+				return "integer"
+			}
+			return "number"
+		}
+	}
+	return "unknown"
+}
+
+// toFloat converts a decoded JSON number to float64
+func toFloat(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// equal compares decoded JSON values, treating numbers by value
+func equal(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// checkFormat validates the formats used by blueprint schemas
+func checkFormat(format, value string) string {
+	switch format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return fmt.Sprintf("value %q is not an RFC 3339 date-time", value)
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return fmt.Sprintf("value %q is not a date (YYYY-MM-DD)", value)
+		}
+	}
+	return ""
+}
+
+// display formats a value for messages
+func display(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// displayList formats enum values for messages
+func displayList(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = display(v)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// escape escapes a property name for use in a JSON pointer (RFC 6901)
+func escape(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
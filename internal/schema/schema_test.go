@@ -0,0 +1,128 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const featureSchema = `{
+  "type": "object",
+  "required": ["id", "title", "status", "created_at"],
+  "properties": {
+    "id": {"type": "string", "pattern": "^[a-z0-9-]+$"},
+    "title": {"type": "string", "minLength": 1},
+    "status": {"type": "string", "enum": ["ideation", "design", "released"]},
+    "created_at": {"type": "string", "format": "date-time"},
+    "tags": {"type": "array", "items": {"type": "string"}},
+    "cost": {
+      "type": "object",
+      "properties": {"monthly": {"type": "number", "minimum": 0}},
+      "additionalProperties": false
+    },
+    "size": {"type": ["integer", "null"]}
+  }
+}`
+
+func TestValidate_Valid(t *testing.T) {
+	s, err := Parse([]byte(featureSchema))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	violations, err := s.ValidateJSON([]byte(`{
+		"id": "login-page",
+		"title": "Login page",
+		"status": "design",
+		"created_at": "2024-05-01T10:00:00Z",
+		"tags": ["auth"],
+		"cost": {"monthly": 12.5},
+		"size": null,
+		"extra": true
+	}`))
+	if err != nil {
+		t.Fatalf("ValidateJSON failed: %v", err)
+	}
+	if len(violations) != 0 {
+		t.Errorf("Expected no violations, got %v", violations)
+	}
+}
+
+func TestValidate_Violations(t *testing.T) {
+	s, err := Parse([]byte(featureSchema))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	violations, err := s.ValidateJSON([]byte(`{
+		"id": "Login Page",
+		"title": "",
+		"status": "shipped",
+		"tags": ["auth", 3],
+		"cost": {"monthly": -1, "yearly": 5},
+		"size": 1.5
+	}`))
+	if err != nil {
+		t.Fatalf("ValidateJSON failed: %v", err)
+	}
+
+	var pointers []string
+	for _, v := range violations {
+		pointers = append(pointers, v.Pointer)
+	}
+	want := []string{"/cost/monthly", "/cost/yearly", "/created_at", "/id", "/size", "/status", "/tags/1", "/title"}
+	if !slices.Equal(pointers, want) {
+		t.Errorf("Unexpected violations:\n got %v\nwant %v\n%v", pointers, want, violations)
+	}
+}
+
+func TestValidate_DateTimeFormat(t *testing.T) {
+	s, err := Parse([]byte(`{"properties": {"at": {"type": "string", "format": "date-time"}}}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	violations, _ := s.ValidateJSON([]byte(`{"at": "yesterday"}`))
+	if len(violations) != 1 || violations[0].Pointer != "/at" {
+		t.Errorf("Expected date-time violation at /at, got %v", violations)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	if _, err := Parse([]byte(`{`)); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+	if _, err := Parse([]byte(`{"properties": {"id": {"pattern": "("}}}`)); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+func TestEnumStrings(t *testing.T) {
+	s, err := Parse([]byte(featureSchema))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if got := s.EnumStrings("status"); !slices.Equal(got, []string{"ideation", "design", "released"}) {
+		t.Errorf("Unexpected enum: %v", got)
+	}
+	if got := s.EnumStrings("missing"); got != nil {
+		t.Errorf("Expected nil for missing property, got %v", got)
+	}
+}
+
+func TestParse_BlueprintSchemas(t *testing.T) {
+	paths, err := filepath.Glob("../blueprint/*/templates/*.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Parse(data); err != nil {
+			t.Errorf("%s: %v", p, err)
+		}
+	}
+}
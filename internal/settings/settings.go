@@ -0,0 +1,457 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Wameedh/ccflow/internal/jsonobj"
+	"github.com/Wameedh/ccflow/internal/util"
+)
+
+// FileName is the Claude Code settings file in a .claude directory
+const FileName = "settings.json"
+
+// HookTypeCommand is the hook type that runs a shell command
+const HookTypeCommand = "command"
+
+// eventOrder is the order hook events are written in when a file has no
+// order of its own; unknown events follow in alphabetical order
+var eventOrder = []string{
+	"PreToolUse", "PostToolUse", "UserPromptSubmit", "Notification",
+	"Stop", "SubagentStop", "PreCompact", "SessionStart", "SessionEnd",
+}
+
+// keyOrder is the order top-level keys are written in for new files
+var keyOrder = []string{"$schema", "permissions", "env", "hooks"}
+
+// Hook is a single hook action. Keys it doesn't model, e.g. statusMessage,
+// are kept as read.
+type Hook struct {
+	Type    string
+	Command string
+	Timeout int
+
+	doc *jsonobj.Object // keys as read, including ones this type doesn't model
+}
+
+// Matcher groups hooks that run for the tools matching a pattern. An empty
+// matcher matches every tool, or applies to events without tools (e.g. Stop).
+// Keys it doesn't model are kept as read.
+type Matcher struct {
+	Matcher string
+	Hooks   []Hook
+
+	doc *jsonobj.Object // keys as read, including ones this type doesn't model
+}
+
+// UnmarshalJSON decodes a hook, keeping unmodeled keys
+func (h *Hook) UnmarshalJSON(data []byte) error {
+	doc, err := jsonobj.Parse(data)
+	if err != nil {
+		return err
+	}
+	*h = Hook{doc: doc}
+	return decodeFields(doc, map[string]interface{}{
+		"type":    &h.Type,
+		"command": &h.Command,
+		"timeout": &h.Timeout,
+	})
+}
+
+// MarshalJSON encodes a hook in the key order it was read in
+func (h Hook) MarshalJSON() ([]byte, error) {
+	doc := h.doc.Clone()
+	if err := doc.Set("type", h.Type); err != nil {
+		return nil, err
+	}
+	if err := doc.Set("command", h.Command); err != nil {
+		return nil, err
+	}
+	if err := doc.SetUnlessEmpty("timeout", h.Timeout, h.Timeout != 0); err != nil {
+		return nil, err
+	}
+	return doc.Marshal(), nil
+}
+
+// UnmarshalJSON decodes a matcher, keeping unmodeled keys
+func (m *Matcher) UnmarshalJSON(data []byte) error {
+	doc, err := jsonobj.Parse(data)
+	if err != nil {
+		return err
+	}
+	*m = Matcher{doc: doc}
+	return decodeFields(doc, map[string]interface{}{
+		"matcher": &m.Matcher,
+		"hooks":   &m.Hooks,
+	})
+}
+
+// MarshalJSON encodes a matcher in the key order it was read in
+func (m Matcher) MarshalJSON() ([]byte, error) {
+	doc := m.doc.Clone()
+	if err := doc.SetUnlessEmpty("matcher", m.Matcher, m.Matcher != ""); err != nil {
+		return nil, err
+	}
+	hooks := m.Hooks
+	if hooks == nil {
+		hooks = []Hook{}
+	}
+	if err := doc.Set("hooks", hooks); err != nil {
+		return nil, err
+	}
+	return doc.Marshal(), nil
+}
+
+// Permissions controls which tools Claude Code may use without asking
+type Permissions struct {
+	Allow                 []string
+	Deny                  []string
+	Ask                   []string
+	AdditionalDirectories []string
+	DefaultMode           string
+
+	doc *jsonobj.Object // keys as read, including ones this type doesn't model
+}
+
+// Settings is a parsed settings.json. Fields ccflow doesn't model are kept
+// as-is, and keys are written back in the order they were read.
+type Settings struct {
+	Permissions Permissions
+	Env         map[string]string
+	Hooks       map[string][]Matcher // event name → matchers
+
+	doc       *jsonobj.Object
+	hookOrder []string // event order as read
+}
+
+// New returns empty settings
+func New() *Settings {
+	doc := jsonobj.New()
+	doc.SetKeyOrder(keyOrder)
+	return &Settings{
+		Env:         make(map[string]string),
+		Hooks:       make(map[string][]Matcher),
+		Permissions: Permissions{doc: jsonobj.New()},
+		doc:         doc,
+	}
+}
+
+// Parse parses settings.json content
+func Parse(data []byte) (*Settings, error) {
+	s := New()
+
+	doc, err := jsonobj.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	doc.SetKeyOrder(keyOrder)
+	s.doc = doc
+
+	if raw, ok := doc.Get("permissions"); ok {
+		if s.Permissions.doc, err = jsonobj.Parse(raw); err != nil {
+			return nil, fmt.Errorf("permissions: %w", err)
+		}
+		if err := s.Permissions.decode(); err != nil {
+			return nil, fmt.Errorf("permissions: %w", err)
+		}
+	}
+
+	if raw, ok := doc.Get("env"); ok {
+		if err := json.Unmarshal(raw, &s.Env); err != nil {
+			return nil, fmt.Errorf("env: %w", err)
+		}
+		if s.Env == nil {
+			s.Env = make(map[string]string)
+		}
+	}
+
+	if raw, ok := doc.Get("hooks"); ok {
+		hooks, err := jsonobj.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("hooks: %w", err)
+		}
+		for _, event := range hooks.Keys() {
+			var matchers []Matcher
+			raw, _ := hooks.Get(event)
+			if err := json.Unmarshal(raw, &matchers); err != nil {
+				return nil, fmt.Errorf("hooks.%s: %w", event, err)
+			}
+			s.Hooks[event] = matchers
+		}
+		s.hookOrder = hooks.Keys()
+	}
+
+	return s, nil
+}
+
+// Load reads settings from path. A missing file yields empty settings.
+func Load(path string) (*Settings, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+
+	s, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	return s, nil
+}
+
+// Save writes settings to path
+func (s *Settings) Save(path string) error {
+	data, err := s.Marshal()
+	if err != nil {
+		return err
+	}
+	return util.SafeWriteFile(path, data, true)
+}
+
+// Marshal returns the settings as indented JSON. Keys keep the order they
+// were read in; new keys follow.
+func (s *Settings) Marshal() ([]byte, error) {
+	doc := s.doc.Clone()
+
+	perms, err := s.Permissions.marshal()
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.SetUnlessEmpty("permissions", perms, !s.Permissions.isEmpty()); err != nil {
+		return nil, err
+	}
+	env := s.Env
+	if env == nil {
+		env = map[string]string{}
+	}
+	if err := doc.SetUnlessEmpty("env", env, len(env) > 0); err != nil {
+		return nil, err
+	}
+
+	hooks := jsonobj.New()
+	for _, event := range s.events() {
+		if err := hooks.Set(event, s.Hooks[event]); err != nil {
+			return nil, err
+		}
+	}
+	if err := doc.SetUnlessEmpty("hooks", json.RawMessage(hooks.Marshal()), hooks.Len() > 0); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, doc.Marshal(), "", "  "); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// events returns the hook events with matchers, in file order followed by
+// new events in canonical order
+func (s *Settings) events() []string {
+	var events []string
+	for _, event := range s.hookOrder {
+		if len(s.Hooks[event]) > 0 {
+			events = append(events, event)
+		}
+	}
+
+	var added []string
+	for event, matchers := range s.Hooks {
+		if len(matchers) > 0 && !slices.Contains(events, event) {
+			added = append(added, event)
+		}
+	}
+	sort.Slice(added, func(i, j int) bool {
+		a, b := slices.Index(eventOrder, added[i]), slices.Index(eventOrder, added[j])
+		if a < 0 && b < 0 {
+			return added[i] < added[j]
+		}
+		if a < 0 || b < 0 {
+			return b < 0
+		}
+		return a < b
+	})
+	return append(events, added...)
+}
+
+// HookRef identifies a command hook registered for an event
+type HookRef struct {
+	Event   string
+	Matcher string
+	Command string
+}
+
+// CommandHooks returns every command hook in event order
+func (s *Settings) CommandHooks() []HookRef {
+	var refs []HookRef
+	for _, event := range s.events() {
+		for _, m := range s.Hooks[event] {
+			for _, h := range m.Hooks {
+				if h.Type == HookTypeCommand {
+					refs = append(refs, HookRef{Event: event, Matcher: m.Matcher, Command: h.Command})
+				}
+			}
+		}
+	}
+	return refs
+}
+
+// AddHook registers a command for an event unless the event already runs it.
+// It reports whether the hook was added.
+func (s *Settings) AddHook(event, matcher, command string) bool {
+	if s.hasHook(event, command) {
+		return false
+	}
+
+	s.Hooks[event] = append(s.Hooks[event], Matcher{
+		Matcher: matcher,
+		Hooks:   []Hook{{Type: HookTypeCommand, Command: command}},
+	})
+	return true
+}
+
+// hasHook reports whether the event runs the command
+func (s *Settings) hasHook(event, command string) bool {
+	for _, m := range s.Hooks[event] {
+		for _, h := range m.Hooks {
+			if sameCommand(h.Command, command) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RemoveHook removes a command from every event, dropping matchers and
+// events left empty. It returns the events the command was removed from.
+func (s *Settings) RemoveHook(command string) []string {
+	var removed []string
+	for _, event := range s.events() {
+		var kept []Matcher
+		found := false
+		for _, m := range s.Hooks[event] {
+			var hooks []Hook
+			for _, h := range m.Hooks {
+				if sameCommand(h.Command, command) {
+					found = true
+				} else {
+					hooks = append(hooks, h)
+				}
+			}
+			if len(hooks) > 0 {
+				m.Hooks = hooks
+				kept = append(kept, m)
+			}
+		}
+		if found {
+			removed = append(removed, event)
+			if len(kept) == 0 {
+				delete(s.Hooks, event)
+			} else {
+				s.Hooks[event] = kept
+			}
+		}
+	}
+
+	// Removing the last hook leaves no "hooks": {} behind, which settings
+	// rendered without the hook don't have
+	if len(removed) > 0 && len(s.events()) == 0 {
+		s.doc.Delete("hooks")
+	}
+	return removed
+}
+
+// ScriptPath returns the script a hook command runs, relative to the .claude
+// directory, or "" for inline commands. Commands may refer to scripts as
+// ./hooks/x.sh, hooks/x.sh or $CLAUDE_PROJECT_DIR/.claude/hooks/x.sh.
+func ScriptPath(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return ""
+	}
+	script := strings.Trim(fields[0], `"'`)
+	for _, prefix := range []string{"$CLAUDE_PROJECT_DIR/.claude/", "${CLAUDE_PROJECT_DIR}/.claude/", `$CLAUDE_PROJECT_DIR"/.claude/`} {
+		script = strings.TrimPrefix(script, prefix)
+	}
+	if !strings.Contains(script, "/") || filepath.IsAbs(script) {
+		return ""
+	}
+	return filepath.Clean(script)
+}
+
+// sameCommand reports whether two commands run the same script or command
+func sameCommand(a, b string) bool {
+	if a == b {
+		return true
+	}
+	sa, sb := ScriptPath(a), ScriptPath(b)
+	return sa != "" && sa == sb
+}
+
+// isEmpty reports whether no permissions are set and none were read
+func (p *Permissions) isEmpty() bool {
+	return p.doc.Len() == 0 && len(p.Allow) == 0 && len(p.Deny) == 0 &&
+		len(p.Ask) == 0 && len(p.AdditionalDirectories) == 0 && p.DefaultMode == ""
+}
+
+// decode fills the modeled fields from the permissions object
+func (p *Permissions) decode() error {
+	return decodeFields(p.doc, map[string]interface{}{
+		"allow":                 &p.Allow,
+		"deny":                  &p.Deny,
+		"ask":                   &p.Ask,
+		"additionalDirectories": &p.AdditionalDirectories,
+		"defaultMode":           &p.DefaultMode,
+	})
+}
+
+// decodeFields decodes the keys of an object present in fields into their
+// destinations
+func decodeFields(doc *jsonobj.Object, fields map[string]interface{}) error {
+	for key, dst := range fields {
+		if raw, ok := doc.Get(key); ok {
+			if err := json.Unmarshal(raw, dst); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// marshal encodes the permissions, keeping unmodeled keys
+func (p *Permissions) marshal() (json.RawMessage, error) {
+	doc := p.doc.Clone()
+	for _, f := range []struct {
+		key   string
+		value interface{}
+		set   bool
+	}{
+		{"allow", nonNil(p.Allow), len(p.Allow) > 0},
+		{"deny", nonNil(p.Deny), len(p.Deny) > 0},
+		{"ask", nonNil(p.Ask), len(p.Ask) > 0},
+		{"additionalDirectories", nonNil(p.AdditionalDirectories), len(p.AdditionalDirectories) > 0},
+		{"defaultMode", p.DefaultMode, p.DefaultMode != ""},
+	} {
+		if err := doc.SetUnlessEmpty(f.key, f.value, f.set); err != nil {
+			return nil, err
+		}
+	}
+	return doc.Marshal(), nil
+}
+
+// nonNil returns an empty list for nil, so it encodes as [] rather than null
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
@@ -0,0 +1,289 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSettings = `{
+  "model": "sonnet",
+  "hooks": {
+    "Stop": [
+      {
+        "hooks": [
+          {
+            "type": "command",
+            "command": "./hooks/end-of-turn.sh"
+          }
+        ]
+      }
+    ],
+    "PostToolUse": [
+      {
+        "matcher": "Write|Edit",
+        "hooks": [
+          {
+            "type": "command",
+            "command": "./hooks/post-edit.sh",
+            "timeout": 30
+          }
+        ]
+      }
+    ]
+  },
+  "permissions": {
+    "allow": [
+      "Bash(go test:*)"
+    ],
+    "disableBypassPermissionsMode": "disable"
+  },
+  "statusLine": {
+    "type": "command",
+    "command": "echo hi"
+  }
+}
+`
+
+func TestParse_RoundTrip(t *testing.T) {
+	s, err := Parse([]byte(testSettings))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(s.Permissions.Allow) != 1 || s.Permissions.Allow[0] != "Bash(go test:*)" {
+		t.Errorf("Unexpected permissions: %+v", s.Permissions)
+	}
+	if m := s.Hooks["PostToolUse"]; len(m) != 1 || m[0].Matcher != "Write|Edit" || m[0].Hooks[0].Timeout != 30 {
+		t.Errorf("Unexpected PostToolUse hooks: %+v", m)
+	}
+
+	// Unchanged settings are written back byte for byte
+	data, err := s.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(data) != testSettings {
+		t.Errorf("Round trip changed the file:\n%s", data)
+	}
+}
+
+func TestParse_KeepsUnknownHookKeys(t *testing.T) {
+	const input = `{
+  "hooks": {
+    "PostToolUse": [
+      {
+        "matcher": "Write|Edit",
+        "description": "format on save",
+        "hooks": [
+          {
+            "type": "command",
+            "command": "./hooks/post-edit.sh",
+            "statusMessage": "Formatting...",
+            "timeout": 30
+          },
+          {
+            "type": "command",
+            "command": "./hooks/lint.sh"
+          }
+        ]
+      }
+    ]
+  }
+}
+`
+	s, err := Parse([]byte(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	data, err := s.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(data) != input {
+		t.Errorf("Round trip changed the file:\n%s", data)
+	}
+
+	// Rewriting the file after a change keeps the other hooks' keys
+	s.RemoveHook("./hooks/lint.sh")
+	s.AddHook("Stop", "", "./hooks/end-of-turn.sh")
+	data, err = s.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	for _, want := range []string{`"description": "format on save"`, `"statusMessage": "Formatting..."`, `"timeout": 30`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %s to be kept:\n%s", want, data)
+		}
+	}
+	if strings.Contains(string(data), "lint.sh") || !strings.Contains(string(data), "end-of-turn.sh") {
+		t.Errorf("Expected lint.sh replaced by end-of-turn.sh:\n%s", data)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, data := range []string{
+		`not json`,
+		`[]`,
+		`{"hooks": [{"event": "Stop", "script": "./hooks/end-of-turn.sh"}]}`,
+		`{"env": {"DEBUG": 1}}`,
+		`{"permissions": {"allow": "Bash"}}`,
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Expected error for %s", data)
+		}
+	}
+}
+
+func TestAddHook(t *testing.T) {
+	s, err := Parse([]byte(testSettings))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// Same script, different spelling
+	if s.AddHook("Stop", "", "hooks/end-of-turn.sh") {
+		t.Error("Expected existing hook not to be added again")
+	}
+	if !s.AddHook("PreToolUse", "Bash", "./hooks/guard.sh") {
+		t.Error("Expected new hook to be added")
+	}
+	if !s.AddHook("Stop", "", "./hooks/notify.sh") {
+		t.Error("Expected second Stop hook to be added")
+	}
+
+	var got []string
+	for _, ref := range s.CommandHooks() {
+		got = append(got, ref.Event+" "+ref.Command)
+	}
+	want := []string{
+		"Stop ./hooks/end-of-turn.sh",
+		"Stop ./hooks/notify.sh",
+		"PostToolUse ./hooks/post-edit.sh",
+		"PreToolUse ./hooks/guard.sh",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected hooks:\n%s", strings.Join(got, "\n"))
+	}
+
+	// Existing events keep their order; new events follow
+	data, _ := s.Marshal()
+	stop := strings.Index(string(data), `"Stop"`)
+	post := strings.Index(string(data), `"PostToolUse"`)
+	pre := strings.Index(string(data), `"PreToolUse"`)
+	if !(stop < post && post < pre) {
+		t.Errorf("Unexpected event order:\n%s", data)
+	}
+}
+
+func TestRemoveHook(t *testing.T) {
+	s, _ := Parse([]byte(testSettings))
+	s.AddHook("PostToolUse", "Write", "./hooks/lint.sh")
+
+	if events := s.RemoveHook("./hooks/post-edit.sh"); len(events) != 1 || events[0] != "PostToolUse" {
+		t.Errorf("Expected removal from PostToolUse, got %v", events)
+	}
+	if m := s.Hooks["PostToolUse"]; len(m) != 1 || m[0].Hooks[0].Command != "./hooks/lint.sh" {
+		t.Errorf("Expected lint hook to remain, got %+v", m)
+	}
+
+	if events := s.RemoveHook("./hooks/end-of-turn.sh"); len(events) != 1 {
+		t.Errorf("Expected removal from Stop, got %v", events)
+	}
+	if _, ok := s.Hooks["Stop"]; ok {
+		t.Error("Expected empty Stop event to be dropped")
+	}
+	if events := s.RemoveHook("./hooks/missing.sh"); len(events) != 0 {
+		t.Errorf("Expected nothing removed, got %v", events)
+	}
+}
+
+func TestRemoveHook_LastHook(t *testing.T) {
+	s, err := Parse([]byte(`{"hooks": {"Stop": [{"hooks": [{"type": "command", "command": "./hooks/lint.sh"}]}]}}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	s.RemoveHook("./hooks/lint.sh")
+
+	data, err := s.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(data) != "{}\n" {
+		t.Errorf("Expected the emptied hooks object to be dropped, got:\n%s", data)
+	}
+
+	// An empty hooks object ccflow didn't empty is kept
+	s, _ = Parse([]byte(`{"hooks": {}}`))
+	s.RemoveHook("./hooks/lint.sh")
+	if data, _ := s.Marshal(); !strings.Contains(string(data), `"hooks"`) {
+		t.Errorf("Expected the hooks object to be kept, got:\n%s", data)
+	}
+}
+
+func TestNewFileKeyOrder(t *testing.T) {
+	s := New()
+	s.AddHook("Stop", "", "./hooks/end-of-turn.sh")
+	s.AddHook("PostToolUse", "Write|Edit", "./hooks/post-edit.sh")
+	s.Env["CCFLOW"] = "1"
+	s.Permissions.Deny = []string{"Read(.env)"}
+
+	data, err := s.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	order := []string{`"permissions"`, `"env"`, `"hooks"`, `"PostToolUse"`, `"Stop"`}
+	last := -1
+	for _, key := range order {
+		i := strings.Index(string(data), key)
+		if i < last {
+			t.Fatalf("Expected %v in order, got:\n%s", order, data)
+		}
+		last = i
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".claude", FileName)
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load of missing file failed: %v", err)
+	}
+	s.AddHook("Stop", "", "./hooks/end-of-turn.sh")
+	if err := s.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if refs := loaded.CommandHooks(); len(refs) != 1 || refs[0].Command != "./hooks/end-of-turn.sh" {
+		t.Errorf("Unexpected hooks after reload: %+v", refs)
+	}
+
+	os.WriteFile(path, []byte("{"), 0644)
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for invalid file")
+	}
+}
+
+func TestScriptPath(t *testing.T) {
+	tests := map[string]string{
+		"./hooks/post-edit.sh":                             "hooks/post-edit.sh",
+		"hooks/post-edit.sh --fast":                        "hooks/post-edit.sh",
+		`"$CLAUDE_PROJECT_DIR"/.claude/hooks/post-edit.sh`: "hooks/post-edit.sh",
+		"$CLAUDE_PROJECT_DIR/.claude/hooks/end-of-turn.sh": "hooks/end-of-turn.sh",
+		"npm run lint":                                     "",
+		"/usr/local/bin/notify":                            "",
+		"":                                                 "",
+	}
+	for command, want := range tests {
+		if got := ScriptPath(command); got != want {
+			t.Errorf("ScriptPath(%q) = %q, want %q", command, got, want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package settings
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Upgrade adds to s what next introduces over base: permission rules, env
+// variables, hook commands and top-level keys that next has and base lacks.
+// Whatever the user added, changed or removed since base is left alone. A
+// nil base treats everything in next as new. It returns a description of
+// each change.
+func (s *Settings) Upgrade(base, next *Settings) []string {
+	if base == nil {
+		base = New()
+	}
+	var changes []string
+
+	rules := []struct {
+		key                string
+		list               *[]string
+		baseList, nextList []string
+	}{
+		{"allow", &s.Permissions.Allow, base.Permissions.Allow, next.Permissions.Allow},
+		{"deny", &s.Permissions.Deny, base.Permissions.Deny, next.Permissions.Deny},
+		{"ask", &s.Permissions.Ask, base.Permissions.Ask, next.Permissions.Ask},
+		{"additionalDirectories", &s.Permissions.AdditionalDirectories, base.Permissions.AdditionalDirectories, next.Permissions.AdditionalDirectories},
+	}
+	for _, r := range rules {
+		for _, rule := range r.nextList {
+			if !slices.Contains(*r.list, rule) && !slices.Contains(r.baseList, rule) {
+				*r.list = append(*r.list, rule)
+				changes = append(changes, fmt.Sprintf("permissions.%s: %s", r.key, rule))
+			}
+		}
+	}
+	if s.Permissions.DefaultMode == "" && base.Permissions.DefaultMode == "" && next.Permissions.DefaultMode != "" {
+		s.Permissions.DefaultMode = next.Permissions.DefaultMode
+		changes = append(changes, "permissions.defaultMode: "+next.Permissions.DefaultMode)
+	}
+
+	var names []string
+	for name := range next.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, inBase := base.Env[name]
+		if _, ok := s.Env[name]; !ok && !inBase {
+			s.Env[name] = next.Env[name]
+			changes = append(changes, "env."+name)
+		}
+	}
+
+	for _, ref := range next.CommandHooks() {
+		if !base.hasHook(ref.Event, ref.Command) && s.AddHook(ref.Event, ref.Matcher, ref.Command) {
+			changes = append(changes, fmt.Sprintf("hooks.%s: %s", ref.Event, ref.Command))
+		}
+	}
+
+	for _, key := range next.doc.Keys() {
+		if key == "permissions" || key == "env" || key == "hooks" {
+			continue // merged above
+		}
+		_, inBase := base.doc.Get(key)
+		if _, ok := s.doc.Get(key); !ok && !inBase {
+			raw, _ := next.doc.Get(key)
+			if err := s.doc.Set(key, raw); err == nil {
+				changes = append(changes, key)
+			}
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,72 @@
+package settings
+
+import (
+	"reflect"
+	"testing"
+)
+
+func mustParse(t *testing.T, data string) *Settings {
+	t.Helper()
+	s, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return s
+}
+
+func TestUpgrade(t *testing.T) {
+	base := mustParse(t, `{
+  "permissions": {"allow": ["Bash(npm:*)", "Bash(rm:*)"]},
+  "hooks": {"Stop": [{"hooks": [{"type": "command", "command": "./hooks/end-of-turn.sh"}]}]}
+}`)
+	// The user dropped Bash(rm:*) and the Stop hook, and added their own rule
+	user := mustParse(t, `{
+  "permissions": {"allow": ["Bash(npm:*)", "Bash(make:*)"]},
+  "env": {"DEBUG": "1"}
+}`)
+	next := mustParse(t, `{
+  "permissions": {"allow": ["Bash(npm:*)", "Bash(rm:*)", "Bash(go:*)"], "deny": ["Read(.env)"]},
+  "env": {"DEBUG": "0", "CI": "true"},
+  "hooks": {
+    "Stop": [{"hooks": [{"type": "command", "command": "./hooks/end-of-turn.sh"}]}],
+    "PostToolUse": [{"matcher": "Write|Edit", "hooks": [{"type": "command", "command": "./hooks/post-edit.sh"}]}]
+  },
+  "statusLine": {"type": "command", "command": "ccflow status"}
+}`)
+
+	got := user.Upgrade(base, next)
+	want := []string{
+		"permissions.allow: Bash(go:*)",
+		"permissions.deny: Read(.env)",
+		"env.CI",
+		"hooks.PostToolUse: ./hooks/post-edit.sh",
+		"statusLine",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Upgrade() = %v\nwant %v", got, want)
+	}
+
+	if !reflect.DeepEqual(user.Permissions.Allow, []string{"Bash(npm:*)", "Bash(make:*)", "Bash(go:*)"}) {
+		t.Errorf("Unexpected allow rules: %v", user.Permissions.Allow)
+	}
+	if user.Env["DEBUG"] != "1" {
+		t.Errorf("Expected the user's DEBUG to be kept, got %q", user.Env["DEBUG"])
+	}
+	if len(user.Hooks["Stop"]) != 0 {
+		t.Errorf("Expected the removed Stop hook to stay removed, got %+v", user.Hooks["Stop"])
+	}
+
+	if again := user.Upgrade(base, next); len(again) != 0 {
+		t.Errorf("Expected a second upgrade to change nothing, got %v", again)
+	}
+}
+
+func TestUpgrade_NoBase(t *testing.T) {
+	user := mustParse(t, `{"permissions": {"allow": ["Bash(npm:*)"]}}`)
+	next := mustParse(t, `{"permissions": {"allow": ["Bash(npm:*)", "Bash(rm:*)"]}}`)
+
+	got := user.Upgrade(nil, next)
+	if !reflect.DeepEqual(got, []string{"permissions.allow: Bash(rm:*)"}) {
+		t.Errorf("Upgrade() = %v", got)
+	}
+}
@@ -0,0 +1,408 @@
+// Package snapshot records files before a command changes them, so that
+// the change can be rolled back.
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Wameedh/ccflow/internal/util"
+)
+
+const (
+	// StoreDir holds a workspace's snapshots, relative to its root. It sits
+	// in the workspace root so that it outlives the hub and .ccflow
+	// directories the commands it protects may remove.
+	StoreDir = ".ccflow/snapshots"
+	// JournalFile describes a snapshot, inside its directory
+	JournalFile = "journal.json"
+	// Keep is the number of snapshots kept; older ones are pruned
+	Keep = 20
+
+	filesDir = "files"
+	idFormat = "20060102-150405.000"
+)
+
+// Kind is what a path was when the snapshot was taken
+type Kind string
+
+const (
+	KindFile    Kind = "file"
+	KindSymlink Kind = "symlink"
+	KindDir     Kind = "dir"
+	KindAbsent  Kind = "absent" // Created by the command; removed on restore
+)
+
+// Entry records one path as it was before the command ran
+type Entry struct {
+	Path   string      `json:"path"` // Relative to the workspace root, or absolute outside it
+	Kind   Kind        `json:"kind"`
+	Mode   fs.FileMode `json:"mode,omitempty"`
+	Target string      `json:"target,omitempty"` // Symlink target
+	Blob   string      `json:"blob,omitempty"`   // Copy of a file's content, relative to the snapshot
+}
+
+// Journal describes a snapshot: the command it was taken before and the
+// paths it recorded. Directories are recorded with everything in them.
+type Journal struct {
+	ID        string    `json:"id"`
+	Command   string    `json:"command"`
+	CreatedAt time.Time `json:"created_at"`
+	Entries   []Entry   `json:"entries"`
+}
+
+// Files returns the number of files and symlinks the snapshot recorded
+func (j *Journal) Files() int {
+	n := 0
+	for _, e := range j.Entries {
+		if e.Kind == KindFile || e.Kind == KindSymlink {
+			n++
+		}
+	}
+	return n
+}
+
+// Store holds the snapshots of a workspace
+type Store struct {
+	Root string // Workspace root
+}
+
+// New returns the snapshot store of the workspace at root
+func New(root string) *Store {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	return &Store{Root: root}
+}
+
+// Dir returns the directory holding the snapshots
+func (s *Store) Dir() string {
+	return filepath.Join(s.Root, StoreDir)
+}
+
+// Take records paths, and everything under those that are directories,
+// before a command changes them. Paths that don't exist are recorded as
+// absent so that restoring removes what the command created. Older
+// snapshots beyond Keep are pruned.
+func (s *Store) Take(command string, paths ...string) (*Journal, error) {
+	id := time.Now().Format(idFormat)
+	dir := filepath.Join(s.Dir(), id)
+	for n := 1; util.FileExists(dir); n++ {
+		id = fmt.Sprintf("%s-%d", time.Now().Format(idFormat), n)
+		dir = filepath.Join(s.Dir(), id)
+	}
+	if err := util.EnsureDir(filepath.Join(dir, filesDir)); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	j := &Journal{ID: id, Command: command, CreatedAt: time.Now()}
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if err := s.record(j, dir, path, seen); err != nil {
+			_ = os.RemoveAll(dir)
+			return nil, err
+		}
+	}
+
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to marshal %s: %w", JournalFile, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, JournalFile), data, 0644); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to write %s: %w", JournalFile, err)
+	}
+
+	return j, s.Prune(Keep)
+}
+
+// record adds path, and a directory's contents, to the journal
+func (s *Store) record(j *Journal, dir, path string, seen map[string]bool) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if seen[path] {
+		return nil
+	}
+
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		seen[path] = true
+		j.Entries = append(j.Entries, Entry{Path: s.rel(path), Kind: KindAbsent})
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return s.recordOne(j, dir, path, info, seen)
+	}
+
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == s.Dir() {
+			return filepath.SkipDir
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return s.recordOne(j, dir, p, info, seen)
+	})
+}
+
+// recordOne adds a single file, symlink or directory to the journal
+func (s *Store) recordOne(j *Journal, dir, path string, info fs.FileInfo, seen map[string]bool) error {
+	if seen[path] {
+		return nil
+	}
+	seen[path] = true
+
+	entry := Entry{Path: s.rel(path), Mode: info.Mode().Perm()}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		entry.Kind, entry.Target, entry.Mode = KindSymlink, target, 0
+	case info.IsDir():
+		entry.Kind = KindDir
+	default:
+		entry.Kind = KindFile
+		entry.Blob = filepath.Join(filesDir, fmt.Sprintf("%d", len(j.Entries)))
+		if err := util.CopyFile(path, filepath.Join(dir, entry.Blob)); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", entry.Path, err)
+		}
+	}
+	j.Entries = append(j.Entries, entry)
+	return nil
+}
+
+// rel returns path relative to the workspace root when it's inside it
+func (s *Store) rel(path string) string {
+	if rel, err := filepath.Rel(s.Root, path); err == nil && !isOutside(rel) {
+		return rel
+	}
+	return path
+}
+
+// abs returns the absolute path of an entry
+func (s *Store) abs(e Entry) string {
+	if filepath.IsAbs(e.Path) {
+		return e.Path
+	}
+	return filepath.Join(s.Root, e.Path)
+}
+
+// List returns the snapshots, newest first. Snapshots without a readable
+// journal are skipped.
+func (s *Store) List() ([]*Journal, error) {
+	entries, err := os.ReadDir(s.Dir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var journals []*Journal
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.Dir(), entry.Name(), JournalFile))
+		if err != nil {
+			continue
+		}
+		var j Journal
+		if err := json.Unmarshal(data, &j); err != nil {
+			continue
+		}
+		j.ID = entry.Name()
+		journals = append(journals, &j)
+	}
+
+	sort.SliceStable(journals, func(a, b int) bool {
+		if !journals[a].CreatedAt.Equal(journals[b].CreatedAt) {
+			return journals[a].CreatedAt.After(journals[b].CreatedAt)
+		}
+		return journals[a].ID > journals[b].ID
+	})
+	return journals, nil
+}
+
+// Undo rolls back the last n snapshotted commands, newest first, and
+// returns their journals
+func (s *Store) Undo(n int) ([]*Journal, error) {
+	journals, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	if n > len(journals) {
+		return nil, fmt.Errorf("only %d snapshot(s) to undo", len(journals))
+	}
+	return s.restoreAll(journals[:n])
+}
+
+// RestoreTo rolls back the command snapshot id was taken before, and every
+// command snapshotted after it
+func (s *Store) RestoreTo(id string) ([]*Journal, error) {
+	journals, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	for i, j := range journals {
+		if j.ID == id {
+			return s.restoreAll(journals[:i+1])
+		}
+	}
+	return nil, fmt.Errorf("snapshot '%s' not found", id)
+}
+
+// restoreAll restores snapshots in order, deleting each once restored
+func (s *Store) restoreAll(journals []*Journal) ([]*Journal, error) {
+	for i, j := range journals {
+		if err := s.Restore(j); err != nil {
+			return journals[:i], fmt.Errorf("failed to restore snapshot %s (%s): %w", j.ID, j.Command, err)
+		}
+		if err := os.RemoveAll(filepath.Join(s.Dir(), j.ID)); err != nil {
+			return journals[:i+1], err
+		}
+	}
+	return journals, nil
+}
+
+// Restore puts every path a snapshot recorded back the way it was.
+// Directories get back exactly the content they had, and paths that were
+// absent are removed. The store itself is never touched.
+func (s *Store) Restore(j *Journal) error {
+	dir := filepath.Join(s.Dir(), j.ID)
+
+	recorded := make(map[string]bool)
+	for _, e := range j.Entries {
+		recorded[s.abs(e)] = true
+	}
+
+	for _, e := range j.Entries {
+		path := s.abs(e)
+		info, statErr := os.Lstat(path)
+
+		switch e.Kind {
+		case KindAbsent:
+			if err := s.RemoveAll(path); err != nil {
+				return err
+			}
+
+		case KindDir:
+			if statErr == nil && !info.IsDir() {
+				if err := os.Remove(path); err != nil {
+					return err
+				}
+			}
+			if err := os.MkdirAll(path, e.Mode); err != nil {
+				return err
+			}
+			// Drop what the command added to the directory
+			children, err := os.ReadDir(path)
+			if err != nil {
+				return err
+			}
+			for _, child := range children {
+				if p := filepath.Join(path, child.Name()); !recorded[p] {
+					if err := s.RemoveAll(p); err != nil {
+						return err
+					}
+				}
+			}
+
+		case KindSymlink:
+			if statErr == nil {
+				if err := s.RemoveAll(path); err != nil {
+					return err
+				}
+			}
+			if err := util.EnsureDir(filepath.Dir(path)); err != nil {
+				return err
+			}
+			if err := os.Symlink(e.Target, path); err != nil {
+				return err
+			}
+
+		case KindFile:
+			if statErr == nil && (info.IsDir() || info.Mode()&os.ModeSymlink != 0) {
+				if err := s.RemoveAll(path); err != nil {
+					return err
+				}
+			}
+			content, err := os.ReadFile(filepath.Join(dir, e.Blob))
+			if err != nil {
+				return fmt.Errorf("snapshot copy of %s is missing: %w", e.Path, err)
+			}
+			if err := util.EnsureDir(filepath.Dir(path)); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, content, e.Mode); err != nil {
+				return err
+			}
+			if err := os.Chmod(path, e.Mode); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Prune deletes all but the newest keep snapshots
+func (s *Store) Prune(keep int) error {
+	journals, err := s.List()
+	if err != nil {
+		return err
+	}
+	for i := keep; i < len(journals); i++ {
+		if err := os.RemoveAll(filepath.Join(s.Dir(), journals[i].ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveAll removes path like os.RemoveAll, except that the store is kept
+// when it lies inside path
+func (s *Store) RemoveAll(path string) error {
+	rel, err := filepath.Rel(path, s.Dir())
+	if err != nil || isOutside(rel) {
+		return os.RemoveAll(path)
+	}
+	if rel == "." {
+		return nil
+	}
+	if info, err := os.Lstat(path); err != nil || !info.IsDir() {
+		return os.RemoveAll(path)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := s.RemoveAll(filepath.Join(path, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isOutside reports whether a relative path leaves its base directory
+func isOutside(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
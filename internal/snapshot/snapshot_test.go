@@ -0,0 +1,193 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestTakeAndUndo(t *testing.T) {
+	root := t.TempDir()
+	claude := filepath.Join(root, ".claude")
+	writeFile(t, filepath.Join(claude, "agents", "a.md"), "agent a")
+	writeFile(t, filepath.Join(claude, "agents", "b.md"), "agent b")
+	config := filepath.Join(root, "workflow.yaml")
+	writeFile(t, config, "name: shop")
+	created := filepath.Join(root, "new.txt")
+
+	store := New(root)
+	j, err := store.Take("upgrade", claude, config, created)
+	if err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+	if j.Files() != 3 {
+		t.Errorf("Expected 3 files recorded, got %d", j.Files())
+	}
+
+	// The command edits, deletes and creates files
+	writeFile(t, filepath.Join(claude, "agents", "a.md"), "edited")
+	_ = os.Remove(filepath.Join(claude, "agents", "b.md"))
+	writeFile(t, filepath.Join(claude, "agents", "c.md"), "agent c")
+	writeFile(t, config, "name: changed")
+	writeFile(t, created, "created")
+
+	undone, err := store.Undo(1)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if len(undone) != 1 || undone[0].Command != "upgrade" {
+		t.Errorf("Expected the upgrade to be undone, got %v", undone)
+	}
+
+	if got := readFile(t, filepath.Join(claude, "agents", "a.md")); got != "agent a" {
+		t.Errorf("a.md = %q, want restored content", got)
+	}
+	if got := readFile(t, filepath.Join(claude, "agents", "b.md")); got != "agent b" {
+		t.Errorf("b.md = %q, want restored content", got)
+	}
+	if _, err := os.Stat(filepath.Join(claude, "agents", "c.md")); !os.IsNotExist(err) {
+		t.Error("Expected c.md, added by the command, to be removed")
+	}
+	if got := readFile(t, config); got != "name: shop" {
+		t.Errorf("workflow.yaml = %q, want restored content", got)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Error("Expected new.txt, created by the command, to be removed")
+	}
+
+	// Restored snapshots are used up
+	if journals, _ := store.List(); len(journals) != 0 {
+		t.Errorf("Expected no snapshots left, got %d", len(journals))
+	}
+	if _, err := store.Undo(1); err == nil {
+		t.Error("Expected an error undoing with no snapshots")
+	}
+}
+
+func TestRestoreTo(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "workflow.yaml")
+	writeFile(t, file, "v1")
+
+	store := New(root)
+	first, err := store.Take("first", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, file, "v2")
+	if _, err := store.Take("second", file); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, file, "v3")
+
+	journals, _ := store.List()
+	if len(journals) != 2 || journals[0].Command != "second" {
+		t.Fatalf("Expected snapshots newest first, got %v", journals)
+	}
+
+	restored, err := store.RestoreTo(first.ID)
+	if err != nil {
+		t.Fatalf("RestoreTo failed: %v", err)
+	}
+	if len(restored) != 2 {
+		t.Errorf("Expected both commands rolled back, got %d", len(restored))
+	}
+	if got := readFile(t, file); got != "v1" {
+		t.Errorf("Expected v1 after rolling back to the first snapshot, got %q", got)
+	}
+}
+
+func TestRestore_DirectoryReplacedBySymlink(t *testing.T) {
+	root := t.TempDir()
+	claude := filepath.Join(root, ".claude")
+	hub := filepath.Join(root, "workflow-hub")
+	writeFile(t, filepath.Join(claude, "settings.json"), "{}")
+
+	store := New(root)
+	if _, err := store.Take("expand", claude, hub); err != nil {
+		t.Fatal(err)
+	}
+
+	// Expand moves .claude into the hub and links it back
+	if err := os.MkdirAll(hub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(claude, filepath.Join(hub, ".claude")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("workflow-hub", ".claude"), claude); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Undo(1); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if info, err := os.Lstat(claude); err != nil || !info.IsDir() {
+		t.Fatalf("Expected .claude to be a directory again, got %v, %v", info, err)
+	}
+	if got := readFile(t, filepath.Join(claude, "settings.json")); got != "{}" {
+		t.Errorf("settings.json = %q", got)
+	}
+	if _, err := os.Stat(hub); !os.IsNotExist(err) {
+		t.Error("Expected the hub, created by the command, to be removed")
+	}
+}
+
+func TestRemoveAll_KeepsStore(t *testing.T) {
+	root := t.TempDir()
+	ccflow := filepath.Join(root, ".ccflow")
+	writeFile(t, filepath.Join(ccflow, "workflow.yaml"), "name: shop")
+
+	store := New(root)
+	if _, err := store.Take("remove", ccflow); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RemoveAll(ccflow); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(ccflow, "workflow.yaml")); !os.IsNotExist(err) {
+		t.Error("Expected workflow.yaml to be removed")
+	}
+
+	if _, err := store.Undo(1); err != nil {
+		t.Fatalf("Expected the snapshot to survive the removal: %v", err)
+	}
+	if got := readFile(t, filepath.Join(ccflow, "workflow.yaml")); got != "name: shop" {
+		t.Errorf("workflow.yaml = %q", got)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	root := t.TempDir()
+	store := New(root)
+	for i := 0; i < 3; i++ {
+		if _, err := store.Take("cmd", filepath.Join(root, "x")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Prune(2); err != nil {
+		t.Fatal(err)
+	}
+	if journals, _ := store.List(); len(journals) != 2 {
+		t.Errorf("Expected 2 snapshots after pruning, got %d", len(journals))
+	}
+}
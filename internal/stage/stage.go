@@ -0,0 +1,253 @@
+// Package stage lets a command prepare its changes to a workspace in a
+// scratch copy and then apply them all at once, or not at all.
+package stage
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Wameedh/ccflow/internal/snapshot"
+	"github.com/Wameedh/ccflow/internal/util"
+)
+
+// DirPrefix starts the name of every staging directory, which lives in the
+// workspace's .ccflow directory so that it is on the same filesystem
+const DirPrefix = "staging-"
+
+// Stage is a scratch copy of the parts of a workspace a command changes.
+// The command seeds it with what it reads, writes its results under Dir as
+// if Dir were the workspace root, and then commits.
+type Stage struct {
+	Root string // Workspace root
+	Dir  string // Mirror of Root the command writes to
+
+	base     string   // Holds Dir and, while committing, the replaced files
+	removals []string // Paths to remove on commit, relative to Root
+}
+
+// New creates an empty stage for the workspace at root
+func New(root string) (*Stage, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	parent := filepath.Join(root, ".ccflow")
+	if err := util.EnsureDir(parent); err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	base, err := os.MkdirTemp(parent, DirPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	s := &Stage{Root: root, Dir: filepath.Join(base, "root"), base: base}
+	if err := util.EnsureDir(s.Dir); err != nil {
+		s.Abort()
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	return s, nil
+}
+
+// Path returns where a path in the workspace is staged
+func (s *Stage) Path(target string) string {
+	rel, err := filepath.Rel(s.Root, target)
+	if err != nil || isOutside(rel) {
+		// Not in the workspace; callers only stage paths inside it
+		return target
+	}
+	return filepath.Join(s.Dir, rel)
+}
+
+// Seed copies existing paths from the workspace into the stage, so that the
+// command reads and updates them there. Directories are copied whole,
+// except for snapshots and other stages. Missing paths are skipped.
+func (s *Stage) Seed(paths ...string) error {
+	for _, path := range paths {
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			continue
+		}
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && s.skip(p) {
+				return filepath.SkipDir
+			}
+			return copyEntry(p, s.Path(p), d)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to stage %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// skip reports whether a directory is left out of seeding: the snapshot
+// store and staging directories
+func (s *Stage) skip(dir string) bool {
+	if dir == filepath.Join(s.Root, snapshot.StoreDir) {
+		return true
+	}
+	return filepath.Dir(dir) == filepath.Join(s.Root, ".ccflow") && strings.HasPrefix(filepath.Base(dir), DirPrefix)
+}
+
+// Remove removes a path from the stage and removes it from the workspace
+// on commit. Deleting staged files by other means leaves the workspace's
+// copies alone.
+func (s *Stage) Remove(target string) error {
+	if err := os.RemoveAll(s.Path(target)); err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(s.Root, target)
+	if err != nil || isOutside(rel) {
+		return fmt.Errorf("%s is outside the workspace", target)
+	}
+	s.removals = append(s.removals, rel)
+	return nil
+}
+
+// Commit moves everything staged into the workspace and carries out the
+// removals. Files identical to the workspace's are left alone. If any step
+// fails, the steps already taken are reverted, so the workspace is either
+// fully updated or untouched. The stage is deleted either way.
+func (s *Stage) Commit() error {
+	defer s.Abort()
+
+	backup := filepath.Join(s.base, "backup")
+	var undo []func() error
+	rollback := func(err error) error {
+		for i := len(undo) - 1; i >= 0; i-- {
+			_ = undo[i]() // Best effort; keep restoring the rest
+		}
+		return err
+	}
+
+	// moveAside moves what is at target into the backup, to be restored on
+	// rollback
+	moveAside := func(target, rel string) error {
+		saved := filepath.Join(backup, rel)
+		if err := util.EnsureDir(filepath.Dir(saved)); err != nil {
+			return err
+		}
+		if err := os.Rename(target, saved); err != nil {
+			return err
+		}
+		undo = append(undo, func() error { return os.Rename(saved, target) })
+		return nil
+	}
+
+	err := filepath.WalkDir(s.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(s.Dir, path)
+		if rel == "." {
+			return nil
+		}
+		target := filepath.Join(s.Root, rel)
+
+		if d.IsDir() {
+			if info, err := os.Stat(target); err == nil && info.IsDir() {
+				return nil
+			}
+			if _, err := os.Lstat(target); err == nil {
+				if err := moveAside(target, rel); err != nil {
+					return err
+				}
+			}
+			if err := os.Mkdir(target, 0755); err != nil {
+				return err
+			}
+			undo = append(undo, func() error { return os.Remove(target) })
+			return nil
+		}
+
+		if same(path, target) {
+			return nil
+		}
+		if _, err := os.Lstat(target); err == nil {
+			if err := moveAside(target, rel); err != nil {
+				return err
+			}
+		}
+		if err := os.Rename(path, target); err != nil {
+			return err
+		}
+		undo = append(undo, func() error { return os.RemoveAll(target) })
+		return nil
+	})
+	if err != nil {
+		return rollback(fmt.Errorf("failed to apply staged changes: %w", err))
+	}
+
+	for _, rel := range s.removals {
+		target := filepath.Join(s.Root, rel)
+		if _, err := os.Lstat(target); err != nil {
+			continue
+		}
+		if err := moveAside(target, rel); err != nil {
+			return rollback(fmt.Errorf("failed to remove %s: %w", rel, err))
+		}
+	}
+
+	return nil
+}
+
+// Abort deletes the stage without touching the workspace. It is safe to
+// call after Commit.
+func (s *Stage) Abort() {
+	_ = os.RemoveAll(s.base)
+	_ = os.Remove(filepath.Dir(s.base)) // Only if nothing else is in .ccflow
+}
+
+// copyEntry copies a file, symlink or directory from the workspace into the
+// stage
+func copyEntry(src, dst string, d fs.DirEntry) error {
+	switch {
+	case d.Type()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		if err := util.EnsureDir(filepath.Dir(dst)); err != nil {
+			return err
+		}
+		_ = os.Remove(dst) // Seeded before, with an overlapping path
+		return os.Symlink(target, dst)
+	case d.IsDir():
+		return util.EnsureDir(dst)
+	default:
+		return util.CopyFile(src, dst)
+	}
+}
+
+// same reports whether a staged file or symlink matches what is at target
+func same(staged, target string) bool {
+	si, err := os.Lstat(staged)
+	if err != nil {
+		return false
+	}
+	ti, err := os.Lstat(target)
+	if err != nil || si.Mode() != ti.Mode() {
+		return false
+	}
+
+	if si.Mode()&os.ModeSymlink != 0 {
+		a, errA := os.Readlink(staged)
+		b, errB := os.Readlink(target)
+		return errA == nil && errB == nil && a == b
+	}
+
+	a, errA := os.ReadFile(staged)
+	b, errB := os.ReadFile(target)
+	return errA == nil && errB == nil && bytes.Equal(a, b)
+}
+
+// isOutside reports whether a relative path leaves its base directory
+func isOutside(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
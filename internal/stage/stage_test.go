@@ -0,0 +1,161 @@
+package stage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Wameedh/ccflow/internal/util"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestCommit(t *testing.T) {
+	root := t.TempDir()
+	claude := filepath.Join(root, ".claude")
+	writeFile(t, filepath.Join(claude, "agents", "a.md"), "agent a")
+	writeFile(t, filepath.Join(claude, "agents", "b.md"), "agent b")
+	writeFile(t, filepath.Join(root, "old.txt"), "old")
+
+	st, err := New(root)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := st.Seed(claude, filepath.Join(root, "missing")); err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+	if readFile(t, st.Path(filepath.Join(claude, "agents", "b.md"))) != "agent b" {
+		t.Error("Expected seeded files in the stage")
+	}
+
+	writeFile(t, st.Path(filepath.Join(claude, "agents", "a.md")), "edited")
+	writeFile(t, st.Path(filepath.Join(root, "hub", "workflow.yaml")), "name: shop")
+	if err := st.Remove(filepath.Join(root, "old.txt")); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+
+	// Nothing changes until the commit
+	if readFile(t, filepath.Join(claude, "agents", "a.md")) != "agent a" {
+		t.Error("Workspace changed before commit")
+	}
+
+	if err := st.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	if got := readFile(t, filepath.Join(claude, "agents", "a.md")); got != "edited" {
+		t.Errorf("Expected a.md updated, got %q", got)
+	}
+	if got := readFile(t, filepath.Join(claude, "agents", "b.md")); got != "agent b" {
+		t.Errorf("Expected b.md kept, got %q", got)
+	}
+	if got := readFile(t, filepath.Join(root, "hub", "workflow.yaml")); got != "name: shop" {
+		t.Errorf("Expected workflow.yaml created, got %q", got)
+	}
+	if util.FileExists(filepath.Join(root, "old.txt")) {
+		t.Error("Expected old.txt removed")
+	}
+	if util.DirExists(filepath.Join(root, ".ccflow")) {
+		t.Error("Expected the stage to be cleaned up")
+	}
+}
+
+func TestCommit_ReplacesDirWithSymlink(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".claude", "settings.json"), "{}")
+
+	st, err := New(root)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	writeFile(t, st.Path(filepath.Join(root, "hub", ".claude", "settings.json")), "{}")
+	if err := os.Symlink(filepath.Join("hub", ".claude"), st.Path(filepath.Join(root, ".claude"))); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := st.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	target, err := os.Readlink(filepath.Join(root, ".claude"))
+	if err != nil || target != filepath.Join("hub", ".claude") {
+		t.Errorf("Expected .claude to link to hub/.claude, got %q (%v)", target, err)
+	}
+	if readFile(t, filepath.Join(root, ".claude", "settings.json")) != "{}" {
+		t.Error("Expected settings.json through the link")
+	}
+}
+
+func TestCommit_RollsBackOnFailure(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "b.txt"), "original b")
+
+	st, err := New(root)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	writeFile(t, st.Path(filepath.Join(root, "a.txt")), "new a")
+	writeFile(t, st.Path(filepath.Join(root, "b.txt")), "new b")
+
+	// Replacing b.txt needs the backup directory, which can't be created
+	writeFile(t, filepath.Join(st.base, "backup"), "in the way")
+
+	if err := st.Commit(); err == nil {
+		t.Fatal("Expected Commit to fail")
+	}
+
+	if util.FileExists(filepath.Join(root, "a.txt")) {
+		t.Error("Expected a.txt, written before the failure, to be rolled back")
+	}
+	if got := readFile(t, filepath.Join(root, "b.txt")); got != "original b" {
+		t.Errorf("Expected b.txt untouched, got %q", got)
+	}
+	if util.DirExists(filepath.Join(root, ".ccflow")) {
+		t.Error("Expected the stage to be cleaned up")
+	}
+}
+
+func TestAbort(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".ccflow", "snapshots", "1", "journal.json"), "{}")
+	writeFile(t, filepath.Join(root, ".ccflow", "workflow.yaml"), "name: shop")
+
+	st, err := New(root)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := st.Seed(filepath.Join(root, ".ccflow")); err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+	if util.DirExists(st.Path(filepath.Join(root, ".ccflow", "snapshots"))) {
+		t.Error("Expected snapshots to be left out of the stage")
+	}
+	writeFile(t, st.Path(filepath.Join(root, ".ccflow", "workflow.yaml")), "name: changed")
+
+	st.Abort()
+
+	if got := readFile(t, filepath.Join(root, ".ccflow", "workflow.yaml")); got != "name: shop" {
+		t.Errorf("Expected workflow.yaml untouched, got %q", got)
+	}
+	entries, _ := os.ReadDir(filepath.Join(root, ".ccflow"))
+	if len(entries) != 2 {
+		t.Errorf("Expected only the original .ccflow entries left, got %d", len(entries))
+	}
+}
@@ -0,0 +1,263 @@
+package transition
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/feature"
+)
+
+// Phase is a stage of the workflow, entered with the slash command of the same name
+type Phase string
+
+const (
+	PhaseIdea      Phase = "idea"
+	PhaseDesign    Phase = "design"
+	PhaseImplement Phase = "implement"
+	PhaseReview    Phase = "review"
+	PhaseRelease   Phase = "release"
+)
+
+// Phases lists the workflow phases in order
+var Phases = []Phase{PhaseIdea, PhaseDesign, PhaseImplement, PhaseReview, PhaseRelease}
+
+// entryStatuses maps each phase to the feature status that starts it. A
+// feature enters release once approved; /release itself marks it released.
+var entryStatuses = map[Phase]string{
+	PhaseIdea:      "ideation",
+	PhaseDesign:    "design",
+	PhaseImplement: "implementation",
+	PhaseReview:    "review",
+	PhaseRelease:   "approved",
+}
+
+// EntryStatus returns the feature status a phase starts in
+func (p Phase) EntryStatus() string {
+	return entryStatuses[p]
+}
+
+// Command returns the slash command that runs a phase
+func (p Phase) Command() string {
+	return "/" + string(p)
+}
+
+// Transition is a move from one phase to the next
+type Transition struct {
+	From Phase
+	To   Phase
+	Key  string // workflow.yaml key under transitions, e.g. "idea_to_design"
+	Mode config.TransitionMode
+}
+
+// PhaseOf returns the phase a status belongs to. Statuses between two phase
+// entry statuses in the schema's enum (e.g. design_approved, plan_approved)
+// belong to the earlier phase.
+func PhaseOf(statuses []string, status string) (Phase, error) {
+	i := slices.Index(statuses, status)
+	if i < 0 {
+		return "", fmt.Errorf("unknown status: %s", status)
+	}
+
+	for j := i; j >= 0; j-- {
+		for _, phase := range Phases {
+			if statuses[j] == phase.EntryStatus() {
+				return phase, nil
+			}
+		}
+		if statuses[j] == feature.StatusRollback {
+			break
+		}
+	}
+	return "", fmt.Errorf("status %s is not part of a workflow phase", status)
+}
+
+// For returns the transition out of a phase with its configured mode
+func For(cfg config.TransitionsConfig, from Phase) (Transition, error) {
+	i := slices.Index(Phases, from)
+	if i < 0 {
+		return Transition{}, fmt.Errorf("unknown phase: %s", from)
+	}
+	if i == len(Phases)-1 {
+		return Transition{}, fmt.Errorf("%s is the last phase", from)
+	}
+
+	t := Transition{From: from, To: Phases[i+1]}
+	switch from {
+	case PhaseIdea:
+		t.Key, t.Mode = "idea_to_design", cfg.IdeaToDesign.Mode
+	case PhaseDesign:
+		t.Key, t.Mode = "design_to_implement", cfg.DesignToImplement.Mode
+	case PhaseImplement:
+		t.Key, t.Mode = "implement_to_review", cfg.ImplementToReview.Mode
+	case PhaseReview:
+		t.Key, t.Mode = "review_to_release", cfg.ReviewToRelease.Mode
+	}
+	if t.Mode == "" {
+		t.Mode = config.TransitionPrompt
+	}
+	return t, nil
+}
+
+// ConfirmFunc asks whether a prompt-mode transition should proceed
+type ConfirmFunc func(t Transition, f *feature.Feature) (bool, error)
+
+// GateFunc returns an error when a feature may not make a transition
+type GateFunc func(t Transition, f *feature.Feature) error
+
+// Engine moves features between phases according to the configured modes
+type Engine struct {
+	store   *feature.Store
+	cfg     config.TransitionsConfig
+	confirm ConfirmFunc
+	gate    GateFunc
+}
+
+// NewEngine creates an engine. confirm is called for prompt-mode transitions;
+// when nil, prompt-mode transitions are only suggested, as in manual mode.
+// gate, when set, runs before a feature is advanced and blocks it on error.
+func NewEngine(store *feature.Store, cfg config.TransitionsConfig, confirm ConfirmFunc, gate GateFunc) *Engine {
+	return &Engine{store: store, cfg: cfg, confirm: confirm, gate: gate}
+}
+
+// Result describes what Next did
+type Result struct {
+	Feature    *feature.Feature
+	Transition Transition
+	FromStatus string
+	Advanced   bool // feature status was moved to the next phase
+	Declined   bool // prompt-mode transition was not confirmed
+	Command    string
+}
+
+// Next looks up the transition out of a feature's current phase and, depending
+// on its mode, advances the feature (auto), asks first (prompt) or only
+// suggests the next slash command (manual). The gate runs before asking or
+// advancing, and its error is returned unchanged.
+func (e *Engine) Next(id string) (*Result, error) {
+	f, err := e.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := e.store.Statuses()
+	phase, err := PhaseOf(statuses, f.Status())
+	if err != nil {
+		return nil, fmt.Errorf("feature %s: %w", id, err)
+	}
+	t, err := For(e.cfg, phase)
+	if err != nil {
+		return nil, fmt.Errorf("feature %s: %w", id, err)
+	}
+	if !slices.Contains(statuses, t.To.EntryStatus()) {
+		return nil, fmt.Errorf("feature %s: status %s is not in the blueprint's state schema", id, t.To.EntryStatus())
+	}
+
+	result := &Result{
+		Feature:    f,
+		Transition: t,
+		FromStatus: f.Status(),
+		Command:    fmt.Sprintf("%s %s", t.To.Command(), id),
+	}
+
+	switch t.Mode {
+	case config.TransitionManual:
+		return result, nil
+	case config.TransitionPrompt, config.TransitionAuto:
+	default:
+		return nil, fmt.Errorf("invalid transition mode for %s: %s", t.Key, t.Mode)
+	}
+
+	if t.Mode == config.TransitionPrompt && e.confirm == nil {
+		return result, nil
+	}
+	if e.gate != nil {
+		if err := e.gate(t, f); err != nil {
+			return nil, err
+		}
+	}
+
+	if t.Mode == config.TransitionPrompt {
+		ok, err := e.confirm(t, f)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			result.Declined = true
+			return result, nil
+		}
+	}
+
+	advanced, err := e.store.Advance(id, t.To.EntryStatus())
+	if err != nil {
+		return nil, err
+	}
+	result.Feature = advanced
+	result.Advanced = true
+	return result, nil
+}
+
+// Advance moves a feature to a status, or to its next status when to is
+// empty. Unlike Next it ignores the transition modes, but it runs the gate
+// for every phase the feature leaves on the way. Moves back to an earlier
+// phase, within a phase, or to or from a status outside the phases (e.g.
+// rollback) are not gated.
+func (e *Engine) Advance(id, to string) (*Result, error) {
+	f, err := e.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := e.store.Statuses()
+	if to == "" {
+		if to, err = feature.NextStatus(statuses, f.Status()); err != nil {
+			return nil, fmt.Errorf("feature %s: %w", id, err)
+		}
+	}
+
+	result := &Result{Feature: f, FromStatus: f.Status()}
+	if e.gate != nil {
+		for _, t := range e.crossed(statuses, f.Status(), to) {
+			if err := e.gate(t, f); err != nil {
+				return nil, err
+			}
+			result.Transition = t
+		}
+	}
+
+	advanced, err := e.store.Advance(id, to)
+	if err != nil {
+		return nil, err
+	}
+	result.Feature = advanced
+	result.Advanced = true
+	return result, nil
+}
+
+// crossed returns the transitions out of every phase a move from one status
+// to another leaves, in order
+func (e *Engine) crossed(statuses []string, from, to string) []Transition {
+	fromPhase, err := PhaseOf(statuses, from)
+	if err != nil {
+		return nil
+	}
+	toPhase, err := PhaseOf(statuses, to)
+	if err != nil {
+		return nil
+	}
+
+	start, end := slices.Index(Phases, fromPhase), slices.Index(Phases, toPhase)
+	if end <= start {
+		return nil
+	}
+
+	var transitions []Transition
+	for _, phase := range Phases[start:end] {
+		t, err := For(e.cfg, phase)
+		if err != nil {
+			break
+		}
+		transitions = append(transitions, t)
+	}
+	return transitions
+}
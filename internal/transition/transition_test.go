@@ -0,0 +1,304 @@
+package transition
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/feature"
+)
+
+const testSchema = `{
+  "type": "object",
+  "required": ["id", "title", "status", "created_at"],
+  "properties": {
+    "id": {"type": "string"},
+    "title": {"type": "string"},
+    "status": {"type": "string", "enum": ["ideation", "design", "design_approved", "implementation", "review", "plan_approved", "approved", "released", "rollback"]},
+    "created_at": {"type": "string", "format": "date-time"}
+  }
+}`
+
+var testStatuses = []string{"ideation", "design", "design_approved", "implementation", "review", "plan_approved", "approved", "released", "rollback"}
+
+// newTestStore creates a store with one feature in the given status
+func newTestStore(t *testing.T, status string) *feature.Store {
+	t.Helper()
+	store, err := feature.NewStore(t.TempDir(), []byte(testSchema))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	if _, err := store.New("login", "Login", ""); err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if status != "ideation" {
+		if _, err := store.Advance("login", status); err != nil {
+			t.Fatalf("Advance failed: %v", err)
+		}
+	}
+	return store
+}
+
+// allModes returns a TransitionsConfig with every transition in mode
+func allModes(mode config.TransitionMode) config.TransitionsConfig {
+	tc := config.TransitionConfig{Mode: mode}
+	return config.TransitionsConfig{IdeaToDesign: tc, DesignToImplement: tc, ImplementToReview: tc, ReviewToRelease: tc}
+}
+
+func TestPhaseOf(t *testing.T) {
+	tests := map[string]Phase{
+		"ideation":        PhaseIdea,
+		"design":          PhaseDesign,
+		"design_approved": PhaseDesign,
+		"implementation":  PhaseImplement,
+		"plan_approved":   PhaseReview,
+		"approved":        PhaseRelease,
+		"released":        PhaseRelease,
+	}
+	for status, want := range tests {
+		got, err := PhaseOf(testStatuses, status)
+		if err != nil || got != want {
+			t.Errorf("PhaseOf(%s) = %s, %v; want %s", status, got, err, want)
+		}
+	}
+
+	for _, status := range []string{"rollback", "unknown"} {
+		if _, err := PhaseOf(testStatuses, status); err == nil {
+			t.Errorf("Expected error for %s", status)
+		}
+	}
+}
+
+func TestFor(t *testing.T) {
+	cfg := config.TransitionsConfig{
+		DesignToImplement: config.TransitionConfig{Mode: config.TransitionAuto},
+	}
+
+	tr, err := For(cfg, PhaseDesign)
+	if err != nil {
+		t.Fatalf("For failed: %v", err)
+	}
+	if tr.To != PhaseImplement || tr.Key != "design_to_implement" || tr.Mode != config.TransitionAuto {
+		t.Errorf("Unexpected transition: %+v", tr)
+	}
+
+	// Unset modes default to prompt
+	if tr, _ := For(cfg, PhaseIdea); tr.Mode != config.TransitionPrompt {
+		t.Errorf("Expected prompt default, got %s", tr.Mode)
+	}
+
+	if _, err := For(cfg, PhaseRelease); err == nil {
+		t.Error("Expected error for transition out of the last phase")
+	}
+}
+
+func TestNext_Auto(t *testing.T) {
+	store := newTestStore(t, "design_approved")
+	engine := NewEngine(store, allModes(config.TransitionAuto), nil, nil)
+
+	result, err := engine.Next("login")
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	if !result.Advanced || result.Feature.Status() != "implementation" {
+		t.Errorf("Expected advance to implementation, got %+v", result)
+	}
+	if result.FromStatus != "design_approved" || result.Command != "/implement login" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestNext_ReviewToRelease(t *testing.T) {
+	store := newTestStore(t, "plan_approved")
+	engine := NewEngine(store, allModes(config.TransitionAuto), nil, nil)
+
+	// Release starts at approved; /release is what marks a feature released
+	result, err := engine.Next("login")
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	if !result.Advanced || result.Feature.Status() != "approved" || result.Command != "/release login" {
+		t.Errorf("Expected advance to approved with /release suggested, got %+v", result)
+	}
+
+	if _, err := engine.Next("login"); err == nil {
+		t.Error("Expected an error moving past the last phase")
+	}
+}
+
+func TestNext_Manual(t *testing.T) {
+	store := newTestStore(t, "ideation")
+	engine := NewEngine(store, allModes(config.TransitionManual), func(Transition, *feature.Feature) (bool, error) {
+		t.Fatal("confirm should not be called in manual mode")
+		return false, nil
+	}, nil)
+
+	result, err := engine.Next("login")
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	if result.Advanced || result.Command != "/design login" {
+		t.Errorf("Expected suggestion only, got %+v", result)
+	}
+	if f, _ := store.Get("login"); f.Status() != "ideation" {
+		t.Errorf("Manual mode should not change status, got %s", f.Status())
+	}
+}
+
+func TestNext_Prompt(t *testing.T) {
+	store := newTestStore(t, "review")
+
+	var asked Transition
+	answer := false
+	engine := NewEngine(store, allModes(config.TransitionPrompt), func(tr Transition, f *feature.Feature) (bool, error) {
+		asked = tr
+		return answer, nil
+	}, nil)
+
+	result, err := engine.Next("login")
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	if asked.Key != "review_to_release" {
+		t.Errorf("Expected to be asked about review_to_release, got %+v", asked)
+	}
+	if result.Advanced || !result.Declined {
+		t.Errorf("Expected declined transition, got %+v", result)
+	}
+
+	answer = true
+	result, err = engine.Next("login")
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	if !result.Advanced || result.Feature.Status() != "approved" {
+		t.Errorf("Expected advance to approved, got %+v", result)
+	}
+
+	// Without a confirm function, prompt mode only suggests
+	store = newTestStore(t, "review")
+	result, err = NewEngine(store, allModes(config.TransitionPrompt), nil, nil).Next("login")
+	if err != nil || result.Advanced {
+		t.Errorf("Expected suggestion only, got %+v %v", result, err)
+	}
+
+	// Confirm errors are returned
+	wantErr := errors.New("no terminal")
+	_, err = NewEngine(store, allModes(config.TransitionPrompt), func(Transition, *feature.Feature) (bool, error) {
+		return false, wantErr
+	}, nil).Next("login")
+	if !errors.Is(err, wantErr) {
+		t.Errorf("Expected confirm error, got %v", err)
+	}
+}
+
+func TestNext_GateBlocks(t *testing.T) {
+	store := newTestStore(t, "design")
+
+	blocked := errors.New("design doc missing")
+	var gated Transition
+	engine := NewEngine(store, allModes(config.TransitionAuto), nil, func(tr Transition, f *feature.Feature) error {
+		gated = tr
+		return blocked
+	})
+
+	if _, err := engine.Next("login"); !errors.Is(err, blocked) {
+		t.Fatalf("Expected gate error, got %v", err)
+	}
+	if gated.Key != "design_to_implement" {
+		t.Errorf("Expected gate for design_to_implement, got %+v", gated)
+	}
+	if f, _ := store.Get("login"); f.Status() != "design" {
+		t.Errorf("Blocked feature should not move, got %s", f.Status())
+	}
+
+	// Manual mode never runs the gate
+	engine = NewEngine(store, allModes(config.TransitionManual), nil, func(Transition, *feature.Feature) error {
+		t.Fatal("gate should not run in manual mode")
+		return nil
+	})
+	if _, err := engine.Next("login"); err != nil {
+		t.Errorf("Next failed: %v", err)
+	}
+}
+
+func TestNext_Errors(t *testing.T) {
+	for _, status := range []string{"released", "rollback"} {
+		store := newTestStore(t, status)
+		if _, err := NewEngine(store, allModes(config.TransitionAuto), nil, nil).Next("login"); err == nil {
+			t.Errorf("Expected error for feature in %s", status)
+		}
+	}
+
+	store := newTestStore(t, "ideation")
+	if _, err := NewEngine(store, allModes("sometimes"), nil, nil).Next("login"); err == nil {
+		t.Error("Expected error for invalid mode")
+	}
+	if _, err := NewEngine(store, allModes(config.TransitionAuto), nil, nil).Next("missing"); err == nil {
+		t.Error("Expected error for missing feature")
+	}
+}
+
+func TestAdvance_Gates(t *testing.T) {
+	tests := []struct {
+		name  string
+		from  string
+		to    string
+		gated []string
+	}{
+		{"next status within a phase", "design", "", nil},
+		{"next status into a phase", "design_approved", "", []string{"design_to_implement"}},
+		{"explicit status across phases", "design", "review", []string{"design_to_implement", "implement_to_review"}},
+		{"back to an earlier phase", "review", "design", nil},
+		{"to rollback", "approved", "rollback", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore(t, tt.from)
+			var gated []string
+			// Modes don't matter to Advance, even manual ones
+			engine := NewEngine(store, allModes(config.TransitionManual), nil, func(tr Transition, f *feature.Feature) error {
+				gated = append(gated, tr.Key)
+				return nil
+			})
+
+			result, err := engine.Advance("login", tt.to)
+			if err != nil {
+				t.Fatalf("Advance failed: %v", err)
+			}
+			if !result.Advanced || result.FromStatus != tt.from {
+				t.Errorf("Unexpected result: %+v", result)
+			}
+			if !slices.Equal(gated, tt.gated) {
+				t.Errorf("Expected gates %v, got %v", tt.gated, gated)
+			}
+		})
+	}
+}
+
+func TestAdvance_GateBlocks(t *testing.T) {
+	store := newTestStore(t, "ideation")
+
+	blocked := errors.New("design doc missing")
+	engine := NewEngine(store, allModes(config.TransitionAuto), nil, func(tr Transition, f *feature.Feature) error {
+		if tr.Key == "design_to_implement" {
+			return blocked
+		}
+		return nil
+	})
+
+	if _, err := engine.Advance("login", "implementation"); !errors.Is(err, blocked) {
+		t.Fatalf("Expected gate error, got %v", err)
+	}
+	if f, _ := store.Get("login"); f.Status() != "ideation" {
+		t.Errorf("Blocked feature should not move, got %s", f.Status())
+	}
+
+	// Without a gate, as with --force, the feature moves
+	result, err := NewEngine(store, allModes(config.TransitionAuto), nil, nil).Advance("login", "implementation")
+	if err != nil || result.Feature.Status() != "implementation" {
+		t.Errorf("Expected ungated advance to implementation, got %+v, %v", result, err)
+	}
+}
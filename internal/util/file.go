@@ -8,6 +8,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+
+	"golang.org/x/term"
 )
 
 // FileExists checks if a file exists
@@ -107,6 +109,11 @@ func ReadStdin() ([]byte, error) {
 	return content, nil
 }
 
+// IsTerminal reports whether the given file is attached to an interactive terminal
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
 // CreateRelativeSymlink creates a relative symbolic link
 func CreateRelativeSymlink(target, linkPath string) error {
 	// Calculate relative path from link location to target
@@ -0,0 +1,158 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Wameedh/ccflow/internal/blueprint"
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/installer"
+	"github.com/Wameedh/ccflow/internal/settings"
+	"github.com/Wameedh/ccflow/internal/stage"
+	"github.com/Wameedh/ccflow/internal/util"
+	"github.com/Wameedh/ccflow/internal/workspace"
+)
+
+// Fix repairs what a failed or warned check found. Only the description is
+// part of the doctor output schema.
+type Fix struct {
+	Description string       `json:"description" yaml:"description"`
+	Paths       []string     `json:"-" yaml:"-"` // what Apply changes, to snapshot beforehand
+	Apply       func() error `json:"-" yaml:"-"`
+}
+
+// Fixable returns the checks that didn't pass and have a fix, in check order
+func (r *DoctorResult) Fixable() []Check {
+	var fixable []Check
+	for _, check := range r.Checks {
+		if check.Status != "pass" && check.Fix != nil {
+			fixable = append(fixable, check)
+		}
+	}
+	return fixable
+}
+
+// fixExecutable makes a hook script executable
+func fixExecutable(path string) *Fix {
+	return &Fix{
+		Description: fmt.Sprintf("chmod +x %s", path),
+		Paths:       []string{path},
+		Apply: func() error {
+			info, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			return os.Chmod(path, info.Mode()|0111)
+		},
+	}
+}
+
+// fixDirectories creates missing directories
+func fixDirectories(dirs []string) *Fix {
+	return &Fix{
+		Description: "create " + strings.Join(dirs, ", "),
+		Paths:       dirs,
+		Apply: func() error {
+			for _, dir := range dirs {
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// fixInstall re-creates a repo's .claude from the hub in the workflow's
+// install mode. It is only offered when verification failed, so what it
+// replaces is a missing or wrong symlink, or a stray file, never a local or
+// copied .claude directory.
+func (v *Validator) fixInstall(ws *workspace.Workspace, repo config.RepoConfig) *Fix {
+	claudePath := filepath.Join(ws.Root, repo.Path, ".claude")
+	mode, err := installer.ParseInstallMode(ws.Config.Install.Mode)
+	if err != nil {
+		return nil
+	}
+	verb := "re-link"
+	if mode == installer.InstallModeCopy {
+		verb = "re-copy"
+	}
+
+	return &Fix{
+		Description: fmt.Sprintf("%s %s to the hub", verb, claudePath),
+		Paths:       []string{claudePath},
+		Apply: func() error {
+			results := v.installer.Install(installer.InstallOptions{
+				HubPath:       ws.GetHubPath(),
+				Repos:         []config.RepoConfig{repo},
+				WorkspacePath: ws.Root,
+				Mode:          mode,
+				Force:         true,
+			})
+			return results[0].Error
+		},
+	}
+}
+
+// fixSettings regenerates a missing or unparseable settings.json as ccflow
+// would generate it today: the blueprint's settings without the hooks
+// removed with ccflow remove-hook, plus the hooks added with ccflow
+// add-hook. Edits made to an unparseable file are lost, so callers
+// snapshot it first. The file and its recorded base are written through a
+// stage and replaced together.
+func (v *Validator) fixSettings(ws *workspace.Workspace, settingsPath string, exists bool) *Fix {
+	description := fmt.Sprintf("regenerate %s from blueprint %s", settingsPath, ws.Config.Blueprint)
+	if exists {
+		description += ", discarding its local edits"
+	}
+
+	return &Fix{
+		Description: description,
+		Paths:       []string{settingsPath},
+		Apply: func() error {
+			bpManager, err := v.blueprints()
+			if err != nil {
+				return err
+			}
+			bp, err := bpManager.Get(ws.Config.Blueprint)
+			if err != nil {
+				return err
+			}
+			manifest := workspace.LoadManifest(ws.GetHubPath())
+			s, err := bpManager.RenderHubSettings(bp, ws.Config.Hooks.Enabled, manifest)
+			if err != nil {
+				return err
+			}
+			next, err := s.Marshal()
+			if err != nil {
+				return fmt.Errorf("failed to encode settings.json: %w", err)
+			}
+			blueprint.RegisterAddedHooks(bp, s, manifest)
+			content, err := s.Marshal()
+			if err != nil {
+				return fmt.Errorf("failed to encode settings.json: %w", err)
+			}
+
+			st, err := stage.New(ws.Root)
+			if err != nil {
+				return err
+			}
+			defer st.Abort()
+			staged := st.Path(settingsPath)
+			if err := util.SafeWriteFile(staged, content, true); err != nil {
+				return err
+			}
+			if _, err := settings.Load(staged); err != nil {
+				return err
+			}
+			// The base is the blueprint's rendering, which upgrade merges
+			// against; hooks added with add-hook are the user's
+			if err := workspace.SaveBase(st.Path(ws.GetHubPath()), settings.FileName, next); err != nil {
+				return err
+			}
+			return st.Commit()
+		},
+	}
+}
@@ -0,0 +1,42 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/Wameedh/ccflow/internal/parallel"
+	"github.com/Wameedh/ccflow/internal/workspace"
+)
+
+// checkParallel validates parallel groups against the hub's agents. It
+// returns nothing when parallel execution is off and no groups are defined.
+func (v *Validator) checkParallel(ws *workspace.Workspace) []Check {
+	cfg := ws.Config.Parallel
+	issues := parallel.Validate(cfg, parallel.HubAgents(ws.GetHubPath()))
+	if !cfg.Enabled && len(cfg.Groups) == 0 && len(issues) == 0 {
+		return nil
+	}
+
+	check := Check{Name: "Parallel groups", Path: ws.ConfigPath}
+	failed := 0
+	for _, issue := range issues {
+		if issue.Severity == "error" {
+			failed++
+		}
+		check.Details = append(check.Details, issue.String())
+	}
+
+	switch {
+	case failed > 0:
+		check.Status = "fail"
+		check.Message = fmt.Sprintf("%d problem(s) in the parallel section of workflow.yaml", failed)
+		check.Remediation = "Fix parallel.groups in workflow.yaml; run 'ccflow parallel plan' to review the result"
+	case len(issues) > 0:
+		check.Status = "warn"
+		check.Message = fmt.Sprintf("%d warning(s) in the parallel section of workflow.yaml", len(issues))
+	default:
+		check.Status = "pass"
+		check.Message = fmt.Sprintf("%d group(s) valid (sync_gate: %s)", len(cfg.Groups), parallel.SyncGate(cfg))
+	}
+
+	return []Check{check}
+}
@@ -0,0 +1,141 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Wameedh/ccflow/internal/blueprint"
+	"github.com/Wameedh/ccflow/internal/feature"
+	"github.com/Wameedh/ccflow/internal/schema"
+	"github.com/Wameedh/ccflow/internal/workspace"
+)
+
+// schemaTarget ties a blueprint schema template to the JSON files it describes
+type schemaTarget struct {
+	kind     string // used in check names, e.g. "State file"
+	template string // schema under the blueprint's templates/
+	dir      func(ws *workspace.Workspace) string
+}
+
+// schemaTargets lists the documents doctor validates. Feature state files live
+// in the state directory; experiments (python-data-dev) and infrastructure
+// requests (devops-infra) live next to it under the state root.
+var schemaTargets = []schemaTarget{
+	{"State file", feature.SchemaTemplate, func(ws *workspace.Workspace) string {
+		return ws.GetStatePath()
+	}},
+	{"Experiment file", "experiment.schema.json", func(ws *workspace.Workspace) string {
+		return filepath.Join(ws.Root, ws.Config.State.Root, "experiments")
+	}},
+	{"Infra request file", "infra-request.schema.json", func(ws *workspace.Workspace) string {
+		return filepath.Join(ws.Root, ws.Config.State.Root, "requests")
+	}},
+}
+
+// checkSchemaFiles validates workflow documents against the blueprint's
+// schemas, with one failing check per invalid file
+func (v *Validator) checkSchemaFiles(ws *workspace.Workspace) []Check {
+	var checks []Check
+
+	bpManager, err := v.blueprints()
+	if err != nil {
+		return []Check{{
+			Name:    "Schema validation",
+			Status:  "warn",
+			Message: fmt.Sprintf("cannot load blueprints: %v", err),
+		}}
+	}
+
+	for _, target := range schemaTargets {
+		files, _ := filepath.Glob(filepath.Join(target.dir(ws), "*.json"))
+		if len(files) == 0 {
+			continue
+		}
+
+		schemaData, err := bpManager.GetTemplate(ws.Config.Blueprint, target.template)
+		if err != nil {
+			checks = append(checks, Check{
+				Name:    target.kind + "s",
+				Status:  "warn",
+				Message: fmt.Sprintf("blueprint %s has no %s; %d file(s) not validated", ws.Config.Blueprint, target.template, len(files)),
+			})
+			continue
+		}
+		s, err := schema.Parse(schemaData)
+		if err != nil {
+			checks = append(checks, Check{
+				Name:        target.kind + "s",
+				Status:      "fail",
+				Message:     fmt.Sprintf("%s is invalid: %v", target.template, err),
+				Remediation: fmt.Sprintf("Fix templates/%s in blueprint %s", target.template, ws.Config.Blueprint),
+			})
+			continue
+		}
+
+		valid := 0
+		for _, file := range files {
+			if check, ok := checkSchemaFile(ws, target, s, file); !ok {
+				checks = append(checks, check)
+			} else {
+				valid++
+			}
+		}
+		if valid > 0 {
+			checks = append(checks, Check{
+				Name:    target.kind + "s",
+				Status:  "pass",
+				Message: fmt.Sprintf("%d file(s) match %s", valid, target.template),
+			})
+		}
+	}
+
+	return checks
+}
+
+// checkSchemaFile validates one file, returning a failing check if it is invalid
+func checkSchemaFile(ws *workspace.Workspace, target schemaTarget, s *schema.Schema, file string) (Check, bool) {
+	relPath, err := filepath.Rel(ws.Root, file)
+	if err != nil {
+		relPath = file
+	}
+	check := Check{
+		Name:        fmt.Sprintf("%s: %s", target.kind, relPath),
+		Status:      "fail",
+		Remediation: fmt.Sprintf("Edit %s to match the blueprint's %s", relPath, target.template),
+		Path:        file,
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		check.Message = fmt.Sprintf("cannot read file: %v", err)
+		return check, false
+	}
+
+	violations, err := s.ValidateJSON(data)
+	if err != nil {
+		check.Message = err.Error()
+		return check, false
+	}
+	if len(violations) == 0 {
+		return check, true
+	}
+
+	check.Message = fmt.Sprintf("%d schema violation(s)", len(violations))
+	for _, violation := range violations {
+		check.Details = append(check.Details, fmt.Sprintf("%s %s", relPath, violation))
+	}
+	return check, false
+}
+
+// blueprints returns the blueprint manager, loading it on first use
+func (v *Validator) blueprints() (*blueprint.Manager, error) {
+	if v.bpManager == nil {
+		m, err := blueprint.NewManager()
+		if err != nil {
+			return nil, err
+		}
+		v.bpManager = m
+	}
+	return v.bpManager, nil
+}
@@ -1,13 +1,15 @@
 package validator
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 
+	"github.com/Wameedh/ccflow/internal/blueprint"
 	"github.com/Wameedh/ccflow/internal/config"
 	"github.com/Wameedh/ccflow/internal/installer"
+	"github.com/Wameedh/ccflow/internal/settings"
 	"github.com/Wameedh/ccflow/internal/util"
 	"github.com/Wameedh/ccflow/internal/workspace"
 )
@@ -15,6 +17,7 @@ import (
 // Validator performs health checks on a workflow
 type Validator struct {
 	installer *installer.Installer
+	bpManager *blueprint.Manager // loaded on first use by schema checks
 }
 
 // New creates a new Validator
@@ -24,53 +27,58 @@ func New() *Validator {
 	}
 }
 
-// StatusResult contains the result of a status check
+// StatusResult contains the result of a status check. The tags are the
+// schema of ccflow status --output json|yaml (docs/OUTPUT.md).
 type StatusResult struct {
-	WorkflowName string
-	Blueprint    string
-	Topology     config.Topology
-	HubPath      string
-	DocsPath     string
-	Repos        []RepoStatus
-	Hooks        []HookStatus
-	HooksEnabled bool
-	GatesEnabled bool
-	Errors       []string
-	Warnings     []string
+	WorkflowName string          `json:"workflow_name" yaml:"workflow_name"`
+	Blueprint    string          `json:"blueprint" yaml:"blueprint"`
+	Topology     config.Topology `json:"topology" yaml:"topology"`
+	HubPath      string          `json:"hub_path" yaml:"hub_path"`
+	DocsPath     string          `json:"docs_path" yaml:"docs_path"`
+	Repos        []RepoStatus    `json:"repos" yaml:"repos"`
+	Hooks        []HookStatus    `json:"hooks" yaml:"hooks"`
+	HooksEnabled bool            `json:"hooks_enabled" yaml:"hooks_enabled"`
+	GatesEnabled bool            `json:"gates_enabled" yaml:"gates_enabled"`
+	Errors       []string        `json:"errors" yaml:"errors"`
+	Warnings     []string        `json:"warnings" yaml:"warnings"`
 }
 
 // RepoStatus represents the status of a single repo
 type RepoStatus struct {
-	Name    string
-	Path    string
-	Kind    config.RepoKind
-	Status  string // "ok", "broken", "missing"
-	Message string
+	Name    string          `json:"name" yaml:"name"`
+	Path    string          `json:"path" yaml:"path"`
+	Kind    config.RepoKind `json:"kind" yaml:"kind"`
+	Status  string          `json:"status" yaml:"status"` // "ok", "broken", "missing"
+	Message string          `json:"message" yaml:"message"`
 }
 
 // HookStatus represents the status of a hook
 type HookStatus struct {
-	Name       string
-	ScriptPath string
-	Exists     bool
-	Executable bool
-	Events     []string
+	Name       string   `json:"name" yaml:"name"`
+	ScriptPath string   `json:"script_path" yaml:"script_path"`
+	Exists     bool     `json:"exists" yaml:"exists"`
+	Executable bool     `json:"executable" yaml:"executable"`
+	Events     []string `json:"events" yaml:"events"`
 }
 
-// DoctorResult contains the result of a doctor check
+// DoctorResult contains the result of a doctor check. The tags are the
+// schema of ccflow doctor --output json|yaml (docs/OUTPUT.md).
 type DoctorResult struct {
-	Checks   []Check
-	Passed   int
-	Failed   int
-	Warnings int
+	Checks   []Check `json:"checks" yaml:"checks"`
+	Passed   int     `json:"passed" yaml:"passed"`
+	Failed   int     `json:"failed" yaml:"failed"`
+	Warnings int     `json:"warnings" yaml:"warnings"`
 }
 
 // Check represents a single doctor check
 type Check struct {
-	Name        string
-	Status      string // "pass", "fail", "warn"
-	Message     string
-	Remediation string
+	Name        string   `json:"name" yaml:"name"`
+	Status      string   `json:"status" yaml:"status"` // "pass", "fail", "warn"
+	Message     string   `json:"message" yaml:"message"`
+	Details     []string `json:"details,omitempty" yaml:"details,omitempty"` // one line per problem, e.g. "<file> <json pointer>: <violation>"
+	Remediation string   `json:"remediation,omitempty" yaml:"remediation,omitempty"`
+	Path        string   `json:"path,omitempty" yaml:"path,omitempty"` // file the check is about, where there is one
+	Fix         *Fix     `json:"fix,omitempty" yaml:"fix,omitempty"`   // set when doctor --fix can repair the problem
 }
 
 // Status performs a status check on the workflow
@@ -81,8 +89,11 @@ func (v *Validator) Status(ws *workspace.Workspace) *StatusResult {
 		Topology:     ws.Topology,
 		HubPath:      ws.GetHubPath(),
 		DocsPath:     ws.GetDocsPath(),
+		Repos:        []RepoStatus{},
 		HooksEnabled: ws.Config.Hooks.Enabled,
 		GatesEnabled: ws.Config.Gates.Enabled,
+		Errors:       []string{},
+		Warnings:     []string{},
 	}
 
 	// Check repos
@@ -147,57 +158,38 @@ func (v *Validator) checkRepo(ws *workspace.Workspace, repo config.RepoConfig) R
 	return status
 }
 
-// checkHooks checks the status of all configured hooks
+// checkHooks checks the status of all hook scripts registered in settings.json
 func (v *Validator) checkHooks(ws *workspace.Workspace) []HookStatus {
-	var hooks []HookStatus
+	hooks := []HookStatus{}
 
-	settingsPath := filepath.Join(ws.GetHubPath(), "settings.json")
-	if !util.FileExists(settingsPath) {
-		return hooks
-	}
-
-	data, err := os.ReadFile(settingsPath)
+	s, err := settings.Load(filepath.Join(ws.GetHubPath(), settings.FileName))
 	if err != nil {
 		return hooks
 	}
 
-	var settings struct {
-		Hooks []struct {
-			Event    string   `json:"event"`
-			Script   string   `json:"script"`
-			Commands []string `json:"commands,omitempty"`
-		} `json:"hooks"`
-	}
-
-	if err := json.Unmarshal(data, &settings); err != nil {
-		return hooks
-	}
-
-	// Group by script
-	hookMap := make(map[string]*HookStatus)
-	for _, h := range settings.Hooks {
-		scriptPath := h.Script
-		if !filepath.IsAbs(scriptPath) {
-			scriptPath = filepath.Join(ws.GetHubPath(), h.Script)
+	// Group by script, in the order hooks are registered
+	index := make(map[string]int)
+	for _, ref := range s.CommandHooks() {
+		script := settings.ScriptPath(ref.Command)
+		if script == "" {
+			continue // inline command, nothing to check on disk
 		}
+		scriptPath := filepath.Join(ws.GetHubPath(), script)
 
-		existing, ok := hookMap[scriptPath]
-		if !ok {
-			hookStatus := &HookStatus{
-				Name:       filepath.Base(scriptPath),
-				ScriptPath: scriptPath,
-				Exists:     util.FileExists(scriptPath),
-				Executable: util.IsExecutable(scriptPath),
-				Events:     []string{h.Event},
+		if i, ok := index[scriptPath]; ok {
+			if !slices.Contains(hooks[i].Events, ref.Event) {
+				hooks[i].Events = append(hooks[i].Events, ref.Event)
 			}
-			hookMap[scriptPath] = hookStatus
-		} else {
-			existing.Events = append(existing.Events, h.Event)
+			continue
 		}
-	}
-
-	for _, h := range hookMap {
-		hooks = append(hooks, *h)
+		index[scriptPath] = len(hooks)
+		hooks = append(hooks, HookStatus{
+			Name:       filepath.Base(scriptPath),
+			ScriptPath: scriptPath,
+			Exists:     util.FileExists(scriptPath),
+			Executable: util.IsExecutable(scriptPath),
+			Events:     []string{ref.Event},
+		})
 	}
 
 	return hooks
@@ -205,7 +197,7 @@ func (v *Validator) checkHooks(ws *workspace.Workspace) []HookStatus {
 
 // Doctor performs comprehensive health checks
 func (v *Validator) Doctor(ws *workspace.Workspace) *DoctorResult {
-	result := &DoctorResult{}
+	result := &DoctorResult{Checks: []Check{}}
 
 	// Check 1: Marker file exists
 	result.addCheck(v.checkMarker(ws))
@@ -228,6 +220,16 @@ func (v *Validator) Doctor(ws *workspace.Workspace) *DoctorResult {
 	// Check 5: Required directories exist
 	result.addCheck(v.checkDirectories(ws))
 
+	// Check 6: State, experiment and infra request files match their schemas
+	for _, schemaCheck := range v.checkSchemaFiles(ws) {
+		result.addCheck(schemaCheck)
+	}
+
+	// Check 7: Parallel groups reference hub agents and don't conflict
+	for _, parallelCheck := range v.checkParallel(ws) {
+		result.addCheck(parallelCheck)
+	}
+
 	return result
 }
 
@@ -246,7 +248,7 @@ func (r *DoctorResult) addCheck(check Check) {
 
 // checkMarker verifies the workflow marker file
 func (v *Validator) checkMarker(ws *workspace.Workspace) Check {
-	check := Check{Name: "Workflow marker"}
+	check := Check{Name: "Workflow marker", Path: ws.ConfigPath}
 
 	if util.FileExists(ws.ConfigPath) {
 		check.Status = "pass"
@@ -262,14 +264,14 @@ func (v *Validator) checkMarker(ws *workspace.Workspace) Check {
 
 // checkSettingsJSON verifies settings.json is valid
 func (v *Validator) checkSettingsJSON(ws *workspace.Workspace) Check {
-	check := Check{Name: "Settings JSON"}
-
 	settingsPath := filepath.Join(ws.GetHubPath(), "settings.json")
+	check := Check{Name: "Settings JSON", Path: settingsPath}
 
 	if !util.FileExists(settingsPath) {
 		check.Status = "warn"
 		check.Message = "settings.json not found"
 		check.Remediation = "Run 'ccflow run' to generate settings.json"
+		check.Fix = v.fixSettings(ws, settingsPath, false)
 		return check
 	}
 
@@ -280,11 +282,11 @@ func (v *Validator) checkSettingsJSON(ws *workspace.Workspace) Check {
 		return check
 	}
 
-	var settings interface{}
-	if err := json.Unmarshal(data, &settings); err != nil {
+	if _, err := settings.Parse(data); err != nil {
 		check.Status = "fail"
-		check.Message = fmt.Sprintf("invalid JSON in settings.json: %v", err)
-		check.Remediation = "Fix JSON syntax errors in settings.json"
+		check.Message = fmt.Sprintf("invalid settings.json: %v", err)
+		check.Remediation = "Fix the JSON syntax or structure of settings.json"
+		check.Fix = v.fixSettings(ws, settingsPath, true)
 		return check
 	}
 
@@ -299,7 +301,7 @@ func (v *Validator) checkHookScripts(ws *workspace.Workspace) []Check {
 
 	hooks := v.checkHooks(ws)
 	for _, hook := range hooks {
-		check := Check{Name: fmt.Sprintf("Hook: %s", hook.Name)}
+		check := Check{Name: fmt.Sprintf("Hook: %s", hook.Name), Path: hook.ScriptPath}
 
 		if !hook.Exists {
 			check.Status = "fail"
@@ -309,6 +311,7 @@ func (v *Validator) checkHookScripts(ws *workspace.Workspace) []Check {
 			check.Status = "warn"
 			check.Message = fmt.Sprintf("script not executable: %s", hook.ScriptPath)
 			check.Remediation = fmt.Sprintf("Run 'chmod +x %s'", hook.ScriptPath)
+			check.Fix = fixExecutable(hook.ScriptPath)
 		} else {
 			check.Status = "pass"
 			check.Message = fmt.Sprintf("script exists and is executable (events: %v)", hook.Events)
@@ -326,7 +329,7 @@ func (v *Validator) checkSymlinks(ws *workspace.Workspace) []Check {
 
 	for _, repo := range ws.Config.Repos {
 		repoPath := filepath.Join(ws.Root, repo.Path)
-		check := Check{Name: fmt.Sprintf("Symlink: %s", repo.Name)}
+		check := Check{Name: fmt.Sprintf("Symlink: %s", repo.Name), Path: filepath.Join(repoPath, ".claude")}
 
 		if !util.DirExists(repoPath) {
 			check.Status = "warn"
@@ -343,6 +346,7 @@ func (v *Validator) checkSymlinks(ws *workspace.Workspace) []Check {
 			check.Status = "fail"
 			check.Message = msg
 			check.Remediation = fmt.Sprintf("Run 'ccflow run' to fix symlinks, or manually create: ln -s <hub>/.claude %s/.claude", repoPath)
+			check.Fix = v.fixInstall(ws, repo)
 		}
 
 		checks = append(checks, check)
@@ -387,6 +391,7 @@ func (v *Validator) checkDirectories(ws *workspace.Workspace) Check {
 		check.Status = "fail"
 		check.Message = fmt.Sprintf("missing directories: %v", missing)
 		check.Remediation = "Run 'ccflow run' to create missing directories"
+		check.Fix = fixDirectories(missing)
 	}
 
 	return check
@@ -1,8 +1,10 @@
 package validator
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Wameedh/ccflow/internal/config"
@@ -22,9 +24,11 @@ func createTestWorkspace(t *testing.T) (*workspace.Workspace, string) {
 	// Create settings.json
 	settingsPath := filepath.Join(claudeDir, "settings.json")
 	settings := `{
-		"hooks": [
-			{"event": "Stop", "script": "./hooks/end-of-turn.sh"}
-		],
+		"hooks": {
+			"Stop": [
+				{"hooks": [{"type": "command", "command": "./hooks/end-of-turn.sh"}]}
+			]
+		},
 		"permissions": {}
 	}`
 	os.WriteFile(settingsPath, []byte(settings), 0644)
@@ -97,6 +101,12 @@ func TestStatus(t *testing.T) {
 		t.Error("Expected hooks to be enabled")
 	}
 
+	if len(result.Hooks) != 1 || result.Hooks[0].Name != "end-of-turn.sh" || !result.Hooks[0].Exists {
+		t.Errorf("Expected end-of-turn.sh hook from settings.json, got %+v", result.Hooks)
+	} else if len(result.Hooks[0].Events) != 1 || result.Hooks[0].Events[0] != "Stop" {
+		t.Errorf("Expected Stop event, got %v", result.Hooks[0].Events)
+	}
+
 	if len(result.Repos) != 1 {
 		t.Errorf("Expected 1 repo, got %d", len(result.Repos))
 	}
@@ -106,6 +116,31 @@ func TestStatus(t *testing.T) {
 	}
 }
 
+// TestStatus_JSONSchema pins the field names of ccflow status --output json,
+// documented in docs/OUTPUT.md
+func TestStatus_JSONSchema(t *testing.T) {
+	ws, _ := createTestWorkspace(t)
+	result := New().Status(ws)
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"workflow_name", "blueprint", "topology", "hub_path", "docs_path", "repos", "hooks", "hooks_enabled", "gates_enabled", "errors", "warnings"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("Expected %s in status output", key)
+		}
+	}
+	if fields["errors"] == nil || fields["warnings"] == nil {
+		t.Error("Expected errors and warnings to be lists, not null")
+	}
+}
+
 func TestDoctor(t *testing.T) {
 	ws, _ := createTestWorkspace(t)
 	v := New()
@@ -184,3 +219,169 @@ func TestDoctor_NonExecutableHook(t *testing.T) {
 		t.Error("Expected warning for non-executable hook")
 	}
 }
+
+func TestDoctor_StateFileSchema(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("CCFLOW_BLUEPRINT_PATH", "")
+
+	ws, tmpDir := createTestWorkspace(t)
+	stateDir := filepath.Join(tmpDir, "docs", "workflow", "state")
+
+	valid := `{"id": "login", "title": "Login", "status": "design", "created_at": "2024-05-01T10:00:00Z"}`
+	invalid := `{"id": "Search Page", "title": "Search", "status": "shipped"}`
+	os.WriteFile(filepath.Join(stateDir, "login.json"), []byte(valid), 0644)
+	os.WriteFile(filepath.Join(stateDir, "search.json"), []byte(invalid), 0644)
+
+	v := New()
+	result := v.Doctor(ws)
+
+	var failed *Check
+	passed := false
+	for i, check := range result.Checks {
+		switch check.Name {
+		case "State file: docs/workflow/state/search.json":
+			failed = &result.Checks[i]
+		case "State files":
+			passed = check.Status == "pass"
+		}
+	}
+
+	if !passed {
+		t.Error("Expected passing check for the valid state file")
+	}
+	if failed == nil || failed.Status != "fail" {
+		t.Fatalf("Expected failing check for search.json, got %+v", result.Checks)
+	}
+
+	want := []string{
+		"docs/workflow/state/search.json /created_at: required property is missing",
+		`docs/workflow/state/search.json /id: value "Search Page" does not match pattern ^[a-z0-9-]+$`,
+	}
+	for _, w := range want {
+		found := false
+		for _, d := range failed.Details {
+			if d == w {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected detail %q, got %v", w, failed.Details)
+		}
+	}
+	if len(failed.Details) != 3 {
+		t.Errorf("Expected 3 violations, got %v", failed.Details)
+	}
+}
+
+func TestDoctor_ParallelGroups(t *testing.T) {
+	ws, tmpDir := createTestWorkspace(t)
+	agentsDir := filepath.Join(tmpDir, "workflow-hub", ".claude", "agents")
+	os.MkdirAll(agentsDir, 0755)
+	for _, agent := range []string{"backend-agent", "frontend-agent"} {
+		os.WriteFile(filepath.Join(agentsDir, agent+".md"), []byte("# "+agent+"\n"), 0644)
+	}
+
+	findCheck := func(result *DoctorResult) *Check {
+		for i, check := range result.Checks {
+			if check.Name == "Parallel groups" {
+				return &result.Checks[i]
+			}
+		}
+		return nil
+	}
+
+	// Disabled with no groups: nothing to check
+	if check := findCheck(New().Doctor(ws)); check != nil {
+		t.Errorf("Expected no parallel check, got %+v", check)
+	}
+
+	ws.Config.Parallel = config.ParallelConfig{
+		Enabled:  true,
+		SyncGate: "all",
+		Groups: []config.ParallelGroup{
+			{Name: "build", Agents: []string{"backend-agent", "frontend-agent"}, Sync: "all"},
+		},
+	}
+	if check := findCheck(New().Doctor(ws)); check == nil || check.Status != "pass" {
+		t.Errorf("Expected passing parallel check, got %+v", check)
+	}
+
+	ws.Config.Parallel.Groups = append(ws.Config.Parallel.Groups,
+		config.ParallelGroup{Name: "review", Agents: []string{"frontend-agent", "ghost-agent"}, Sync: "most"})
+	check := findCheck(New().Doctor(ws))
+	if check == nil || check.Status != "fail" {
+		t.Fatalf("Expected failing parallel check, got %+v", check)
+	}
+	if len(check.Details) != 3 {
+		t.Errorf("Expected 3 problems, got %v", check.Details)
+	}
+}
+
+func TestDoctor_Fix(t *testing.T) {
+	ws, tmpDir := createTestWorkspace(t)
+
+	hookPath := filepath.Join(tmpDir, "workflow-hub", ".claude", "hooks", "end-of-turn.sh")
+	os.Chmod(hookPath, 0644)
+	os.RemoveAll(filepath.Join(tmpDir, "docs", "workflow", "designs"))
+	linkPath := filepath.Join(tmpDir, "web", ".claude")
+	os.Remove(linkPath)
+	os.Symlink(tmpDir, linkPath)
+
+	v := New()
+	result := v.Doctor(ws)
+
+	fixable := result.Fixable()
+	names := make(map[string]bool)
+	for _, check := range fixable {
+		names[check.Name] = true
+	}
+	for _, name := range []string{"Hook: end-of-turn.sh", "Symlink: web", "Required directories"} {
+		if !names[name] {
+			t.Errorf("Expected a fix for %s, got %v", name, names)
+		}
+	}
+	if len(fixable) != len(names) || len(fixable) != 3 {
+		t.Fatalf("Expected 3 fixable checks, got %d", len(fixable))
+	}
+
+	for _, check := range fixable {
+		if err := check.Fix.Apply(); err != nil {
+			t.Fatalf("Fix for %s failed: %v", check.Name, err)
+		}
+	}
+
+	result = v.Doctor(ws)
+	if result.Failed > 0 || result.Warnings > 0 || len(result.Fixable()) > 0 {
+		for _, check := range result.Checks {
+			t.Logf("%s: %s - %s", check.Name, check.Status, check.Message)
+		}
+		t.Error("Expected every check to pass after fixing")
+	}
+}
+
+func TestDoctor_FixSettings(t *testing.T) {
+	ws, tmpDir := createTestWorkspace(t)
+
+	settingsPath := filepath.Join(tmpDir, "workflow-hub", ".claude", "settings.json")
+	os.WriteFile(settingsPath, []byte("invalid json"), 0644)
+
+	v := New()
+	var fix *Fix
+	for _, check := range v.Doctor(ws).Fixable() {
+		if check.Name == "Settings JSON" {
+			fix = check.Fix
+		}
+	}
+	if fix == nil || len(fix.Paths) != 1 || fix.Paths[0] != settingsPath {
+		t.Fatalf("Expected a fix regenerating settings.json, got %+v", fix)
+	}
+
+	// The fix is described in the output schema, without its internals
+	data, err := json.Marshal(Check{Name: "Settings JSON", Fix: fix})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"fix":{"description":"regenerate `) || strings.Contains(string(data), "Paths") {
+		t.Errorf("Unexpected JSON for a fix: %s", data)
+	}
+}
@@ -33,7 +33,18 @@ type Workspace struct {
 // 1. --workspace flag (passed as override)
 // 2. CCFLOW_WORKSPACE environment variable
 // 3. Walk up from current directory looking for markers
+//
+// A workflow found in the registry has its LastUsedAt updated.
 func Discover(override string) (*Workspace, error) {
+	ws, err := discover(override)
+	if err != nil {
+		return nil, err
+	}
+	markUsed(ws.Root)
+	return ws, nil
+}
+
+func discover(override string) (*Workspace, error) {
 	// Check override first
 	if override != "" {
 		return loadWorkspace(override)
@@ -166,6 +177,15 @@ func (w *Workspace) GetHubPath() string {
 	return filepath.Join(w.Root, ".claude")
 }
 
+// GetCCFlowPath returns the absolute path to the directory holding ccflow's
+// own state: .ccflow in the repo, or .ccflow in the hub for multi-repo
+func (w *Workspace) GetCCFlowPath() string {
+	if w.Topology == config.TopologyMultiRepo {
+		return filepath.Join(w.Root, w.Config.Paths.Hub, ".ccflow")
+	}
+	return filepath.Join(w.Root, ".ccflow")
+}
+
 // GetDocsPath returns the absolute path to the docs directory
 func (w *Workspace) GetDocsPath() string {
 	return filepath.Join(w.Root, w.Config.Paths.Docs)
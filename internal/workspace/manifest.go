@@ -0,0 +1,105 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Wameedh/ccflow/internal/config"
+	"github.com/Wameedh/ccflow/internal/util"
+)
+
+// ManifestFile is the managed-files manifest in the hub's .claude directory
+const ManifestFile = ".ccflow-managed.json"
+
+// BaseDir holds, under the ccflow directory, the template content each
+// managed file was last rendered from. Upgrade merges against it.
+const BaseDir = "base"
+
+// LoadManifest reads the managed-files manifest from the hub's .claude
+// directory. A missing or invalid manifest yields an empty one.
+func LoadManifest(hubPath string) *config.ManagedFilesManifest {
+	manifest := &config.ManagedFilesManifest{
+		Version: 1,
+		Files:   make(map[string]config.ManagedFileInfo),
+	}
+
+	data, err := os.ReadFile(filepath.Join(hubPath, ManifestFile))
+	if err == nil {
+		_ = json.Unmarshal(data, manifest) // Ignore errors, use default if invalid
+	}
+	if manifest.Files == nil {
+		manifest.Files = make(map[string]config.ManagedFileInfo)
+	}
+
+	return manifest
+}
+
+// SaveManifest writes the managed-files manifest to the hub's .claude directory
+func SaveManifest(hubPath string, manifest *config.ManagedFilesManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", ManifestFile, err)
+	}
+
+	return util.SafeWriteFile(filepath.Join(hubPath, ManifestFile), data, true)
+}
+
+// baseDir returns the directory holding the bases for the hub's .claude
+// directory: .ccflow beside it, in the repo or the hub
+func baseDir(hubPath string) string {
+	return filepath.Join(filepath.Dir(hubPath), ".ccflow", BaseDir)
+}
+
+// LoadBase returns the template content a managed file was last rendered
+// from, relative to the hub's .claude directory
+func LoadBase(hubPath, relPath string) ([]byte, bool) {
+	data, err := os.ReadFile(filepath.Join(baseDir(hubPath), relPath))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// SaveBase records the template content a managed file was rendered from
+func SaveBase(hubPath, relPath string, content []byte) error {
+	return util.SafeWriteFile(filepath.Join(baseDir(hubPath), relPath), content, true)
+}
+
+// RemoveBase forgets the template content recorded for a managed file
+func RemoveBase(hubPath, relPath string) error {
+	err := os.Remove(filepath.Join(baseDir(hubPath), relPath))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// RecordManaged records a file written from a template in the manifest and
+// keeps its content as the base upgrade merges against
+func RecordManaged(hubPath string, manifest *config.ManagedFilesManifest, templateID, relPath string, content []byte) error {
+	manifest.Track(relPath, templateID, util.HashBytes(content))
+	return SaveBase(hubPath, relPath, content)
+}
+
+// RecordManagedFile records a single file written from a template in the
+// hub's manifest
+func RecordManagedFile(hubPath, templateID, relPath string, content []byte) error {
+	manifest := LoadManifest(hubPath)
+	if err := RecordManaged(hubPath, manifest, templateID, relPath, content); err != nil {
+		return err
+	}
+	return SaveManifest(hubPath, manifest)
+}
+
+// ForgetManagedFile stops tracking a file whose content no longer comes from
+// a template, e.g. one overwritten with the user's own content
+func ForgetManagedFile(hubPath, relPath string) error {
+	manifest := LoadManifest(hubPath)
+	manifest.Untrack(relPath)
+	if err := RemoveBase(hubPath, relPath); err != nil {
+		return err
+	}
+	return SaveManifest(hubPath, manifest)
+}
@@ -0,0 +1,64 @@
+package workspace
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Wameedh/ccflow/internal/util"
+)
+
+func TestRecordManagedFile(t *testing.T) {
+	hubPath := filepath.Join(t.TempDir(), "workflow-hub", ".claude")
+	relPath := filepath.Join("agents", "review-agent.md")
+	content := []byte("# Review\n")
+
+	if err := RecordManagedFile(hubPath, "web-dev/"+relPath, relPath, content); err != nil {
+		t.Fatalf("RecordManagedFile failed: %v", err)
+	}
+
+	manifest := LoadManifest(hubPath)
+	info, ok := manifest.Files[relPath]
+	if !ok {
+		t.Fatalf("%s not recorded", relPath)
+	}
+	if info.Hash != util.HashBytes(content) || info.TemplateID != "web-dev/"+relPath {
+		t.Errorf("Unexpected manifest entry: %+v", info)
+	}
+
+	// The base lives in .ccflow beside the .claude directory
+	if !util.FileExists(filepath.Join(filepath.Dir(hubPath), ".ccflow", BaseDir, relPath)) {
+		t.Error("Expected base under .ccflow/base")
+	}
+	if base, ok := LoadBase(hubPath, relPath); !ok || string(base) != string(content) {
+		t.Errorf("LoadBase() = %q, %v", base, ok)
+	}
+
+	// A removed file that is added again is no longer recorded as removed
+	manifest.Forget(relPath)
+	if err := SaveManifest(hubPath, manifest); err != nil {
+		t.Fatalf("SaveManifest failed: %v", err)
+	}
+	if err := RecordManagedFile(hubPath, "web-dev/"+relPath, relPath, content); err != nil {
+		t.Fatalf("RecordManagedFile failed: %v", err)
+	}
+	if LoadManifest(hubPath).IsRemoved(relPath) {
+		t.Error("Expected re-added file not to be marked removed")
+	}
+
+	if err := ForgetManagedFile(hubPath, relPath); err != nil {
+		t.Fatalf("ForgetManagedFile failed: %v", err)
+	}
+	if _, ok := LoadManifest(hubPath).Files[relPath]; ok {
+		t.Error("Expected file to be untracked")
+	}
+	if _, ok := LoadBase(hubPath, relPath); ok {
+		t.Error("Expected base to be removed")
+	}
+}
+
+func TestLoadManifest_Missing(t *testing.T) {
+	manifest := LoadManifest(t.TempDir())
+	if manifest.Files == nil || len(manifest.Files) != 0 {
+		t.Errorf("Expected an empty manifest, got %+v", manifest)
+	}
+}
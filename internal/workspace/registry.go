@@ -2,6 +2,8 @@ package workspace
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
@@ -14,15 +16,49 @@ const (
 	RegistryDir = ".ccflow"
 	// RegistryFile is the filename for the workflow registry
 	RegistryFile = "registry.json"
+	// RegistryVersion is the registry format this ccflow reads and writes
+	RegistryVersion = 1
 )
 
+// touchInterval is how stale LastUsedAt must be before discovering the
+// workflow updates it, so that scripted runs don't rewrite the registry on
+// every command
+const touchInterval = time.Minute
+
+// Concurrent ccflow processes take turns on the registry through a lock
+// file beside it. A lock older than registryLockStale is taken to belong
+// to a process that died and is broken.
+const (
+	registryLockTimeout = 10 * time.Second
+	registryLockStale   = 30 * time.Second
+	registryLockPoll    = 50 * time.Millisecond
+)
+
+// errRegistryTooNew is returned for a registry written by a newer ccflow,
+// which is left alone rather than treated as damaged
+var errRegistryTooNew = errors.New("registry format is too new")
+
+// ErrUnchanged is returned by an UpdateRegistry update that made no changes
+// to have nothing saved
+var ErrUnchanged = errors.New("registry unchanged")
+
+// registryMigrations upgrade a registry from the version at their index to
+// the next one. Version 0 is a registry written before it had a version.
+var registryMigrations = []func(reg *Registry){
+	func(reg *Registry) {
+		if reg.Workflows == nil {
+			reg.Workflows = []RegistryEntry{}
+		}
+	},
+}
+
 // RegistryEntry represents a workflow entry in the registry
 type RegistryEntry struct {
-	Name       string    `json:"name"`
-	Path       string    `json:"path"`
-	Blueprint  string    `json:"blueprint"`
-	CreatedAt  time.Time `json:"created_at"`
-	LastUsedAt time.Time `json:"last_used_at"`
+	Name       string    `json:"name" yaml:"name"`
+	Path       string    `json:"path" yaml:"path"`
+	Blueprint  string    `json:"blueprint" yaml:"blueprint"`
+	CreatedAt  time.Time `json:"created_at" yaml:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at" yaml:"last_used_at"`
 }
 
 // Registry represents the global workflow registry
@@ -40,18 +76,90 @@ func GetRegistryPath() (string, error) {
 	return filepath.Join(home, RegistryDir, RegistryFile), nil
 }
 
-// LoadRegistry loads the global registry
+// LoadRegistry loads the global registry. A registry that can't be parsed
+// is read from the backup kept by the last save instead.
 func LoadRegistry() (*Registry, error) {
 	path, err := GetRegistryPath()
 	if err != nil {
 		return nil, err
 	}
 
+	reg, _, err := readRegistry(path)
+	return reg, err
+}
+
+// SaveRegistry saves the global registry. Use UpdateRegistry to change it,
+// so that changes made by other ccflow processes since it was loaded are
+// not lost.
+func SaveRegistry(reg *Registry) error {
+	path, err := GetRegistryPath()
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockRegistry(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return writeRegistry(path, reg)
+}
+
+// UpdateRegistry loads the global registry, applies update and saves the
+// result, holding the registry lock throughout. Nothing is saved if update
+// returns an error, which is returned unless it is ErrUnchanged.
+func UpdateRegistry(update func(reg *Registry) error) error {
+	path, err := GetRegistryPath()
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockRegistry(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	reg, recovered, err := readRegistry(path)
+	if err != nil {
+		return err
+	}
+	if recovered {
+		// Keep the damaged file for inspection; it is replaced below
+		_ = os.Rename(path, fmt.Sprintf("%s.corrupt-%s", path, time.Now().Format("20060102-150405")))
+	}
+
+	if err := update(reg); err == ErrUnchanged {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return writeRegistry(path, reg)
+}
+
+// readRegistry reads and migrates the registry at path, falling back to its
+// backup when it is damaged. recovered reports that the backup was used.
+func readRegistry(path string) (reg *Registry, recovered bool, err error) {
 	if !util.FileExists(path) {
 		// Return empty registry if file doesn't exist
-		return &Registry{Version: 1, Workflows: []RegistryEntry{}}, nil
+		return &Registry{Version: RegistryVersion, Workflows: []RegistryEntry{}}, false, nil
 	}
 
+	reg, err = parseRegistry(path)
+	if err == nil || errors.Is(err, errRegistryTooNew) {
+		return reg, false, err
+	}
+
+	backup, backupErr := parseRegistry(path + ".bak")
+	if backupErr != nil {
+		return nil, false, fmt.Errorf("registry %s is damaged and has no usable backup: %w", path, err)
+	}
+	return backup, true, nil
+}
+
+// parseRegistry reads one registry file and migrates it to RegistryVersion
+func parseRegistry(path string) (*Registry, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -61,27 +169,122 @@ func LoadRegistry() (*Registry, error) {
 	if err := json.Unmarshal(data, &reg); err != nil {
 		return nil, err
 	}
+	if reg.Version > RegistryVersion {
+		return nil, fmt.Errorf("%w: %s is version %d, this ccflow supports up to %d; upgrade ccflow", errRegistryTooNew, path, reg.Version, RegistryVersion)
+	}
+
+	for v := reg.Version; v < RegistryVersion; v++ {
+		registryMigrations[v](&reg)
+	}
+	reg.Version = RegistryVersion
 
 	return &reg, nil
 }
 
-// SaveRegistry saves the global registry
-func SaveRegistry(reg *Registry) error {
-	path, err := GetRegistryPath()
+// writeRegistry replaces the registry at path through a rename, so readers
+// see either the old or the new file and never a partial one. The old file
+// is kept as the backup first if it is sound.
+func writeRegistry(path string, reg *Registry) error {
+	if dirErr := util.EnsureDir(filepath.Dir(path)); dirErr != nil {
+		return dirErr
+	}
+
+	data, err := json.MarshalIndent(reg, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	if dirErr := util.EnsureDir(filepath.Dir(path)); dirErr != nil {
-		return dirErr
+	if _, err := parseRegistry(path); err == nil {
+		if current, readErr := os.ReadFile(path); readErr == nil {
+			if err := writeFileAtomic(path+".bak", current); err != nil {
+				return fmt.Errorf("failed to back up the registry: %w", err)
+			}
+		}
+	} else if errors.Is(err, errRegistryTooNew) {
+		return err
 	}
 
-	data, err := json.MarshalIndent(reg, "", "  ")
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes data to a temporary file beside path, syncs it and
+// renames it over path
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
 	if err != nil {
 		return err
 	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
 
-	return os.WriteFile(path, data, 0644)
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// lockRegistry takes the registry lock, waiting for other ccflow processes
+// to release it, and returns the function that releases it
+func lockRegistry(path string) (func(), error) {
+	if err := util.EnsureDir(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(registryLockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { _ = os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock the registry: %w", err)
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > registryLockStale {
+			breakStaleLock(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("registry is locked by another ccflow process (remove %s if none is running)", lockPath)
+		}
+		time.Sleep(registryLockPoll)
+	}
+}
+
+// breakStaleLock removes a lock left by a ccflow that died. Another process
+// may break the same lock and take a new one between our stat and removal,
+// so the lock is first renamed to a name of our own, which only one process
+// can do, and checked again there. A lock that turns out to be fresh is
+// linked back, unless yet another process has locked in the meantime.
+func breakStaleLock(lockPath string) {
+	tmp, err := os.CreateTemp(filepath.Dir(lockPath), filepath.Base(lockPath)+".stale-*")
+	if err != nil {
+		return
+	}
+	tmp.Close()
+	stale := tmp.Name()
+	defer os.Remove(stale)
+
+	if err := os.Rename(lockPath, stale); err != nil {
+		return // Broken by someone else
+	}
+	if info, err := os.Stat(stale); err == nil && time.Since(info.ModTime()) <= registryLockStale {
+		_ = os.Link(stale, lockPath) // Fails, leaving the newer lock, if it exists
+	}
 }
 
 // AddOrUpdateWorkflow adds or updates a workflow in the registry
@@ -117,3 +320,109 @@ func (r *Registry) FindByName(name string) *RegistryEntry {
 	}
 	return nil
 }
+
+// FindByPath finds a workflow by its workspace path
+func (r *Registry) FindByPath(path string) *RegistryEntry {
+	for _, entry := range r.Workflows {
+		if entry.Path == path {
+			return &entry
+		}
+	}
+	return nil
+}
+
+// Touch sets LastUsedAt of the workflow at path and reports whether it is
+// registered
+func (r *Registry) Touch(path string, at time.Time) bool {
+	for i := range r.Workflows {
+		if r.Workflows[i].Path == path {
+			r.Workflows[i].LastUsedAt = at
+			return true
+		}
+	}
+	return false
+}
+
+// Relocate points the workflow with the given name at a new path
+func (r *Registry) Relocate(name, path string) error {
+	if other := r.FindByPath(path); other != nil && other.Name != name {
+		return fmt.Errorf("%s is already registered as '%s'", path, other.Name)
+	}
+	for i := range r.Workflows {
+		if r.Workflows[i].Name == name {
+			r.Workflows[i].Path = path
+			return nil
+		}
+	}
+	return fmt.Errorf("workflow '%s' is not registered", name)
+}
+
+// Prune removes the workflows whose health has problems and returns them
+func (r *Registry) Prune() []RegistryEntry {
+	var kept, pruned []RegistryEntry
+	for _, entry := range r.Workflows {
+		if entry.Health().OK() {
+			kept = append(kept, entry)
+		} else {
+			pruned = append(pruned, entry)
+		}
+	}
+	if kept == nil {
+		kept = []RegistryEntry{}
+	}
+	r.Workflows = kept
+	return pruned
+}
+
+// EntryHealth describes whether a registered workflow is still where the
+// registry says
+type EntryHealth struct {
+	Exists      bool   // The path is a directory
+	MarkerValid bool   // It holds a workflow.yaml that parses
+	Blueprint   string // Blueprint named in workflow.yaml
+	Problem     string // Why the entry is unhealthy, empty if it isn't
+}
+
+// OK reports whether the workflow was found at its registered path
+func (h EntryHealth) OK() bool {
+	return h.Exists && h.MarkerValid
+}
+
+// Health checks the workflow at the entry's path
+func (e RegistryEntry) Health() EntryHealth {
+	var h EntryHealth
+	if !util.DirExists(e.Path) {
+		h.Problem = "path does not exist"
+		return h
+	}
+	h.Exists = true
+
+	ws, err := loadWorkspace(e.Path)
+	if err != nil {
+		h.Problem = "no valid workflow.yaml"
+		return h
+	}
+	h.MarkerValid = true
+	h.Blueprint = ws.Config.Blueprint
+	return h
+}
+
+// markUsed updates LastUsedAt of a registered workflow that has just been
+// discovered. The registry is optional, so failures are ignored.
+func markUsed(root string) {
+	reg, err := LoadRegistry()
+	if err != nil {
+		return
+	}
+	entry := reg.FindByPath(root)
+	if entry == nil || time.Since(entry.LastUsedAt) < touchInterval {
+		return
+	}
+
+	_ = UpdateRegistry(func(reg *Registry) error {
+		if !reg.Touch(root, time.Now()) {
+			return ErrUnchanged // Unregistered meanwhile
+		}
+		return nil
+	})
+}
@@ -0,0 +1,298 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Wameedh/ccflow/internal/config"
+)
+
+// setupRegistryHome points the registry at a temporary home directory
+func setupRegistryHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	return filepath.Join(home, RegistryDir, RegistryFile)
+}
+
+func TestUpdateRegistry_Concurrent(t *testing.T) {
+	setupRegistryHome(t)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- UpdateRegistry(func(reg *Registry) error {
+				reg.AddOrUpdateWorkflow(RegistryEntry{Name: fmt.Sprintf("wf-%d", i), Path: fmt.Sprintf("/work/%d", i)})
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("UpdateRegistry failed: %v", err)
+		}
+	}
+
+	reg, err := LoadRegistry()
+	if err != nil {
+		t.Fatalf("LoadRegistry failed: %v", err)
+	}
+	if len(reg.Workflows) != 20 {
+		t.Errorf("Expected 20 workflows, got %d", len(reg.Workflows))
+	}
+}
+
+func TestUpdateRegistry_ErrorSavesNothing(t *testing.T) {
+	path := setupRegistryHome(t)
+
+	err := UpdateRegistry(func(reg *Registry) error {
+		reg.AddOrUpdateWorkflow(RegistryEntry{Name: "shop", Path: "/work/shop"})
+		return fmt.Errorf("canceled")
+	})
+	if err == nil {
+		t.Fatal("Expected the update's error")
+	}
+	if _, statErr := os.Stat(path); !os.IsNotExist(statErr) {
+		t.Error("Expected no registry to be written")
+	}
+}
+
+func TestLoadRegistry_RecoversFromBackup(t *testing.T) {
+	path := setupRegistryHome(t)
+
+	for _, name := range []string{"shop", "blog"} {
+		name := name
+		if err := UpdateRegistry(func(reg *Registry) error {
+			reg.AddOrUpdateWorkflow(RegistryEntry{Name: name, Path: "/work/" + name})
+			return nil
+		}); err != nil {
+			t.Fatalf("UpdateRegistry failed: %v", err)
+		}
+	}
+
+	// A truncated registry falls back to the backup, which holds the
+	// registry before the last save
+	if err := os.WriteFile(path, []byte(`{"version": 1, "workfl`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reg, err := LoadRegistry()
+	if err != nil {
+		t.Fatalf("LoadRegistry failed: %v", err)
+	}
+	if reg.FindByName("shop") == nil {
+		t.Error("Expected the backup's workflows")
+	}
+
+	// Updating replaces the damaged file and keeps it aside
+	if err := UpdateRegistry(func(reg *Registry) error { return nil }); err != nil {
+		t.Fatalf("UpdateRegistry failed: %v", err)
+	}
+	if _, err := parseRegistry(path); err != nil {
+		t.Errorf("Expected a sound registry after the update: %v", err)
+	}
+	corrupt, _ := filepath.Glob(path + ".corrupt-*")
+	if len(corrupt) != 1 {
+		t.Errorf("Expected the damaged registry to be kept, got %v", corrupt)
+	}
+
+	// Without a usable backup the damage is reported
+	os.WriteFile(path, []byte("{"), 0644)
+	os.WriteFile(path+".bak", []byte("{"), 0644)
+	if _, err := LoadRegistry(); err == nil {
+		t.Error("Expected an error for a damaged registry without a backup")
+	}
+}
+
+func TestLoadRegistry_Versions(t *testing.T) {
+	path := setupRegistryHome(t)
+	os.MkdirAll(filepath.Dir(path), 0755)
+
+	// Registries written before the version field are migrated
+	os.WriteFile(path, []byte(`{"workflows": null}`), 0644)
+	reg, err := LoadRegistry()
+	if err != nil {
+		t.Fatalf("LoadRegistry failed: %v", err)
+	}
+	if reg.Version != RegistryVersion || reg.Workflows == nil {
+		t.Errorf("Expected a migrated registry, got %+v", reg)
+	}
+
+	// Newer registries are refused and left alone
+	newer := fmt.Sprintf(`{"version": %d, "workflows": []}`, RegistryVersion+1)
+	os.WriteFile(path, []byte(newer), 0644)
+	if _, err := LoadRegistry(); err == nil {
+		t.Error("Expected an error for a newer registry")
+	}
+	if err := UpdateRegistry(func(reg *Registry) error { return nil }); err == nil {
+		t.Error("Expected UpdateRegistry to refuse a newer registry")
+	}
+	if data, _ := os.ReadFile(path); string(data) != newer {
+		t.Error("Expected the newer registry to be untouched")
+	}
+}
+
+func TestLockRegistry_BreaksStaleLock(t *testing.T) {
+	path := setupRegistryHome(t)
+	os.MkdirAll(filepath.Dir(path), 0755)
+
+	lockPath := path + ".lock"
+	os.WriteFile(lockPath, []byte("12345\n"), 0644)
+	old := time.Now().Add(-2 * registryLockStale)
+	os.Chtimes(lockPath, old, old)
+
+	unlock, err := lockRegistry(path)
+	if err != nil {
+		t.Fatalf("lockRegistry failed: %v", err)
+	}
+	unlock()
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Error("Expected the lock to be released")
+	}
+}
+
+func TestLockRegistry_StaleLockContention(t *testing.T) {
+	path := setupRegistryHome(t)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	lockPath := path + ".lock"
+
+	for round := 0; round < 20; round++ {
+		os.WriteFile(lockPath, []byte("12345\n"), 0644)
+		old := time.Now().Add(-2 * registryLockStale)
+		os.Chtimes(lockPath, old, old)
+
+		// Both goroutines find the lock stale; only one may hold it at a time
+		var holders, overlaps atomic.Int32
+		var wg sync.WaitGroup
+		errs := make(chan error, 2)
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				unlock, err := lockRegistry(path)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if holders.Add(1) > 1 {
+					overlaps.Add(1)
+				}
+				time.Sleep(5 * time.Millisecond)
+				holders.Add(-1)
+				unlock()
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Fatalf("lockRegistry failed: %v", err)
+		}
+		if overlaps.Load() > 0 {
+			t.Fatalf("Round %d: both goroutines held the lock", round)
+		}
+	}
+
+	// A goroutine that saw the lock stale may get to break it only after the
+	// other has retaken it; the fresh lock must survive
+	os.WriteFile(lockPath, []byte("67890\n"), 0644)
+	breakStaleLock(lockPath)
+	if data, err := os.ReadFile(lockPath); err != nil || string(data) != "67890\n" {
+		t.Errorf("Expected the fresh lock to be kept, got %q, %v", data, err)
+	}
+	os.Remove(lockPath)
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	for _, entry := range entries {
+		t.Errorf("Expected no leftover lock files, found %s", entry.Name())
+	}
+}
+
+// makeWorkflow creates a single-repo workflow and returns its root
+func makeWorkflow(t *testing.T, name string) string {
+	t.Helper()
+	root, _ := filepath.EvalSymlinks(t.TempDir())
+	cfg := config.NewDefaultWorkflowConfig(name)
+	cfg.Blueprint = "web-dev"
+	if err := SaveConfig(filepath.Join(root, SingleRepoMarker), cfg); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestRegistryHealthAndPrune(t *testing.T) {
+	setupRegistryHome(t)
+	healthy := makeWorkflow(t, "shop")
+	noMarker := t.TempDir()
+
+	reg := &Registry{Version: RegistryVersion, Workflows: []RegistryEntry{
+		{Name: "shop", Path: healthy},
+		{Name: "gone", Path: filepath.Join(healthy, "missing")},
+		{Name: "empty", Path: noMarker},
+	}}
+
+	if h := reg.Workflows[0].Health(); !h.OK() || h.Blueprint != "web-dev" {
+		t.Errorf("Expected shop to be healthy, got %+v", h)
+	}
+	if h := reg.Workflows[1].Health(); h.OK() || h.Exists {
+		t.Errorf("Expected gone to be missing, got %+v", h)
+	}
+	if h := reg.Workflows[2].Health(); h.OK() || !h.Exists || h.MarkerValid {
+		t.Errorf("Expected empty to have no marker, got %+v", h)
+	}
+
+	pruned := reg.Prune()
+	if len(pruned) != 2 || len(reg.Workflows) != 1 || reg.Workflows[0].Name != "shop" {
+		t.Errorf("Expected gone and empty pruned, kept %v, pruned %v", reg.Workflows, pruned)
+	}
+}
+
+func TestRegistryRelocate(t *testing.T) {
+	reg := &Registry{Version: RegistryVersion, Workflows: []RegistryEntry{
+		{Name: "shop", Path: "/old/shop"},
+		{Name: "blog", Path: "/work/blog"},
+	}}
+
+	if err := reg.Relocate("shop", "/new/shop"); err != nil {
+		t.Fatalf("Relocate failed: %v", err)
+	}
+	if reg.FindByName("shop").Path != "/new/shop" {
+		t.Error("Expected shop at its new path")
+	}
+	if err := reg.Relocate("shop", "/work/blog"); err == nil {
+		t.Error("Expected an error relocating onto another workflow")
+	}
+	if err := reg.Relocate("missing", "/new/missing"); err == nil {
+		t.Error("Expected an error for an unregistered workflow")
+	}
+}
+
+func TestDiscover_MarksUsed(t *testing.T) {
+	setupRegistryHome(t)
+	root := makeWorkflow(t, "shop")
+
+	created := time.Now().Add(-time.Hour)
+	if err := UpdateRegistry(func(reg *Registry) error {
+		reg.AddOrUpdateWorkflow(RegistryEntry{Name: "shop", Path: root, CreatedAt: created, LastUsedAt: created})
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Discover(root); err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	reg, _ := LoadRegistry()
+	if used := reg.FindByPath(root).LastUsedAt; !used.After(created) {
+		t.Errorf("Expected LastUsedAt to be updated, got %v", used)
+	}
+}